@@ -0,0 +1,284 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CatalogChange describes how a cached list differs from its previous contents.
+type CatalogChange[T any] struct {
+	// Added holds the items that were not present before.
+	Added []T
+	// Removed holds the items that are no longer present.
+	Removed []T
+	// Modified holds the new version of items whose definition changed.
+	Modified []T
+}
+
+// Empty reports whether the change contains no additions, removals or modifications.
+func (c CatalogChange[T]) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// Catalog is a client-side cache of the tools, prompts and resources offered
+// by the server. Each list is fetched, including all pages, the first time it
+// is requested and then served from memory until the server sends the
+// matching list_changed notification, at which point it is refetched in the
+// background and the registered change callbacks are invoked.
+//
+// Use Client.Catalog to obtain the catalog of a client:
+//
+//	catalog := c.Catalog()
+//	catalog.OnToolsChanged(func(change client.CatalogChange[mcp.Tool]) {
+//		log.Printf("tools changed: +%d -%d ~%d", len(change.Added), len(change.Removed), len(change.Modified))
+//	})
+//	tools, err := catalog.Tools(ctx)
+type Catalog struct {
+	tools     *catalogList[mcp.Tool]
+	prompts   *catalogList[mcp.Prompt]
+	resources *catalogList[mcp.Resource]
+}
+
+// Catalog returns the cached catalog of the client, creating it on first use.
+// Creating the catalog subscribes it to list_changed notifications.
+func (c *Client) Catalog() *Catalog {
+	c.catalogOnce.Do(func() {
+		c.catalog = newCatalog(c)
+		c.OnNotification(c.catalog.handleNotification)
+	})
+	return c.catalog
+}
+
+func newCatalog(c *Client) *Catalog {
+	return &Catalog{
+		tools: newCatalogList(func(ctx context.Context) ([]mcp.Tool, error) {
+			result, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+			if err != nil {
+				return nil, err
+			}
+			return result.Tools, nil
+		}, mcp.Tool.GetName),
+		prompts: newCatalogList(func(ctx context.Context) ([]mcp.Prompt, error) {
+			result, err := c.ListPrompts(ctx, mcp.ListPromptsRequest{})
+			if err != nil {
+				return nil, err
+			}
+			return result.Prompts, nil
+		}, mcp.Prompt.GetName),
+		resources: newCatalogList(func(ctx context.Context) ([]mcp.Resource, error) {
+			result, err := c.ListResources(ctx, mcp.ListResourcesRequest{})
+			if err != nil {
+				return nil, err
+			}
+			return result.Resources, nil
+		}, func(r mcp.Resource) string { return r.URI }),
+	}
+}
+
+// Tools returns the cached tools, fetching them from the server if needed.
+func (c *Catalog) Tools(ctx context.Context) ([]mcp.Tool, error) {
+	return c.tools.get(ctx)
+}
+
+// Prompts returns the cached prompts, fetching them from the server if needed.
+func (c *Catalog) Prompts(ctx context.Context) ([]mcp.Prompt, error) {
+	return c.prompts.get(ctx)
+}
+
+// Resources returns the cached resources, fetching them from the server if needed.
+func (c *Catalog) Resources(ctx context.Context) ([]mcp.Resource, error) {
+	return c.resources.get(ctx)
+}
+
+// OnToolsChanged registers a callback invoked after a refetch of the tool list
+// that added, removed or modified tools. Tools are identified by name.
+func (c *Catalog) OnToolsChanged(handler func(CatalogChange[mcp.Tool])) {
+	c.tools.onChange(handler)
+}
+
+// OnPromptsChanged registers a callback invoked after a refetch of the prompt
+// list that added, removed or modified prompts. Prompts are identified by name.
+func (c *Catalog) OnPromptsChanged(handler func(CatalogChange[mcp.Prompt])) {
+	c.prompts.onChange(handler)
+}
+
+// OnResourcesChanged registers a callback invoked after a refetch of the
+// resource list that added, removed or modified resources. Resources are
+// identified by URI.
+func (c *Catalog) OnResourcesChanged(handler func(CatalogChange[mcp.Resource])) {
+	c.resources.onChange(handler)
+}
+
+// Invalidate discards all cached lists so that the next call refetches them.
+func (c *Catalog) Invalidate() {
+	c.tools.invalidate(false)
+	c.prompts.invalidate(false)
+	c.resources.invalidate(false)
+}
+
+func (c *Catalog) handleNotification(notification mcp.JSONRPCNotification) {
+	switch notification.Method {
+	case mcp.MethodNotificationToolsListChanged:
+		c.tools.invalidate(true)
+	case mcp.MethodNotificationPromptsListChanged:
+		c.prompts.invalidate(true)
+	case mcp.MethodNotificationResourcesListChanged:
+		c.resources.invalidate(true)
+	}
+}
+
+// catalogList caches a single list and computes changes between fetches.
+type catalogList[T any] struct {
+	fetch func(context.Context) ([]T, error)
+	key   func(T) string
+
+	// fetchMu serializes fetches so concurrent callers share a single refetch.
+	fetchMu sync.Mutex
+
+	mu         sync.Mutex
+	items      []T
+	loaded     bool
+	valid      bool
+	generation uint64
+	handlers   []func(CatalogChange[T])
+}
+
+func newCatalogList[T any](fetch func(context.Context) ([]T, error), key func(T) string) *catalogList[T] {
+	return &catalogList[T]{fetch: fetch, key: key}
+}
+
+func (l *catalogList[T]) get(ctx context.Context) ([]T, error) {
+	l.mu.Lock()
+	if l.valid {
+		items := slices.Clone(l.items)
+		l.mu.Unlock()
+		return items, nil
+	}
+	l.mu.Unlock()
+
+	return l.refresh(ctx)
+}
+
+func (l *catalogList[T]) onChange(handler func(CatalogChange[T])) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handlers = append(l.handlers, handler)
+}
+
+// invalidate marks the cached list as stale. When refetch is set and the list
+// has been loaded before, it is refetched in the background so that change
+// callbacks fire without waiting for the next read. The refetch cannot run on
+// the caller's goroutine, as notifications are delivered by the transport's
+// reader, which also has to deliver the list response.
+func (l *catalogList[T]) invalidate(refetch bool) {
+	l.mu.Lock()
+	l.generation++
+	l.valid = false
+	loaded := l.loaded
+	l.mu.Unlock()
+
+	if refetch && loaded {
+		go func() {
+			// Errors are not reported here; the list stays invalid and the
+			// next read retries the fetch.
+			_, _ = l.refresh(context.Background())
+		}()
+	}
+}
+
+// refresh fetches the list and invokes the change callbacks. The callbacks
+// run after fetchMu is released, so they may read the catalog again.
+func (l *catalogList[T]) refresh(ctx context.Context) ([]T, error) {
+	items, change, handlers, err := l.fetchLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !change.Empty() {
+		for _, handler := range handlers {
+			handler(change)
+		}
+	}
+	return items, nil
+}
+
+// fetchLocked fetches the list unless another caller refreshed it while
+// fetchMu was being acquired. It returns the change to the previous list, if
+// there was one, and the callbacks to notify of it.
+func (l *catalogList[T]) fetchLocked(ctx context.Context) ([]T, CatalogChange[T], []func(CatalogChange[T]), error) {
+	l.fetchMu.Lock()
+	defer l.fetchMu.Unlock()
+
+	// Another caller may have refreshed the list while we were waiting.
+	l.mu.Lock()
+	if l.valid {
+		items := slices.Clone(l.items)
+		l.mu.Unlock()
+		return items, CatalogChange[T]{}, nil, nil
+	}
+	generation := l.generation
+	l.mu.Unlock()
+
+	items, err := l.fetch(ctx)
+	if err != nil {
+		return nil, CatalogChange[T]{}, nil, err
+	}
+
+	l.mu.Lock()
+	previous, hadPrevious := l.items, l.loaded
+	l.items = items
+	l.loaded = true
+	// A list_changed notification received during the fetch keeps the list invalid.
+	l.valid = l.generation == generation
+	handlers := slices.Clone(l.handlers)
+	l.mu.Unlock()
+
+	var change CatalogChange[T]
+	if hadPrevious && len(handlers) > 0 {
+		change = diffCatalog(previous, items, l.key)
+	}
+	return slices.Clone(items), change, handlers, nil
+}
+
+// diffCatalog compares two versions of a list. Items are matched by key and
+// considered modified when their JSON encoding differs.
+func diffCatalog[T any](previous, current []T, key func(T) string) CatalogChange[T] {
+	var change CatalogChange[T]
+
+	before := make(map[string]T, len(previous))
+	for _, item := range previous {
+		before[key(item)] = item
+	}
+
+	seen := make(map[string]struct{}, len(current))
+	for _, item := range current {
+		k := key(item)
+		seen[k] = struct{}{}
+		old, ok := before[k]
+		if !ok {
+			change.Added = append(change.Added, item)
+			continue
+		}
+		if !catalogItemEqual(old, item) {
+			change.Modified = append(change.Modified, item)
+		}
+	}
+
+	for _, item := range previous {
+		if _, ok := seen[key(item)]; !ok {
+			change.Removed = append(change.Removed, item)
+		}
+	}
+
+	return change
+}
+
+func catalogItemEqual[T any](a, b T) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aJSON, bJSON)
+}
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// catalogTransport wraps the in-process transport, counting list requests and
+// letting tests deliver notifications to the client.
+type catalogTransport struct {
+	*transport.InProcessTransport
+
+	listCalls atomic.Int32
+	// onList, if set, is called before each tools/list request is sent.
+	onList atomic.Pointer[func()]
+
+	mu      sync.Mutex
+	handler func(mcp.JSONRPCNotification)
+}
+
+func (t *catalogTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	if request.Method == string(mcp.MethodToolsList) {
+		t.listCalls.Add(1)
+		if onList := t.onList.Load(); onList != nil {
+			(*onList)()
+		}
+	}
+	return t.InProcessTransport.SendRequest(ctx, request)
+}
+
+func (t *catalogTransport) SetNotificationHandler(handler func(mcp.JSONRPCNotification)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handler = handler
+}
+
+func (t *catalogTransport) notify(method string) {
+	t.mu.Lock()
+	handler := t.handler
+	t.mu.Unlock()
+	handler(mcp.JSONRPCNotification{
+		JSONRPC:      mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{Method: method},
+	})
+}
+
+func newCatalogTestClient(t *testing.T, mcpServer *server.MCPServer) (*Client, *catalogTransport) {
+	t.Helper()
+	tr := &catalogTransport{InProcessTransport: transport.NewInProcessTransport(mcpServer)}
+	c := NewClient(tr)
+	t.Cleanup(func() { _ = c.Close() })
+
+	ctx := context.Background()
+	require.NoError(t, c.Start(ctx))
+	_, err := c.Initialize(ctx, mcp.InitializeRequest{})
+	require.NoError(t, err)
+	return c, tr
+}
+
+func TestCatalog_CachesAllPages(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithPaginationLimit(2))
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		mcpServer.AddTool(mcp.NewTool(name), nil)
+	}
+	c, tr := newCatalogTestClient(t, mcpServer)
+
+	tools, err := c.Catalog().Tools(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, tools, 5)
+	assert.Equal(t, int32(3), tr.listCalls.Load())
+
+	tools, err = c.Catalog().Tools(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, tools, 5)
+	assert.Equal(t, int32(3), tr.listCalls.Load(), "second read should be served from cache")
+
+	c.Catalog().Invalidate()
+	_, err = c.Catalog().Tools(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(6), tr.listCalls.Load())
+}
+
+func TestCatalog_RefreshesOnListChanged(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("keep"), nil)
+	mcpServer.AddTool(mcp.NewTool("remove"), nil)
+	mcpServer.AddTool(mcp.NewTool("modify", mcp.WithDescription("before")), nil)
+	c, tr := newCatalogTestClient(t, mcpServer)
+
+	changes := make(chan CatalogChange[mcp.Tool], 1)
+	c.Catalog().OnToolsChanged(func(change CatalogChange[mcp.Tool]) {
+		changes <- change
+	})
+
+	_, err := c.Catalog().Tools(context.Background())
+	require.NoError(t, err)
+
+	mcpServer.DeleteTools("remove")
+	mcpServer.AddTool(mcp.NewTool("modify", mcp.WithDescription("after")), nil)
+	mcpServer.AddTool(mcp.NewTool("add"), nil)
+	tr.notify(mcp.MethodNotificationToolsListChanged)
+
+	select {
+	case change := <-changes:
+		require.Len(t, change.Added, 1)
+		assert.Equal(t, "add", change.Added[0].Name)
+		require.Len(t, change.Removed, 1)
+		assert.Equal(t, "remove", change.Removed[0].Name)
+		require.Len(t, change.Modified, 1)
+		assert.Equal(t, "after", change.Modified[0].Description)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change callback")
+	}
+
+	tools, err := c.Catalog().Tools(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, tools, 3)
+}
+
+func TestCatalog_HandlerReadsCatalog(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("a"), nil)
+	c, tr := newCatalogTestClient(t, mcpServer)
+
+	read := make(chan []mcp.Tool, 1)
+	c.Catalog().OnToolsChanged(func(change CatalogChange[mcp.Tool]) {
+		tools, err := c.Catalog().Tools(context.Background())
+		assert.NoError(t, err)
+		select {
+		case read <- tools:
+		default:
+		}
+	})
+
+	_, err := c.Catalog().Tools(context.Background())
+	require.NoError(t, err)
+
+	// Another list_changed arrives while the list is refetched, so the list
+	// is still stale when the handler reads it.
+	var once sync.Once
+	onList := func() {
+		once.Do(func() {
+			tr.notify(mcp.MethodNotificationToolsListChanged)
+		})
+	}
+	tr.onList.Store(&onList)
+	mcpServer.AddTool(mcp.NewTool("b"), nil)
+	tr.notify(mcp.MethodNotificationToolsListChanged)
+
+	select {
+	case tools := <-read:
+		assert.Len(t, tools, 2)
+	case <-time.After(5 * time.Second):
+		t.Fatal("change handler could not read the catalog")
+	}
+}
+
+func TestCatalog_IgnoresOtherNotifications(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("tool"), nil)
+	c, tr := newCatalogTestClient(t, mcpServer)
+
+	_, err := c.Catalog().Tools(context.Background())
+	require.NoError(t, err)
+
+	tr.notify(mcp.MethodNotificationPromptsListChanged)
+	tr.notify("notifications/message")
+
+	_, err = c.Catalog().Tools(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), tr.listCalls.Load())
+}
+
+func TestDiffCatalog_Resources(t *testing.T) {
+	previous := []mcp.Resource{
+		{URI: "file:///a", Name: "same"},
+		{URI: "file:///b", Name: "same"},
+	}
+	current := []mcp.Resource{
+		{URI: "file:///a", Name: "same"},
+		{URI: "file:///b", Name: "renamed"},
+		{URI: "file:///c", Name: "same"},
+	}
+
+	change := diffCatalog(previous, current, func(r mcp.Resource) string { return r.URI })
+	assert.Equal(t, []mcp.Resource{{URI: "file:///c", Name: "same"}}, change.Added)
+	assert.Equal(t, []mcp.Resource{{URI: "file:///b", Name: "renamed"}}, change.Modified)
+	assert.Empty(t, change.Removed)
+	assert.True(t, diffCatalog(current, current, func(r mcp.Resource) string { return r.URI }).Empty())
+}
@@ -25,8 +25,16 @@ type Client struct {
 	serverCapabilities mcp.ServerCapabilities
 	protocolVersion    string
 	samplingHandler    SamplingHandler
+	samplingTools      bool
 	rootsHandler       RootsHandler
 	elicitationHandler ElicitationHandler
+	experimental       map[string]any
+
+	requestHandlersMu sync.RWMutex
+	requestHandlers   map[string]RequestHandlerFunc
+
+	catalogOnce sync.Once
+	catalog     *Catalog
 }
 
 type ClientOption func(*Client)
@@ -46,6 +54,15 @@ func WithSamplingHandler(handler SamplingHandler) ClientOption {
 	}
 }
 
+// WithSamplingTools declares that the sampling handler supports tools and
+// toolChoice in sampling requests, and may answer with tool_use content.
+// It has no effect without WithSamplingHandler.
+func WithSamplingTools() ClientOption {
+	return func(c *Client) {
+		c.samplingTools = true
+	}
+}
+
 // WithRootsHandler sets the roots handler for the client.
 // WithRootsHandler returns a ClientOption that sets the client's RootsHandler.
 // When provided, the client will declare the roots capability (ListChanged) during initialization.
@@ -63,6 +80,19 @@ func WithElicitationHandler(handler ElicitationHandler) ClientOption {
 	}
 }
 
+// WithExperimentalCapability declares the experimental capability name with
+// the given configuration during initialization, in addition to the
+// experimental capabilities of the initialize request. Use it together with
+// HandleRequest to support non-standard methods.
+func WithExperimentalCapability(name string, config any) ClientOption {
+	return func(c *Client) {
+		if c.experimental == nil {
+			c.experimental = make(map[string]any)
+		}
+		c.experimental[name] = config
+	}
+}
+
 // WithSession assumes a MCP Session has already been initialized
 func WithSession() ClientOption {
 	return func(c *Client) {
@@ -189,6 +219,9 @@ func (c *Client) Initialize(
 	capabilities := request.Params.Capabilities
 	if c.samplingHandler != nil {
 		capabilities.Sampling = &struct{}{}
+		if c.samplingTools {
+			capabilities.SamplingTools = &struct{}{}
+		}
 	}
 	if c.rootsHandler != nil {
 		capabilities.Roots = &struct {
@@ -201,6 +234,16 @@ func (c *Client) Initialize(
 	if c.elicitationHandler != nil {
 		capabilities.Elicitation = &mcp.ElicitationCapability{}
 	}
+	if len(c.experimental) > 0 {
+		experimental := make(map[string]any, len(capabilities.Experimental)+len(c.experimental))
+		for name, config := range capabilities.Experimental {
+			experimental[name] = config
+		}
+		for name, config := range c.experimental {
+			experimental[name] = config
+		}
+		capabilities.Experimental = experimental
+	}
 
 	// Ensure we send a params object with all required fields
 	params := struct {
@@ -513,7 +556,11 @@ func (c *Client) RootListChanges(
 
 // handleIncomingRequest processes incoming requests from the server.
 // This is the main entry point for server-to-client requests like sampling and elicitation.
+// Handlers registered with HandleRequest take precedence over the built-in ones.
 func (c *Client) handleIncomingRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	if handler, ok := c.requestHandler(request.Method); ok {
+		return handleCustomRequest(ctx, handler, request)
+	}
 	switch request.Method {
 	case string(mcp.MethodSamplingCreateMessage):
 		return c.handleSamplingRequestTransport(ctx, request)
@@ -524,7 +571,12 @@ func (c *Client) handleIncomingRequest(ctx context.Context, request transport.JS
 	case string(mcp.MethodListRoots):
 		return c.handleListRootsRequestTransport(ctx, request)
 	default:
-		return nil, fmt.Errorf("unsupported request method: %s", request.Method)
+		return transport.NewJSONRPCErrorResponse(
+			request.ID,
+			mcp.METHOD_NOT_FOUND,
+			fmt.Sprintf("unsupported request method: %s", request.Method),
+			nil,
+		), nil
 	}
 }
 
@@ -549,14 +601,11 @@ func (c *Client) handleSamplingRequestTransport(ctx context.Context, request tra
 	// Fix content parsing - HTTP transport unmarshals TextContent as map[string]any
 	// Use the helper function to properly handle content from different transports
 	for i := range params.Messages {
-		if contentMap, ok := params.Messages[i].Content.(map[string]any); ok {
-			// Parse the content map into a proper Content type
-			content, err := mcp.ParseContent(contentMap)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse content for message %d: %w", i, err)
-			}
-			params.Messages[i].Content = content
+		content, err := mcp.ParseSamplingContent(params.Messages[i].Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse content for message %d: %w", i, err)
 		}
+		params.Messages[i].Content = content
 	}
 
 	// Create the MCP request
@@ -591,10 +640,23 @@ func (c *Client) handleListRootsRequestTransport(ctx context.Context, request tr
 		return nil, fmt.Errorf("no roots handler configured")
 	}
 
+	// Parse the request parameters, which only hold _meta
+	var params mcp.RequestParams
+	if request.Params != nil {
+		paramsBytes, err := json.Marshal(request.Params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal params: %w", err)
+		}
+		if err := json.Unmarshal(paramsBytes, &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal params: %w", err)
+		}
+	}
+
 	// Create the MCP request
 	mcpRequest := mcp.ListRootsRequest{
 		Request: mcp.Request{
 			Method: string(mcp.MethodListRoots),
+			Params: params,
 		},
 	}
 
@@ -0,0 +1,596 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var (
+	// ErrGroupMemberExists is returned when adding a server under a name that is already in use.
+	ErrGroupMemberExists = errors.New("server already exists in group")
+	// ErrGroupMemberNotFound is returned when a server name is not part of the group.
+	ErrGroupMemberNotFound = errors.New("server not found in group")
+	// ErrGroupNoRoute is returned when no server in the group provides the requested tool, prompt or resource.
+	ErrGroupNoRoute = errors.New("no server in group provides the requested item")
+)
+
+// CollisionPolicy controls how a Group names tools and prompts that are
+// merged from several servers.
+type CollisionPolicy int
+
+const (
+	// PrefixAll prefixes every tool and prompt with the name of its server,
+	// e.g. "github__create_issue". This is the default.
+	PrefixAll CollisionPolicy = iota
+	// PrefixOnCollision keeps original names and only prefixes the items whose
+	// name is offered by more than one server.
+	PrefixOnCollision
+	// FirstWins keeps original names. When several servers offer the same
+	// name, the server that was added to the group first wins.
+	FirstWins
+)
+
+// DefaultNamespaceSeparator separates the server name from the item name in
+// namespaced tools and prompts.
+const DefaultNamespaceSeparator = "__"
+
+// GroupNotificationHandler receives notifications from the servers of a
+// Group, along with the name of the server that sent them.
+type GroupNotificationHandler func(server string, notification mcp.JSONRPCNotification)
+
+// ServerHealth reports the last known state of a server in a Group. Only
+// transport failures and timeouts count as failed requests; error responses
+// of the server, such as for invalid parameters, do not.
+type ServerHealth struct {
+	// Healthy is false when the last request to the server failed.
+	Healthy bool
+	// LastError is the error of the last failed request, if any.
+	LastError error
+	// LastSuccess is the time of the last successful request.
+	LastSuccess time.Time
+	// LastFailure is the time of the last failed request.
+	LastFailure time.Time
+}
+
+// Group aggregates several MCP clients behind a single view. Tools, prompts
+// and resources of all servers are merged and namespaced according to the
+// configured CollisionPolicy, and calls are routed to the server that owns the
+// requested item.
+//
+// Lists are served from each client's Catalog, so they are only fetched once
+// and refreshed when the server sends a list_changed notification.
+//
+// Resources and resource templates keep their URIs, since URIs are meaningful
+// to the server that issued them; when two servers expose the same URI, the
+// server added first wins. Resource and template names are namespaced like
+// tools and prompts. Resources are read from the server that lists their URI,
+// or else from the first server with a template matching it.
+//
+// Clients must be started and initialized before they are added to a group.
+type Group struct {
+	separator string
+	policy    CollisionPolicy
+
+	mu      sync.RWMutex
+	members map[string]*groupMember
+	order   []string
+
+	notifyMu      sync.RWMutex
+	notifications []GroupNotificationHandler
+}
+
+type groupMember struct {
+	name   string
+	client *Client
+
+	mu     sync.Mutex
+	health ServerHealth
+}
+
+// GroupOption configures a Group.
+type GroupOption func(*Group)
+
+// WithNamespaceSeparator sets the separator placed between the server name and
+// the item name. The default is DefaultNamespaceSeparator.
+func WithNamespaceSeparator(separator string) GroupOption {
+	return func(g *Group) {
+		g.separator = separator
+	}
+}
+
+// WithCollisionPolicy sets how names offered by several servers are handled.
+// The default is PrefixAll.
+func WithCollisionPolicy(policy CollisionPolicy) GroupOption {
+	return func(g *Group) {
+		g.policy = policy
+	}
+}
+
+// NewGroup creates an empty Group.
+func NewGroup(opts ...GroupOption) *Group {
+	g := &Group{
+		separator: DefaultNamespaceSeparator,
+		policy:    PrefixAll,
+		members:   make(map[string]*groupMember),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Add adds an initialized client to the group under the given server name.
+// The name is used as the namespace of the server's items and to tag its
+// notifications.
+func (g *Group) Add(name string, c *Client) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.members[name]; ok {
+		return fmt.Errorf("%w: %s", ErrGroupMemberExists, name)
+	}
+
+	member := &groupMember{
+		name:   name,
+		client: c,
+		health: ServerHealth{Healthy: true},
+	}
+	g.members[name] = member
+	g.order = append(g.order, name)
+
+	c.Catalog()
+	c.OnNotification(func(notification mcp.JSONRPCNotification) {
+		// Notifications of removed members are dropped.
+		g.mu.RLock()
+		current := g.members[name] == member
+		g.mu.RUnlock()
+		if current {
+			g.forwardNotification(name, notification)
+		}
+	})
+
+	return nil
+}
+
+// Remove removes a server from the group and closes its client.
+func (g *Group) Remove(name string) error {
+	g.mu.Lock()
+	member, ok := g.members[name]
+	if ok {
+		delete(g.members, name)
+		for i, n := range g.order {
+			if n == name {
+				g.order = append(g.order[:i], g.order[i+1:]...)
+				break
+			}
+		}
+	}
+	g.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrGroupMemberNotFound, name)
+	}
+	return member.client.Close()
+}
+
+// Client returns the client registered under name.
+func (g *Group) Client(name string) (*Client, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	member, ok := g.members[name]
+	if !ok {
+		return nil, false
+	}
+	return member.client, true
+}
+
+// Servers returns the names of the servers in the order they were added.
+func (g *Group) Servers() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return append([]string(nil), g.order...)
+}
+
+// Health returns the last known health of every server in the group.
+func (g *Group) Health() map[string]ServerHealth {
+	members := g.snapshot()
+	health := make(map[string]ServerHealth, len(members))
+	for _, member := range members {
+		member.mu.Lock()
+		health[member.name] = member.health
+		member.mu.Unlock()
+	}
+	return health
+}
+
+// Ping pings every server in the group and updates their health. It returns
+// the joined errors of the servers that did not respond.
+func (g *Group) Ping(ctx context.Context) error {
+	var errs []error
+	for _, member := range g.snapshot() {
+		err := member.client.Ping(ctx)
+		member.record(err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", member.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// OnNotification registers a handler called with every notification sent by
+// any server in the group, tagged with the name of that server.
+func (g *Group) OnNotification(handler GroupNotificationHandler) {
+	g.notifyMu.Lock()
+	defer g.notifyMu.Unlock()
+	g.notifications = append(g.notifications, handler)
+}
+
+// Close closes all clients in the group.
+func (g *Group) Close() error {
+	g.mu.Lock()
+	members := g.members
+	g.members = make(map[string]*groupMember)
+	g.order = nil
+	g.mu.Unlock()
+
+	var errs []error
+	for name, member := range members {
+		if err := member.client.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ListTools returns the namespaced tools of all servers in the group.
+//
+// Servers that fail to list their tools are skipped and marked unhealthy; an
+// error is only returned if every server that supports tools failed.
+func (g *Group) ListTools(ctx context.Context) ([]mcp.Tool, error) {
+	routes, err := g.toolRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tools := make([]mcp.Tool, 0, len(routes.items))
+	for _, item := range routes.items {
+		tool := item.value
+		tool.Name = item.name
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// CallTool calls a namespaced tool on the server that provides it.
+func (g *Group) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	routes, err := g.toolRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	route, ok := routes.lookup(request.Params.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: tool %q", ErrGroupNoRoute, request.Params.Name)
+	}
+
+	request.Params.Name = route.value.Name
+	result, err := route.member.client.CallTool(ctx, request)
+	route.member.record(err)
+	return result, err
+}
+
+// ListPrompts returns the namespaced prompts of all servers in the group.
+// Failing servers are handled as in ListTools.
+func (g *Group) ListPrompts(ctx context.Context) ([]mcp.Prompt, error) {
+	routes, err := g.promptRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prompts := make([]mcp.Prompt, 0, len(routes.items))
+	for _, item := range routes.items {
+		prompt := item.value
+		prompt.Name = item.name
+		prompts = append(prompts, prompt)
+	}
+	return prompts, nil
+}
+
+// GetPrompt gets a namespaced prompt from the server that provides it.
+func (g *Group) GetPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	routes, err := g.promptRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	route, ok := routes.lookup(request.Params.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: prompt %q", ErrGroupNoRoute, request.Params.Name)
+	}
+
+	request.Params.Name = route.value.Name
+	result, err := route.member.client.GetPrompt(ctx, request)
+	route.member.record(err)
+	return result, err
+}
+
+// ListResources returns the resources of all servers in the group, with
+// namespaced names and unchanged URIs. Failing servers are handled as in ListTools.
+func (g *Group) ListResources(ctx context.Context) ([]mcp.Resource, error) {
+	routes, err := g.resourceRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]mcp.Resource, 0, len(routes.items))
+	for _, item := range routes.items {
+		resource := item.value
+		resource.Name = item.name
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+// ListResourceTemplates returns the resource templates of all servers in the
+// group, with namespaced names and unchanged URI templates. Failing servers
+// are handled as in ListTools.
+func (g *Group) ListResourceTemplates(ctx context.Context) ([]mcp.ResourceTemplate, error) {
+	routes, err := g.resourceTemplateRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	templates := make([]mcp.ResourceTemplate, 0, len(routes.items))
+	for _, item := range routes.items {
+		template := item.value
+		template.Name = item.name
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// ReadResource reads a resource from the server that provides its URI.
+func (g *Group) ReadResource(ctx context.Context, request mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	member, err := g.resourceMember(ctx, request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := member.client.ReadResource(ctx, request)
+	member.record(err)
+	return result, err
+}
+
+// Subscribe subscribes to updates of a resource on the server that provides
+// its URI.
+func (g *Group) Subscribe(ctx context.Context, request mcp.SubscribeRequest) error {
+	member, err := g.resourceMember(ctx, request.Params.URI)
+	if err != nil {
+		return err
+	}
+
+	err = member.client.Subscribe(ctx, request)
+	member.record(err)
+	return err
+}
+
+// Unsubscribe cancels a subscription made with Subscribe.
+func (g *Group) Unsubscribe(ctx context.Context, request mcp.UnsubscribeRequest) error {
+	member, err := g.resourceMember(ctx, request.Params.URI)
+	if err != nil {
+		return err
+	}
+
+	err = member.client.Unsubscribe(ctx, request)
+	member.record(err)
+	return err
+}
+
+// resourceMember returns the member that provides the resource with the
+// given URI: the one that lists it, or else the first one with a matching
+// resource template.
+func (g *Group) resourceMember(ctx context.Context, uri string) (*groupMember, error) {
+	routes, err := g.resourceRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range routes.items {
+		if item.value.URI == uri {
+			return item.member, nil
+		}
+	}
+
+	templates, err := g.resourceTemplateRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range templates.items {
+		if item.value.URITemplate != nil && item.value.URITemplate.Template != nil &&
+			item.value.URITemplate.Regexp().MatchString(uri) {
+			return item.member, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: resource %q", ErrGroupNoRoute, uri)
+}
+
+func (g *Group) toolRoutes(ctx context.Context) (*groupRoutes[mcp.Tool], error) {
+	return collectRoutes(ctx, g,
+		func(c *Client) bool { return c.GetServerCapabilities().Tools != nil },
+		func(ctx context.Context, c *Client) ([]mcp.Tool, error) { return c.Catalog().Tools(ctx) },
+		mcp.Tool.GetName,
+		nil,
+	)
+}
+
+func (g *Group) promptRoutes(ctx context.Context) (*groupRoutes[mcp.Prompt], error) {
+	return collectRoutes(ctx, g,
+		func(c *Client) bool { return c.GetServerCapabilities().Prompts != nil },
+		func(ctx context.Context, c *Client) ([]mcp.Prompt, error) { return c.Catalog().Prompts(ctx) },
+		mcp.Prompt.GetName,
+		nil,
+	)
+}
+
+func (g *Group) resourceRoutes(ctx context.Context) (*groupRoutes[mcp.Resource], error) {
+	return collectRoutes(ctx, g,
+		func(c *Client) bool { return c.GetServerCapabilities().Resources != nil },
+		func(ctx context.Context, c *Client) ([]mcp.Resource, error) { return c.Catalog().Resources(ctx) },
+		mcp.Resource.GetName,
+		func(r mcp.Resource) string { return r.URI },
+	)
+}
+
+// resourceTemplateRoutes lists the resource templates of the servers. They
+// are not cached, since servers do not announce changes to them.
+func (g *Group) resourceTemplateRoutes(ctx context.Context) (*groupRoutes[mcp.ResourceTemplate], error) {
+	return collectRoutes(ctx, g,
+		func(c *Client) bool { return c.GetServerCapabilities().Resources != nil },
+		func(ctx context.Context, c *Client) ([]mcp.ResourceTemplate, error) {
+			result, err := c.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
+			if err != nil {
+				return nil, err
+			}
+			return result.ResourceTemplates, nil
+		},
+		mcp.ResourceTemplate.GetName,
+		func(t mcp.ResourceTemplate) string {
+			if t.URITemplate == nil || t.URITemplate.Template == nil {
+				return ""
+			}
+			return t.URITemplate.Raw()
+		},
+	)
+}
+
+// groupRoute is an item of a server together with its name in the group.
+type groupRoute[T any] struct {
+	name   string
+	value  T
+	member *groupMember
+}
+
+type groupRoutes[T any] struct {
+	items  []groupRoute[T]
+	byName map[string]int
+}
+
+func (r *groupRoutes[T]) lookup(name string) (*groupRoute[T], bool) {
+	i, ok := r.byName[name]
+	if !ok {
+		return nil, false
+	}
+	return &r.items[i], true
+}
+
+// collectRoutes lists the items of every server that supports them and names
+// them according to the group's collision policy. If key is not nil, items are
+// also deduplicated by key across servers, keeping the first server's item.
+func collectRoutes[T any](
+	ctx context.Context,
+	g *Group,
+	supported func(*Client) bool,
+	list func(context.Context, *Client) ([]T, error),
+	name func(T) string,
+	key func(T) string,
+) (*groupRoutes[T], error) {
+	var (
+		items     []groupRoute[T]
+		errs      []error
+		attempted int
+	)
+	for _, member := range g.snapshot() {
+		if !supported(member.client) {
+			continue
+		}
+		attempted++
+		values, err := list(ctx, member.client)
+		member.record(err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", member.name, err))
+			continue
+		}
+		for _, value := range values {
+			items = append(items, groupRoute[T]{value: value, member: member})
+		}
+	}
+	if attempted > 0 && len(errs) == attempted {
+		return nil, errors.Join(errs...)
+	}
+
+	owners := make(map[string]int)
+	for _, item := range items {
+		owners[name(item.value)]++
+	}
+
+	routes := &groupRoutes[T]{byName: make(map[string]int, len(items))}
+	seenKeys := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		if key != nil {
+			k := key(item.value)
+			if _, ok := seenKeys[k]; ok {
+				continue
+			}
+			seenKeys[k] = struct{}{}
+		}
+
+		item.name = name(item.value)
+		switch g.policy {
+		case PrefixAll:
+			item.name = item.member.name + g.separator + item.name
+		case PrefixOnCollision:
+			if owners[item.name] > 1 {
+				item.name = item.member.name + g.separator + item.name
+			}
+		}
+		if _, ok := routes.byName[item.name]; ok {
+			// FirstWins, or a prefixed name that clashes with another item.
+			continue
+		}
+		routes.byName[item.name] = len(routes.items)
+		routes.items = append(routes.items, item)
+	}
+	return routes, nil
+}
+
+// snapshot returns the members in the order they were added.
+func (g *Group) snapshot() []*groupMember {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	members := make([]*groupMember, 0, len(g.order))
+	for _, name := range g.order {
+		members = append(members, g.members[name])
+	}
+	return members
+}
+
+func (g *Group) forwardNotification(server string, notification mcp.JSONRPCNotification) {
+	g.notifyMu.RLock()
+	defer g.notifyMu.RUnlock()
+	for _, handler := range g.notifications {
+		handler(server, notification)
+	}
+}
+
+// record updates the health of the member after a request. Errors other than
+// transport failures and timeouts mean the server answered, so they count as
+// successes.
+func (m *groupMember) record(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if isServerFailure(err) {
+		m.health.Healthy = false
+		m.health.LastError = err
+		m.health.LastFailure = time.Now()
+		return
+	}
+	m.health.Healthy = true
+	m.health.LastSuccess = time.Now()
+}
+
+// isServerFailure reports whether err means the server could not be reached
+// or did not answer in time, as opposed to an error response caused by the
+// request.
+func isServerFailure(err error) bool {
+	var transportErr *transport.Error
+	return errors.As(err, &transportErr) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,267 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func newGroupTestServer(name string, tools ...string) *server.MCPServer {
+	s := server.NewMCPServer(name, "1.0.0")
+	for _, tool := range tools {
+		s.AddTool(mcp.NewTool(tool), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(name + ":" + request.Params.Name), nil
+		})
+	}
+	return s
+}
+
+func newGroupTestClient(t *testing.T, s *server.MCPServer) *Client {
+	t.Helper()
+	c, err := NewInProcessClient(s)
+	require.NoError(t, err)
+	require.NoError(t, c.Start(context.Background()))
+	_, err = c.Initialize(context.Background(), mcp.InitializeRequest{})
+	require.NoError(t, err)
+	return c
+}
+
+func groupToolNames(t *testing.T, g *Group) []string {
+	t.Helper()
+	tools, err := g.ListTools(context.Background())
+	require.NoError(t, err)
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	return names
+}
+
+func callGroupTool(t *testing.T, g *Group, name string) string {
+	t.Helper()
+	var request mcp.CallToolRequest
+	request.Params.Name = name
+	result, err := g.CallTool(context.Background(), request)
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+	return result.Content[0].(mcp.TextContent).Text
+}
+
+func TestGroup_CollisionPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   CollisionPolicy
+		expected []string
+		calls    map[string]string
+	}{
+		{
+			name:     "prefix all",
+			policy:   PrefixAll,
+			expected: []string{"alpha__search", "alpha__shared", "beta__fetch", "beta__shared"},
+			calls:    map[string]string{"alpha__shared": "alpha:shared", "beta__shared": "beta:shared"},
+		},
+		{
+			name:     "prefix on collision",
+			policy:   PrefixOnCollision,
+			expected: []string{"search", "alpha__shared", "fetch", "beta__shared"},
+			calls:    map[string]string{"search": "alpha:search", "beta__shared": "beta:shared"},
+		},
+		{
+			name:     "first wins",
+			policy:   FirstWins,
+			expected: []string{"search", "shared", "fetch"},
+			calls:    map[string]string{"shared": "alpha:shared", "fetch": "beta:fetch"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGroup(WithCollisionPolicy(tt.policy))
+			defer g.Close()
+			require.NoError(t, g.Add("alpha", newGroupTestClient(t, newGroupTestServer("alpha", "search", "shared"))))
+			require.NoError(t, g.Add("beta", newGroupTestClient(t, newGroupTestServer("beta", "fetch", "shared"))))
+
+			assert.Equal(t, tt.expected, groupToolNames(t, g))
+			for tool, expected := range tt.calls {
+				assert.Equal(t, expected, callGroupTool(t, g, tool))
+			}
+		})
+	}
+}
+
+func TestGroup_UnknownTool(t *testing.T) {
+	g := NewGroup(WithNamespaceSeparator("."))
+	defer g.Close()
+	require.NoError(t, g.Add("alpha", newGroupTestClient(t, newGroupTestServer("alpha", "search"))))
+
+	assert.Equal(t, "alpha:search", callGroupTool(t, g, "alpha.search"))
+
+	var request mcp.CallToolRequest
+	request.Params.Name = "alpha.missing"
+	_, err := g.CallTool(context.Background(), request)
+	assert.True(t, errors.Is(err, ErrGroupNoRoute))
+}
+
+func TestGroup_AddRemove(t *testing.T) {
+	g := NewGroup()
+	defer g.Close()
+	c := newGroupTestClient(t, newGroupTestServer("alpha", "search"))
+
+	require.NoError(t, g.Add("alpha", c))
+	assert.ErrorIs(t, g.Add("alpha", c), ErrGroupMemberExists)
+	assert.Equal(t, []string{"alpha"}, g.Servers())
+
+	require.NoError(t, g.Remove("alpha"))
+	assert.ErrorIs(t, g.Remove("alpha"), ErrGroupMemberNotFound)
+	assert.Empty(t, groupToolNames(t, g))
+}
+
+func TestGroup_PromptsAndResources(t *testing.T) {
+	alpha := server.NewMCPServer("alpha", "1.0.0")
+	alpha.AddPrompt(mcp.NewPrompt("greet"), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return mcp.NewGetPromptResult("alpha greeting", []mcp.PromptMessage{}), nil
+	})
+	alpha.AddResource(mcp.NewResource("file:///alpha.txt", "notes"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "from alpha"}}, nil
+	})
+	beta := server.NewMCPServer("beta", "1.0.0")
+	beta.AddResource(mcp.NewResource("file:///beta.txt", "notes"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "from beta"}}, nil
+	})
+
+	g := NewGroup()
+	defer g.Close()
+	require.NoError(t, g.Add("alpha", newGroupTestClient(t, alpha)))
+	require.NoError(t, g.Add("beta", newGroupTestClient(t, beta)))
+
+	prompts, err := g.ListPrompts(context.Background())
+	require.NoError(t, err)
+	require.Len(t, prompts, 1)
+	assert.Equal(t, "alpha__greet", prompts[0].Name)
+
+	var getPrompt mcp.GetPromptRequest
+	getPrompt.Params.Name = "alpha__greet"
+	prompt, err := g.GetPrompt(context.Background(), getPrompt)
+	require.NoError(t, err)
+	assert.Equal(t, "alpha greeting", prompt.Description)
+
+	resources, err := g.ListResources(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	assert.Equal(t, "alpha__notes", resources[0].Name)
+	assert.Equal(t, "file:///alpha.txt", resources[0].URI)
+
+	var read mcp.ReadResourceRequest
+	read.Params.URI = "file:///beta.txt"
+	contents, err := g.ReadResource(context.Background(), read)
+	require.NoError(t, err)
+	assert.Equal(t, "from beta", contents.Contents[0].(mcp.TextResourceContents).Text)
+
+	assert.True(t, g.Health()["alpha"].Healthy)
+	assert.True(t, g.Health()["beta"].Healthy)
+}
+
+func TestGroup_HealthAndNotifications(t *testing.T) {
+	healthy := newGroupTestClient(t, newGroupTestServer("healthy", "search"))
+
+	tr := &catalogTransport{InProcessTransport: transport.NewInProcessTransport(server.NewMCPServer("broken", "1.0.0"))}
+	broken := NewClient(unreachableTransport{tr})
+	broken.initialized = true
+	broken.serverCapabilities.Tools = &struct {
+		ListChanged bool `json:"listChanged,omitempty"`
+	}{}
+
+	g := NewGroup()
+	require.NoError(t, g.Add("healthy", healthy))
+	require.NoError(t, g.Add("broken", broken))
+
+	// The broken client cannot reach its server.
+	assert.Equal(t, []string{"healthy__search"}, groupToolNames(t, g))
+	health := g.Health()
+	assert.True(t, health["healthy"].Healthy)
+	assert.False(t, health["broken"].Healthy)
+	assert.Error(t, health["broken"].LastError)
+
+	var sources []string
+	g.OnNotification(func(server string, notification mcp.JSONRPCNotification) {
+		sources = append(sources, server+":"+notification.Method)
+	})
+	require.NoError(t, broken.Start(context.Background()))
+	tr.notify("notifications/message")
+	assert.Equal(t, []string{"broken:notifications/message"}, sources)
+
+	require.NoError(t, g.Remove("healthy"))
+	assert.Error(t, g.Ping(context.Background()))
+}
+
+// unreachableTransport fails every request as if the server could not be
+// reached.
+type unreachableTransport struct {
+	*catalogTransport
+}
+
+func (unreachableTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestGroup_ErrorResponsesKeepServersHealthy(t *testing.T) {
+	alpha := server.NewMCPServer("alpha", "1.0.0")
+	alpha.AddResource(mcp.NewResource("file:///gone.txt", "gone"),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return nil, errors.New("file was deleted")
+		})
+
+	g := NewGroup()
+	defer g.Close()
+	require.NoError(t, g.Add("alpha", newGroupTestClient(t, alpha)))
+
+	// The server answers with an error response, so it is still healthy.
+	var read mcp.ReadResourceRequest
+	read.Params.URI = "file:///gone.txt"
+	_, err := g.ReadResource(context.Background(), read)
+	require.Error(t, err)
+	assert.True(t, g.Health()["alpha"].Healthy)
+	assert.NoError(t, g.Health()["alpha"].LastError)
+}
+
+func TestGroup_ResourceTemplates(t *testing.T) {
+	alpha := server.NewMCPServer("alpha", "1.0.0")
+	alpha.AddResourceTemplate(mcp.NewResourceTemplate("alpha://notes/{id}", "notes"),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "alpha note"}}, nil
+		})
+	beta := server.NewMCPServer("beta", "1.0.0")
+	beta.AddResourceTemplate(mcp.NewResourceTemplate("beta://notes/{id}", "notes"),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "beta note"}}, nil
+		})
+
+	g := NewGroup()
+	defer g.Close()
+	require.NoError(t, g.Add("alpha", newGroupTestClient(t, alpha)))
+	require.NoError(t, g.Add("beta", newGroupTestClient(t, beta)))
+
+	templates, err := g.ListResourceTemplates(context.Background())
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, "alpha__notes", templates[0].Name)
+	assert.Equal(t, "alpha://notes/{id}", templates[0].URITemplate.Raw())
+	assert.Equal(t, "beta__notes", templates[1].Name)
+
+	var read mcp.ReadResourceRequest
+	read.Params.URI = "beta://notes/1"
+	contents, err := g.ReadResource(context.Background(), read)
+	require.NoError(t, err)
+	assert.Equal(t, "beta note", contents.Contents[0].(mcp.TextResourceContents).Text)
+
+	read.Params.URI = "gamma://notes/1"
+	_, err = g.ReadResource(context.Background(), read)
+	assert.ErrorIs(t, err, ErrGroupNoRoute)
+}
@@ -419,3 +419,51 @@ func TestInProcessMCPClient(t *testing.T) {
 		}
 	})
 }
+
+func TestInProcessMCPClient_StructuredOutput(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool(
+		"weather",
+		mcp.WithOutputSchema[struct {
+			Temperature float64 `json:"temperature"`
+		}](),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{StructuredContent: map[string]any{"temperature": 21.5}}, nil
+	})
+
+	client, err := NewInProcessClient(mcpServer)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	// The in-process client has no session, so its protocol version is
+	// unknown to the server and results must not be downgraded.
+	tools, err := client.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(tools.Tools) != 1 || tools.Tools[0].OutputSchema.Type != "object" {
+		t.Errorf("Expected the output schema of the tool, got %+v", tools.Tools)
+	}
+
+	callRequest := mcp.CallToolRequest{}
+	callRequest.Params.Name = "weather"
+	result, err := client.CallTool(context.Background(), callRequest)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.StructuredContent == nil {
+		t.Errorf("Expected structured content, got %+v", result)
+	}
+}
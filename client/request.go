@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RequestHandlerFunc handles a request the server sends to the client.
+// params holds the raw params of the request, and is nil when the request has
+// none. The returned result is marshaled as the result of the response.
+// Return a *RequestError to answer with a specific JSON-RPC error code; other
+// errors are sent as internal errors.
+type RequestHandlerFunc func(ctx context.Context, params json.RawMessage) (any, error)
+
+// RequestError is an error returned by a RequestHandlerFunc that is sent to
+// the server with its JSON-RPC error code and data.
+type RequestError struct {
+	Code    int
+	Message string
+	Data    any
+}
+
+func (e *RequestError) Error() string {
+	return e.Message
+}
+
+// HandleRequest registers handler for requests the server sends with the
+// given method, such as experimental methods. A handler registered for
+// sampling, elicitation, roots or ping replaces the built-in handling of
+// that method. Requests with methods that have no handler are answered with
+// a method not found error.
+//
+// Handlers are only called on transports that support requests from the
+// server, and can be registered before or after Start.
+func (c *Client) HandleRequest(method string, handler RequestHandlerFunc) {
+	c.requestHandlersMu.Lock()
+	defer c.requestHandlersMu.Unlock()
+	if c.requestHandlers == nil {
+		c.requestHandlers = make(map[string]RequestHandlerFunc)
+	}
+	c.requestHandlers[method] = handler
+}
+
+// Request sends a request with the given method and params to the server
+// and unmarshals the result into result, unless result is nil. It can be
+// used for methods the client has no helper for, such as experimental ones.
+// Error responses are returned as errors, see mcp.JSONRPCErrorDetails.AsError.
+func (c *Client) Request(ctx context.Context, method string, params any, result any) error {
+	response, err := c.sendRequest(ctx, method, params, nil)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(*response, result); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// requestHandler returns the handler registered for method, if any.
+func (c *Client) requestHandler(method string) (RequestHandlerFunc, bool) {
+	c.requestHandlersMu.RLock()
+	defer c.requestHandlersMu.RUnlock()
+	handler, ok := c.requestHandlers[method]
+	return handler, ok
+}
+
+// handleCustomRequest calls handler for request and builds the response.
+func handleCustomRequest(ctx context.Context, handler RequestHandlerFunc, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	var params json.RawMessage
+	if request.Params != nil {
+		var err error
+		if params, err = json.Marshal(request.Params); err != nil {
+			return nil, fmt.Errorf("failed to marshal params: %w", err)
+		}
+	}
+
+	result, err := handler(ctx, params)
+	if err != nil {
+		var requestErr *RequestError
+		if errors.As(err, &requestErr) {
+			return transport.NewJSONRPCErrorResponse(request.ID, requestErr.Code, requestErr.Message, requestErr.Data), nil
+		}
+		return nil, err
+	}
+
+	if result == nil {
+		result = &mcp.EmptyResult{}
+	}
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return transport.NewJSONRPCResultResponse(request.ID, resultBytes), nil
+}
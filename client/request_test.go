@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestClient_HandleRequest(t *testing.T) {
+	client := NewClient(&mockElicitationTransport{})
+	client.HandleRequest("x/echo", func(ctx context.Context, params json.RawMessage) (any, error) {
+		var args map[string]any
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, err
+		}
+		return map[string]any{"echo": args["value"]}, nil
+	})
+	client.HandleRequest("x/invalid", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, &RequestError{Code: mcp.INVALID_PARAMS, Message: "bad value", Data: "value"}
+	})
+	client.HandleRequest("x/fail", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, errors.New("boom")
+	})
+	client.HandleRequest("x/empty", func(ctx context.Context, params json.RawMessage) (any, error) {
+		assert.Nil(t, params)
+		return nil, nil
+	})
+
+	request := func(method string, params any) transport.JSONRPCRequest {
+		return transport.JSONRPCRequest{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			ID:      mcp.NewRequestId(int64(1)),
+			Method:  method,
+			Params:  params,
+		}
+	}
+	ctx := context.Background()
+
+	response, err := client.handleIncomingRequest(ctx, request("x/echo", map[string]any{"value": 42}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"echo":42}`, string(response.Result))
+
+	response, err = client.handleIncomingRequest(ctx, request("x/invalid", nil))
+	require.NoError(t, err)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, mcp.INVALID_PARAMS, response.Error.Code)
+	assert.Equal(t, "bad value", response.Error.Message)
+	assert.Equal(t, "value", response.Error.Data)
+
+	_, err = client.handleIncomingRequest(ctx, request("x/fail", nil))
+	assert.EqualError(t, err, "boom")
+
+	response, err = client.handleIncomingRequest(ctx, request("x/empty", nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(response.Result))
+
+	response, err = client.handleIncomingRequest(ctx, request("x/unknown", nil))
+	require.NoError(t, err)
+	require.NotNil(t, response.Error)
+	assert.Equal(t, mcp.METHOD_NOT_FOUND, response.Error.Code)
+
+	// Registered handlers replace the built-in ones.
+	client.HandleRequest(string(mcp.MethodPing), func(ctx context.Context, params json.RawMessage) (any, error) {
+		return map[string]any{"custom": true}, nil
+	})
+	response, err = client.handleIncomingRequest(ctx, request(string(mcp.MethodPing), nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"custom":true}`, string(response.Result))
+}
+
+func TestClient_HandleRequest_InProcess(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("ask"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := mcpServer.SendRequest(ctx, "x/echo", map[string]any{"value": 42})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := mcpServer.SendRequest(ctx, "x/unknown", nil); !errors.Is(err, mcp.ErrMethodNotFound) {
+			return nil, fmt.Errorf("unexpected error: %v", err)
+		}
+		return mcp.NewToolResultText(string(result)), nil
+	})
+
+	client := NewClient(transport.NewInProcessTransportWithOptions(mcpServer))
+	client.HandleRequest("x/echo", func(ctx context.Context, params json.RawMessage) (any, error) {
+		var args map[string]any
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, err
+		}
+		return map[string]any{"echo": args["value"]}, nil
+	})
+	ctx := context.Background()
+	require.NoError(t, client.Start(ctx))
+	defer client.Close()
+	_, err := client.Initialize(ctx, mcp.InitializeRequest{})
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "ask"
+	result, err := client.CallTool(ctx, request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	require.Len(t, result.Content, 1)
+	assert.JSONEq(t, `{"echo":42}`, result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestClient_Request(t *testing.T) {
+	mockTransport := &mockElicitationTransport{
+		sendRequestFunc: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+			switch request.Method {
+			case "x/echo":
+				resultBytes, err := json.Marshal(map[string]any{"echo": request.Params})
+				require.NoError(t, err)
+				return transport.NewJSONRPCResultResponse(request.ID, resultBytes), nil
+			default:
+				return transport.NewJSONRPCErrorResponse(request.ID, mcp.METHOD_NOT_FOUND, "method not found", nil), nil
+			}
+		},
+	}
+	client := NewClient(mockTransport, WithSession())
+	ctx := context.Background()
+
+	var result struct {
+		Echo map[string]any `json:"echo"`
+	}
+	require.NoError(t, client.Request(ctx, "x/echo", map[string]any{"value": "hi"}, &result))
+	assert.Equal(t, map[string]any{"value": "hi"}, result.Echo)
+
+	// A nil result discards the response.
+	require.NoError(t, client.Request(ctx, "x/echo", nil, nil))
+
+	err := client.Request(ctx, "x/unknown", nil, nil)
+	assert.ErrorIs(t, err, mcp.ErrMethodNotFound)
+
+	uninitialized := NewClient(mockTransport)
+	assert.Error(t, uninitialized.Request(ctx, "x/echo", nil, nil))
+}
+
+func TestClient_Initialize_WithExperimentalCapability(t *testing.T) {
+	var capabilities mcp.ClientCapabilities
+	mockTransport := &mockElicitationTransport{
+		sendRequestFunc: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+			paramsBytes, err := json.Marshal(request.Params)
+			require.NoError(t, err)
+			var params mcp.InitializeParams
+			require.NoError(t, json.Unmarshal(paramsBytes, &params))
+			capabilities = params.Capabilities
+
+			resultBytes, err := json.Marshal(mcp.InitializeResult{ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION})
+			require.NoError(t, err)
+			return transport.NewJSONRPCResultResponse(request.ID, resultBytes), nil
+		},
+	}
+	client := NewClient(mockTransport,
+		WithExperimentalCapability("acme/search", map[string]any{"version": 2}),
+	)
+	require.NoError(t, client.Start(context.Background()))
+
+	requestExperimental := map[string]any{"acme/trace": map[string]any{}}
+	_, err := client.Initialize(context.Background(), mcp.InitializeRequest{
+		Params: mcp.InitializeParams{
+			Capabilities: mcp.ClientCapabilities{Experimental: requestExperimental},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"acme/search": map[string]any{"version": float64(2)},
+		"acme/trace":  map[string]any{},
+	}, capabilities.Experimental)
+	// The experimental capabilities of the request are left untouched.
+	assert.Len(t, requestExperimental, 1)
+}
@@ -246,6 +246,8 @@ func TestClient_Initialize_WithSampling(t *testing.T) {
 		// Verify sampling capability is included in the request
 		if params.Capabilities.Sampling == nil {
 			t.Error("Sampling capability should be included in initialization request when handler is configured")
+		} else if params.Capabilities.SamplingTools != nil {
+			t.Error("Sampling tools capability should not be declared without WithSamplingTools")
 		}
 
 		// Verify other expected fields
@@ -262,6 +264,15 @@ func TestClient_Initialize_WithSampling(t *testing.T) {
 	}
 }
 
+func TestWithSamplingTools(t *testing.T) {
+	client := &Client{}
+	WithSamplingTools()(client)
+
+	if !client.samplingTools {
+		t.Error("Sampling tools should be enabled")
+	}
+}
+
 // Helper function to create a mock JSON-RPC request for testing
 func mockJSONRPCRequest(mcpRequest mcp.CreateMessageRequest) transport.JSONRPCRequest {
 	return transport.JSONRPCRequest{
@@ -20,8 +20,12 @@ type InProcessTransport struct {
 
 	onNotification func(mcp.JSONRPCNotification)
 	notifyMu       sync.RWMutex
+	requestHandler RequestHandler
+	requestMu      sync.RWMutex
 	started        bool
 	startedMu      sync.Mutex
+	done           chan struct{}
+	closeOnce      sync.Once
 }
 
 type InProcessOption func(*InProcessTransport)
@@ -50,10 +54,17 @@ func NewInProcessTransport(server *server.MCPServer) *InProcessTransport {
 	}
 }
 
+// NewInProcessTransportWithOptions creates an in-process transport with its
+// own client session. Unlike NewInProcessTransport, the session is always
+// registered with the server, so the server can see the client info and log
+// level, notifications sent to the session are delivered to the client, and
+// requests the server sends to the session, such as pings and custom
+// methods, are passed to the handler set with SetRequestHandler.
 func NewInProcessTransportWithOptions(server *server.MCPServer, opts ...InProcessOption) *InProcessTransport {
 	t := &InProcessTransport{
 		server:    server,
 		sessionID: server.GenerateInProcessSessionID(),
+		done:      make(chan struct{}),
 	}
 
 	for _, opt := range opts {
@@ -72,19 +83,77 @@ func (c *InProcessTransport) Start(ctx context.Context) error {
 	c.started = true
 	c.startedMu.Unlock()
 
-	// Create and register session if we have handlers
-	if c.samplingHandler != nil || c.elicitationHandler != nil || c.rootsHandler != nil {
-		c.session = server.NewInProcessSessionWithHandlers(c.sessionID, c.samplingHandler, c.elicitationHandler, c.rootsHandler)
-		if err := c.server.RegisterSession(ctx, c.session); err != nil {
+	// Create and register a session for transports created with options
+	if c.sessionID != "" {
+		session := server.NewInProcessSessionWithHandlers(c.sessionID, c.samplingHandler, c.elicitationHandler, c.rootsHandler)
+		if err := c.server.RegisterSession(ctx, session); err != nil {
 			c.startedMu.Lock()
 			c.started = false
 			c.startedMu.Unlock()
 			return fmt.Errorf("failed to register session: %w", err)
 		}
+		session.SetRequestHandler(c.handleServerRequest)
+		c.session = session
+		go c.forwardNotifications(session.Notifications())
 	}
 	return nil
 }
 
+// forwardNotifications delivers notifications sent to the session to the
+// notification handler until the transport is closed.
+func (c *InProcessTransport) forwardNotifications(notifications <-chan mcp.JSONRPCNotification) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case notification := <-notifications:
+			c.notifyMu.RLock()
+			handler := c.onNotification
+			c.notifyMu.RUnlock()
+			if handler != nil {
+				handler(notification)
+			}
+		}
+	}
+}
+
+// SetRequestHandler sets the handler of requests the server sends to the
+// client. Transports created without options have no session, so the server
+// cannot send them requests.
+func (c *InProcessTransport) SetRequestHandler(handler RequestHandler) {
+	c.requestMu.Lock()
+	defer c.requestMu.Unlock()
+	c.requestHandler = handler
+}
+
+// handleServerRequest passes a request the server sends to the session to
+// the request handler.
+func (c *InProcessTransport) handleServerRequest(ctx context.Context, request mcp.JSONRPCRequest) (json.RawMessage, error) {
+	c.requestMu.RLock()
+	handler := c.requestHandler
+	c.requestMu.RUnlock()
+	if handler == nil {
+		return nil, server.ErrRequestsNotSupported
+	}
+
+	response, err := handler(ctx, JSONRPCRequest{
+		JSONRPC: request.JSONRPC,
+		ID:      request.ID,
+		Method:  request.Method,
+		Params:  request.Params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		return nil, fmt.Errorf("no response to %s request", request.Method)
+	}
+	if response.Error != nil {
+		return nil, response.Error.AsError()
+	}
+	return response.Result, nil
+}
+
 func (c *InProcessTransport) SendRequest(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
 	requestBytes, err := json.Marshal(request)
 	if err != nil {
@@ -130,7 +199,10 @@ func (c *InProcessTransport) SetNotificationHandler(handler func(notification mc
 
 func (c *InProcessTransport) Close() error {
 	if c.session != nil {
-		c.server.UnregisterSession(context.Background(), c.sessionID)
+		c.closeOnce.Do(func() {
+			close(c.done)
+			c.server.UnregisterSession(context.Background(), c.sessionID)
+		})
 	}
 	return nil
 }
@@ -315,6 +315,19 @@ func (c *SSE) handleSSEEvent(event, data string) {
 			c.logger.Errorf("Error unmarshaling message: %v", err)
 			return
 		}
+		var request JSONRPCRequest
+		if err := json.Unmarshal([]byte(data), &request); err != nil {
+			c.logger.Errorf("Error unmarshaling message: %v", err)
+			return
+		}
+
+		// Handle requests from the server. Their IDs are chosen by the
+		// server, so they must not be taken for the response to a pending
+		// request with the same ID.
+		if request.Method != "" && !request.ID.IsNil() {
+			go c.handleIncomingRequest(request)
+			return
+		}
 
 		// Handle notification
 		if baseMessage.ID.IsNil() {
@@ -346,6 +359,35 @@ func (c *SSE) handleSSEEvent(event, data string) {
 	}
 }
 
+// handleIncomingRequest answers a request sent by the server on the SSE
+// stream. The SSE transport does not dispatch server requests to the client,
+// so pings are answered with an empty result and other methods with a method
+// not found error.
+func (c *SSE) handleIncomingRequest(request JSONRPCRequest) {
+	var response *JSONRPCResponse
+	if request.Method == string(mcp.MethodPing) {
+		response = NewJSONRPCResultResponse(request.ID, json.RawMessage(`{}`))
+	} else {
+		response = NewJSONRPCErrorResponse(
+			request.ID,
+			mcp.METHOD_NOT_FOUND,
+			fmt.Sprintf("SSE transport does not handle server requests: %s", request.Method),
+			nil,
+		)
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		c.logger.Errorf("Error marshaling response: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.postMessage(ctx, body, "response"); err != nil {
+		c.logger.Errorf("Error sending response to %s request: %v", request.Method, err)
+	}
+}
+
 func (c *SSE) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
 	c.notifyMu.Lock()
 	defer c.notifyMu.Unlock()
@@ -569,15 +611,25 @@ func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNoti
 	if err != nil {
 		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
+	return c.postMessage(ctx, notificationBytes, "notification")
+}
+
+// postMessage posts a message that is not answered on the SSE stream, such
+// as a notification or a response, to the message endpoint. kind names the
+// message in errors.
+func (c *SSE) postMessage(ctx context.Context, message []byte, kind string) error {
+	if c.endpoint == nil {
+		return fmt.Errorf("endpoint not received")
+	}
 
 	req, err := http.NewRequestWithContext(
 		ctx,
 		"POST",
 		c.endpoint.String(),
-		bytes.NewReader(notificationBytes),
+		bytes.NewReader(message),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create notification request: %w", err)
+		return fmt.Errorf("failed to create %s request: %w", kind, err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -620,7 +672,7 @@ func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNoti
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send notification: %w", err)
+		return fmt.Errorf("failed to send %s: %w", kind, err)
 	}
 	defer resp.Body.Close()
 
@@ -637,7 +689,8 @@ func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNoti
 
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf(
-			"notification failed with status %d: %s",
+			"%s failed with status %d: %s",
+			kind,
 			resp.StatusCode,
 			body,
 		)
@@ -1415,3 +1415,66 @@ func TestSSE_SendRequest_Timeout(t *testing.T) {
 			"Expected context.DeadlineExceeded, got: %v", err)
 	})
 }
+
+func TestSSE_ServerRequestWithOverlappingID(t *testing.T) {
+	events := make(chan string, 10)
+	pingResponses := make(chan JSONRPCResponse, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == "text/event-stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			flusher, _ := w.(http.Flusher)
+			fmt.Fprintf(w, "event: endpoint\ndata: /message\n\n")
+			flusher.Flush()
+			for {
+				select {
+				case event := <-events:
+					fmt.Fprintf(w, "event: message\ndata: %s\n\n", event)
+					flusher.Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}
+
+		var message map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		w.WriteHeader(http.StatusAccepted)
+		if _, ok := message["method"]; ok {
+			// The server pings the client with the ID of the pending
+			// request before answering it.
+			events <- fmt.Sprintf(`{"jsonrpc":"2.0","id":%v,"method":"ping"}`, message["id"])
+			return
+		}
+		var response JSONRPCResponse
+		data, _ := json.Marshal(message)
+		require.NoError(t, json.Unmarshal(data, &response))
+		pingResponses <- response
+		events <- `{"jsonrpc":"2.0","id":1,"result":{"answer":42}}`
+	}))
+	defer server.Close()
+
+	transport, err := NewSSE(server.URL)
+	require.NoError(t, err)
+	defer transport.Close()
+	require.NoError(t, transport.Start(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	response, err := transport.SendRequest(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(1)),
+		Method:  "test/answer",
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"answer":42}`, string(response.Result))
+
+	select {
+	case pingResponse := <-pingResponses:
+		require.Equal(t, mcp.NewRequestId(int64(1)), pingResponse.ID)
+		require.JSONEq(t, `{}`, string(pingResponse.Result))
+		require.Nil(t, pingResponse.Error)
+	case <-ctx.Done():
+		t.Fatal("ping was not answered")
+	}
+}
@@ -0,0 +1,394 @@
+package conformance
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Check is a single named conformance check.
+type Check struct {
+	// Name identifies the check, e.g. "pagination/tools".
+	Name string
+	// Description summarizes the behaviour the check verifies.
+	Description string
+
+	run func(c *conn)
+}
+
+// Checks returns all conformance checks in the order Run executes them.
+func Checks() []Check {
+	return []Check{
+		{
+			Name:        "lifecycle/initialize",
+			Description: "initialize returns the protocol version, server info and capabilities",
+			run:         checkInitialize,
+		},
+		{
+			Name:        "lifecycle/ping-before-initialize",
+			Description: "ping is answered before initialization",
+			run:         checkPingBeforeInitialize,
+		},
+		{
+			Name:        "ping",
+			Description: "ping returns an empty result",
+			run:         checkPing,
+		},
+		{
+			Name:        "version/latest",
+			Description: "the latest protocol version is accepted",
+			run:         checkVersionLatest,
+		},
+		{
+			Name:        "version/unsupported",
+			Description: "an unsupported protocol version is answered with a supported one",
+			run:         checkVersionUnsupported,
+		},
+		{
+			Name:        "errors/unknown-method",
+			Description: "unknown methods fail with METHOD_NOT_FOUND",
+			run:         checkUnknownMethod,
+		},
+		{
+			Name:        "errors/invalid-jsonrpc-version",
+			Description: "requests with a JSON-RPC version other than 2.0 fail with INVALID_REQUEST",
+			run:         checkInvalidJSONRPCVersion,
+		},
+		{
+			Name:        "errors/unknown-tool",
+			Description: "calling an unknown tool fails with INVALID_PARAMS",
+			run:         checkUnknownTool,
+		},
+		{
+			Name:        "capabilities/gated-methods",
+			Description: "methods of capabilities the server did not announce fail with METHOD_NOT_FOUND",
+			run:         checkGatedMethods,
+		},
+		paginationCheck("tools", mcp.MethodToolsList, "tools"),
+		paginationCheck("prompts", mcp.MethodPromptsList, "prompts"),
+		paginationCheck("resources", mcp.MethodResourcesList, "resources"),
+		paginationCheck("resource-templates", mcp.MethodResourcesTemplatesList, "resourceTemplates"),
+		listChangedCheck("tools", mcp.MethodNotificationToolsListChanged),
+		listChangedCheck("prompts", mcp.MethodNotificationPromptsListChanged),
+		listChangedCheck("resources", mcp.MethodNotificationResourcesListChanged),
+		{
+			Name:        "tasks/lifecycle",
+			Description: "a task-augmented tool call moves through valid states and yields a result",
+			run:         checkTaskLifecycle,
+		},
+		{
+			Name:        "tasks/unknown-task",
+			Description: "tasks/get with an unknown task ID fails with INVALID_PARAMS",
+			run:         checkUnknownTask,
+		},
+	}
+}
+
+func checkInitialize(c *conn) {
+	result := c.initialize(mcp.LATEST_PROTOCOL_VERSION)
+	if result.ProtocolVersion == "" {
+		c.Errorf("initialize: missing protocolVersion")
+	}
+	if result.ServerInfo.Name == "" {
+		c.Errorf("initialize: missing serverInfo.name")
+	}
+	if result.ServerInfo.Version == "" {
+		c.Errorf("initialize: missing serverInfo.version")
+	}
+
+	// After the handshake, the session is operational.
+	c.request(string(mcp.MethodPing), nil, nil)
+}
+
+func checkPingBeforeInitialize(c *conn) {
+	c.request(string(mcp.MethodPing), nil, nil)
+}
+
+func checkPing(c *conn) {
+	c.initialize(mcp.LATEST_PROTOCOL_VERSION)
+
+	var result map[string]any
+	c.request(string(mcp.MethodPing), nil, &result)
+	for key := range result {
+		if key != "_meta" {
+			c.Errorf("ping: expected an empty result, got field %q", key)
+		}
+	}
+}
+
+func checkVersionLatest(c *conn) {
+	result := c.initialize(mcp.LATEST_PROTOCOL_VERSION)
+	if !slices.Contains(mcp.ValidProtocolVersions, result.ProtocolVersion) {
+		c.Errorf("initialize: unknown protocol version %q", result.ProtocolVersion)
+	}
+}
+
+func checkVersionUnsupported(c *conn) {
+	const unsupported = "1999-01-01"
+	result := c.initialize(unsupported)
+	if result.ProtocolVersion == unsupported {
+		c.Errorf("initialize: server accepted unsupported protocol version %q", unsupported)
+	}
+	if !slices.Contains(mcp.ValidProtocolVersions, result.ProtocolVersion) {
+		c.Errorf("initialize: unknown protocol version %q", result.ProtocolVersion)
+	}
+}
+
+func checkUnknownMethod(c *conn) {
+	c.initialize(mcp.LATEST_PROTOCOL_VERSION)
+	c.expectError("conformance/unknown-method", nil, mcp.METHOD_NOT_FOUND)
+}
+
+func checkInvalidJSONRPCVersion(c *conn) {
+	c.initialize(mcp.LATEST_PROTOCOL_VERSION)
+	response := c.callWithVersion("1.0", string(mcp.MethodPing), nil)
+	if response.Error == nil {
+		c.Errorf("ping with jsonrpc 1.0: expected error %d, got result %s", mcp.INVALID_REQUEST, response.Result)
+	} else if response.Error.Code != mcp.INVALID_REQUEST {
+		c.Errorf("ping with jsonrpc 1.0: expected error %d, got %d: %s", mcp.INVALID_REQUEST, response.Error.Code, response.Error.Message)
+	}
+}
+
+func checkUnknownTool(c *conn) {
+	c.initialize(mcp.LATEST_PROTOCOL_VERSION)
+	if c.Capabilities().Tools == nil {
+		c.Skipf("server does not support tools")
+	}
+	c.expectError(string(mcp.MethodToolsCall), map[string]any{
+		"name":      "conformance-unknown-tool",
+		"arguments": map[string]any{},
+	}, mcp.INVALID_PARAMS)
+}
+
+func checkGatedMethods(c *conn) {
+	capabilities := c.initialize(mcp.LATEST_PROTOCOL_VERSION).Capabilities
+
+	gated := []struct {
+		announced bool
+		method    mcp.MCPMethod
+		params    any
+	}{
+		{capabilities.Tools != nil, mcp.MethodToolsList, nil},
+		{capabilities.Tools != nil, mcp.MethodToolsCall, map[string]any{"name": "conformance"}},
+		{capabilities.Prompts != nil, mcp.MethodPromptsList, nil},
+		{capabilities.Prompts != nil, mcp.MethodPromptsGet, map[string]any{"name": "conformance"}},
+		{capabilities.Resources != nil, mcp.MethodResourcesList, nil},
+		{capabilities.Resources != nil, mcp.MethodResourcesTemplatesList, nil},
+		{capabilities.Resources != nil, mcp.MethodResourcesRead, map[string]any{"uri": "conformance:///"}},
+		{capabilities.Logging != nil, mcp.MethodSetLogLevel, map[string]any{"level": "info"}},
+		{capabilities.Completions != nil, mcp.MethodCompletionComplete, map[string]any{}},
+		{capabilities.Tasks != nil, mcp.MethodTasksList, nil},
+		{capabilities.Tasks != nil, mcp.MethodTasksGet, map[string]any{"taskId": "conformance"}},
+	}
+
+	checked := 0
+	for _, method := range gated {
+		if method.announced {
+			continue
+		}
+		checked++
+		c.expectError(string(method.method), method.params, mcp.METHOD_NOT_FOUND)
+	}
+	if checked == 0 {
+		c.Skipf("server announces every capability")
+	}
+}
+
+// paginationCheck walks all pages of a list and checks that an invalid
+// cursor is rejected.
+func paginationCheck(name string, method mcp.MCPMethod, field string) Check {
+	return Check{
+		Name:        "pagination/" + name,
+		Description: "all pages of " + string(method) + " can be walked and invalid cursors fail with INVALID_PARAMS",
+		run: func(c *conn) {
+			capabilities := c.initialize(mcp.LATEST_PROTOCOL_VERSION).Capabilities
+			supported := capabilities.Resources != nil
+			if field == "tools" {
+				supported = capabilities.Tools != nil
+			} else if field == "prompts" {
+				supported = capabilities.Prompts != nil
+			}
+			if !supported {
+				c.Skipf("server does not support %s", name)
+			}
+
+			seen := make(map[string]bool)
+			var cursor mcp.Cursor
+			for page := 0; ; page++ {
+				if page == 1000 {
+					c.Fatalf("%s: pagination did not terminate after %d pages", method, page)
+				}
+				params := map[string]any{}
+				if cursor != "" {
+					params["cursor"] = cursor
+				}
+
+				var result map[string]json.RawMessage
+				c.request(string(method), params, &result)
+
+				var items []map[string]any
+				if err := json.Unmarshal(result[field], &items); err != nil {
+					c.Fatalf("%s: decoding %s: %v", method, field, err)
+				}
+				for _, item := range items {
+					key, _ := item["name"].(string)
+					if uri, ok := item["uri"].(string); ok {
+						key = uri
+					} else if template, ok := item["uriTemplate"].(string); ok {
+						key = template
+					}
+					if seen[key] {
+						c.Errorf("%s: %q returned on more than one page", method, key)
+					}
+					seen[key] = true
+				}
+
+				cursor = ""
+				if next, ok := result["nextCursor"]; ok {
+					if err := json.Unmarshal(next, &cursor); err != nil {
+						c.Fatalf("%s: decoding nextCursor: %v", method, err)
+					}
+				}
+				if cursor == "" {
+					break
+				}
+			}
+
+			c.expectError(string(method), map[string]any{"cursor": "conformance-invalid-cursor"}, mcp.INVALID_PARAMS)
+		},
+	}
+}
+
+// listChangedCheck changes a list with the configured trigger and expects the
+// matching notification if the server announced listChanged.
+func listChangedCheck(kind string, notification string) Check {
+	return Check{
+		Name:        "list-changed/" + kind,
+		Description: "changing the " + kind + " list sends " + notification,
+		run: func(c *conn) {
+			if c.cfg.trigger == nil {
+				c.Skipf("no list changed trigger configured")
+			}
+			capabilities := c.initialize(mcp.LATEST_PROTOCOL_VERSION).Capabilities
+			var listChanged bool
+			switch kind {
+			case "tools":
+				listChanged = capabilities.Tools != nil && capabilities.Tools.ListChanged
+			case "prompts":
+				listChanged = capabilities.Prompts != nil && capabilities.Prompts.ListChanged
+			case "resources":
+				listChanged = capabilities.Resources != nil && capabilities.Resources.ListChanged
+			}
+			if !listChanged {
+				c.Skipf("server does not announce %s.listChanged", kind)
+			}
+
+			c.cfg.trigger(c.t, kind)
+			if !c.waitForNotification(notification, c.cfg.timeout/2) {
+				c.Errorf("did not receive %s", notification)
+			}
+		},
+	}
+}
+
+// taskTransitions lists the states a task may move to from each state.
+var taskTransitions = map[mcp.TaskStatus][]mcp.TaskStatus{
+	mcp.TaskStatusWorking: {
+		mcp.TaskStatusWorking, mcp.TaskStatusInputRequired,
+		mcp.TaskStatusCompleted, mcp.TaskStatusFailed, mcp.TaskStatusCancelled,
+	},
+	mcp.TaskStatusInputRequired: {
+		mcp.TaskStatusWorking, mcp.TaskStatusInputRequired,
+		mcp.TaskStatusCompleted, mcp.TaskStatusFailed, mcp.TaskStatusCancelled,
+	},
+	mcp.TaskStatusCompleted: {mcp.TaskStatusCompleted},
+	mcp.TaskStatusFailed:    {mcp.TaskStatusFailed},
+	mcp.TaskStatusCancelled: {mcp.TaskStatusCancelled},
+}
+
+func checkTaskLifecycle(c *conn) {
+	capabilities := c.initialize(mcp.LATEST_PROTOCOL_VERSION).Capabilities
+	if capabilities.Tasks == nil || capabilities.Tasks.Requests == nil ||
+		capabilities.Tasks.Requests.Tools == nil || capabilities.Tasks.Requests.Tools.Call == nil {
+		c.Skipf("server does not support task-augmented tool calls")
+	}
+
+	name, arguments := c.taskTool()
+
+	var created mcp.CreateTaskResult
+	c.request(string(mcp.MethodToolsCall), map[string]any{
+		"name":      name,
+		"arguments": arguments,
+		"task":      map[string]any{},
+	}, &created)
+	task := created.Task
+	if task.TaskId == "" {
+		c.Fatalf("tools/call: missing task.taskId")
+	}
+	if task.Status != mcp.TaskStatusWorking {
+		c.Errorf("tools/call: new task has status %q, want %q", task.Status, mcp.TaskStatusWorking)
+	}
+
+	for !task.Status.IsTerminal() {
+		interval := 50 * time.Millisecond
+		if task.PollInterval != nil && *task.PollInterval > 0 {
+			interval = min(time.Duration(*task.PollInterval)*time.Millisecond, time.Second)
+		}
+		select {
+		case <-time.After(interval):
+		case <-c.ctx.Done():
+			c.Fatalf("task %s did not finish, last status %q", task.TaskId, task.Status)
+		}
+
+		var current mcp.GetTaskResult
+		c.request(string(mcp.MethodTasksGet), map[string]any{"taskId": task.TaskId}, &current)
+		if current.TaskId != task.TaskId {
+			c.Errorf("tasks/get: got task %q, want %q", current.TaskId, task.TaskId)
+		}
+		if !slices.Contains(taskTransitions[task.Status], current.Status) {
+			c.Fatalf("tasks/get: invalid transition from %q to %q", task.Status, current.Status)
+		}
+		task = current.Task
+	}
+
+	if task.Status == mcp.TaskStatusCompleted {
+		c.request(string(mcp.MethodTasksResult), map[string]any{"taskId": task.TaskId}, nil)
+	}
+
+	// Terminal states are final.
+	var final mcp.GetTaskResult
+	c.request(string(mcp.MethodTasksGet), map[string]any{"taskId": task.TaskId}, &final)
+	if final.Status != task.Status {
+		c.Errorf("tasks/get: terminal status changed from %q to %q", task.Status, final.Status)
+	}
+}
+
+// taskTool returns the tool used by the task checks.
+func (c *conn) taskTool() (string, map[string]any) {
+	if c.cfg.taskTool != "" {
+		return c.cfg.taskTool, c.cfg.taskArgs
+	}
+
+	var result mcp.ListToolsResult
+	c.request(string(mcp.MethodToolsList), nil, &result)
+	for _, tool := range result.Tools {
+		if tool.Execution == nil || tool.Execution.TaskSupport == "" || tool.Execution.TaskSupport == mcp.TaskSupportForbidden {
+			continue
+		}
+		if len(tool.InputSchema.Required) == 0 {
+			return tool.Name, map[string]any{}
+		}
+	}
+	c.Skipf("no task tool without required arguments; use WithTaskTool")
+	return "", nil
+}
+
+func checkUnknownTask(c *conn) {
+	capabilities := c.initialize(mcp.LATEST_PROTOCOL_VERSION).Capabilities
+	if capabilities.Tasks == nil {
+		c.Skipf("server does not support tasks")
+	}
+	c.expectError(string(mcp.MethodTasksGet), map[string]any{"taskId": "conformance-unknown-task"}, mcp.INVALID_PARAMS)
+}
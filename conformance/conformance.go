@@ -0,0 +1,248 @@
+// Package conformance checks that an MCP server follows the protocol.
+//
+// The checks talk to the server through a transport.Interface, so they can be
+// run against a server built with this library as well as against any other
+// server reachable over stdio or HTTP:
+//
+//	func TestConformance(t *testing.T) {
+//		conformance.Run(t, conformance.InProcess(newServer()))
+//	}
+//
+//	func TestExternalConformance(t *testing.T) {
+//		conformance.Run(t, func(t *testing.T) transport.Interface {
+//			return transport.NewStdio("./my-server", nil)
+//		})
+//	}
+//
+// Every check runs as a named subtest on a fresh connection, so individual
+// checks can be selected with go test -run or skipped with Skip.
+package conformance
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Factory returns a new, unstarted connection to the server under test.
+// Run starts and closes the connection.
+type Factory func(t *testing.T) transport.Interface
+
+// InProcess returns a Factory that connects to mcpServer in-process. All
+// connections share the server, each with its own session.
+func InProcess(mcpServer *server.MCPServer) Factory {
+	return func(t *testing.T) transport.Interface {
+		return transport.NewInProcessTransportWithOptions(mcpServer)
+	}
+}
+
+// Option configures Run.
+type Option func(*config)
+
+type config struct {
+	skip       []string
+	only       []string
+	reportPath string
+	timeout    time.Duration
+	trigger    ListChangedTrigger
+	taskTool   string
+	taskArgs   map[string]any
+}
+
+// ListChangedTrigger changes the list of the given kind on the server under
+// test, so that the server sends the matching list_changed notification.
+// The kind is one of "tools", "prompts" or "resources".
+type ListChangedTrigger func(t *testing.T, kind string)
+
+// Skip skips the checks whose names match any of the patterns. Patterns use
+// path.Match syntax, so "pagination/*" skips all pagination checks.
+func Skip(patterns ...string) Option {
+	return func(c *config) {
+		c.skip = append(c.skip, patterns...)
+	}
+}
+
+// Only runs only the checks whose names match any of the patterns.
+func Only(patterns ...string) Option {
+	return func(c *config) {
+		c.only = append(c.only, patterns...)
+	}
+}
+
+// WithReportFile writes the report as JSON to path once all checks have run.
+func WithReportFile(path string) Option {
+	return func(c *config) {
+		c.reportPath = path
+	}
+}
+
+// WithTimeout sets the timeout of each check. The default is 10 seconds.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// WithListChangedTrigger enables the list-changed checks, which call trigger
+// and expect the server to notify the client.
+func WithListChangedTrigger(trigger ListChangedTrigger) Option {
+	return func(c *config) {
+		c.trigger = trigger
+	}
+}
+
+// WithTaskTool selects the tool and arguments used by the task checks. By
+// default, the first tool that supports tasks and has no required arguments
+// is used.
+func WithTaskTool(name string, arguments map[string]any) Option {
+	return func(c *config) {
+		c.taskTool = name
+		c.taskArgs = arguments
+	}
+}
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name     string  `json:"name"`
+	Status   Status  `json:"status"`
+	Message  string  `json:"message,omitempty"`
+	Duration float64 `json:"durationMs"`
+}
+
+// Report is the machine-readable outcome of Run.
+type Report struct {
+	Results []Result `json:"results"`
+	Passed  int      `json:"passed"`
+	Failed  int      `json:"failed"`
+	Skipped int      `json:"skipped"`
+}
+
+// Result returns the result of the named check.
+func (r *Report) Result(name string) (Result, bool) {
+	for _, result := range r.Results {
+		if result.Name == name {
+			return result, true
+		}
+	}
+	return Result{}, false
+}
+
+func (r *Report) add(result Result) {
+	r.Results = append(r.Results, result)
+	switch result.Status {
+	case StatusPass:
+		r.Passed++
+	case StatusFail:
+		r.Failed++
+	case StatusSkip:
+		r.Skipped++
+	}
+}
+
+// Run runs the conformance checks against the server returned by factory
+// and returns their results.
+func Run(t *testing.T, factory Factory, opts ...Option) *Report {
+	t.Helper()
+	cfg := &config{timeout: 10 * time.Second}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	report := &Report{}
+	for _, check := range Checks() {
+		if !cfg.selected(check.Name) {
+			report.add(Result{Name: check.Name, Status: StatusSkip, Message: "skipped by option"})
+			continue
+		}
+
+		result := &checkResult{}
+		start := time.Now()
+		passed := t.Run(check.Name, func(t *testing.T) {
+			c := newConn(t, factory, cfg, result)
+			check.run(c)
+		})
+
+		status := StatusFail
+		switch {
+		case result.skipped():
+			status = StatusSkip
+		case passed:
+			status = StatusPass
+		}
+		report.add(Result{
+			Name:     check.Name,
+			Status:   status,
+			Message:  result.message(),
+			Duration: float64(time.Since(start).Microseconds()) / 1000,
+		})
+	}
+
+	if cfg.reportPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err == nil {
+			err = os.WriteFile(cfg.reportPath, data, 0o644)
+		}
+		if err != nil {
+			t.Errorf("conformance: writing report: %v", err)
+		}
+	}
+	return report
+}
+
+func (c *config) selected(name string) bool {
+	if len(c.only) > 0 && !matchAny(c.only, name) {
+		return false
+	}
+	return !matchAny(c.skip, name)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok || pattern == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkResult collects the messages a check reports.
+type checkResult struct {
+	mu       sync.Mutex
+	messages []string
+	skip     bool
+}
+
+func (r *checkResult) add(message string, skip bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, message)
+	r.skip = r.skip || skip
+}
+
+func (r *checkResult) skipped() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.skip
+}
+
+func (r *checkResult) message() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.messages, "; ")
+}
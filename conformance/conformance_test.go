@@ -0,0 +1,151 @@
+package conformance_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/conformance"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newServer returns a server using every feature the checks exercise, and a
+// trigger that changes its lists.
+func newServer() (*server.MCPServer, conformance.ListChangedTrigger) {
+	mcpServer := server.NewMCPServer(
+		"conformance-test",
+		"1.0.0",
+		server.WithToolCapabilities(true),
+		server.WithPromptCapabilities(true),
+		server.WithResourceCapabilities(true, true),
+		server.WithTaskCapabilities(true, true, true),
+		server.WithPaginationLimit(2),
+	)
+
+	for i := range 5 {
+		mcpServer.AddTool(mcp.NewTool(fmt.Sprintf("tool-%d", i)),
+			func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("ok"), nil
+			})
+		mcpServer.AddPrompt(mcp.NewPrompt(fmt.Sprintf("prompt-%d", i)),
+			func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				return &mcp.GetPromptResult{}, nil
+			})
+		mcpServer.AddResource(mcp.NewResource(fmt.Sprintf("test://resource/%d", i), fmt.Sprintf("resource-%d", i)),
+			func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return nil, nil
+			})
+		mcpServer.AddResourceTemplate(mcp.NewResourceTemplate(fmt.Sprintf("test://template/%d/{id}", i), fmt.Sprintf("template-%d", i)),
+			func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return nil, nil
+			})
+	}
+
+	mcpServer.AddTaskTool(mcp.NewTool("slow", mcp.WithTaskSupport(mcp.TaskSupportRequired)),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CreateTaskResult, error) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(50 * time.Millisecond):
+			}
+			return &mcp.CreateTaskResult{}, nil
+		})
+
+	var changes atomic.Int64
+	trigger := func(t *testing.T, kind string) {
+		name := fmt.Sprintf("added-%d", changes.Add(1))
+		switch kind {
+		case "tools":
+			mcpServer.AddTool(mcp.NewTool(name),
+				func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+					return mcp.NewToolResultText("ok"), nil
+				})
+		case "prompts":
+			mcpServer.AddPrompt(mcp.NewPrompt(name),
+				func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+					return &mcp.GetPromptResult{}, nil
+				})
+		case "resources":
+			mcpServer.AddResource(mcp.NewResource("test://"+name, name),
+				func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+					return nil, nil
+				})
+		}
+	}
+	return mcpServer, trigger
+}
+
+func TestRun(t *testing.T) {
+	mcpServer, trigger := newServer()
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+
+	report := conformance.Run(t, conformance.InProcess(mcpServer),
+		conformance.WithListChangedTrigger(trigger),
+		conformance.WithReportFile(reportPath),
+	)
+
+	if report.Failed != 0 {
+		t.Errorf("Got %d failed checks, want 0", report.Failed)
+	}
+	for _, check := range conformance.Checks() {
+		result, ok := report.Result(check.Name)
+		if !ok {
+			t.Errorf("Check %s missing from report", check.Name)
+			continue
+		}
+		if result.Status != conformance.StatusPass {
+			t.Errorf("Check %s: got status %s (%s), want pass", check.Name, result.Status, result.Message)
+		}
+	}
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written conformance.Report
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatal(err)
+	}
+	if len(written.Results) != len(conformance.Checks()) || written.Passed != report.Passed {
+		t.Errorf("Written report %+v does not match returned report %+v", written, report)
+	}
+}
+
+func TestRun_Selection(t *testing.T) {
+	mcpServer := server.NewMCPServer("conformance-test", "1.0.0")
+
+	report := conformance.Run(t, conformance.InProcess(mcpServer),
+		conformance.Only("lifecycle/*", "errors/*", "pagination/tools"),
+		conformance.Skip("errors/invalid-jsonrpc-version"),
+	)
+
+	want := map[string]conformance.Status{
+		"lifecycle/initialize":             conformance.StatusPass,
+		"lifecycle/ping-before-initialize": conformance.StatusPass,
+		"errors/unknown-method":            conformance.StatusPass,
+		"errors/invalid-jsonrpc-version":   conformance.StatusSkip,
+		// The server has no tools.
+		"errors/unknown-tool": conformance.StatusSkip,
+		"pagination/tools":    conformance.StatusSkip,
+		"ping":                conformance.StatusSkip,
+	}
+	for name, status := range want {
+		result, ok := report.Result(name)
+		if !ok {
+			t.Errorf("Check %s missing from report", name)
+			continue
+		}
+		if result.Status != status {
+			t.Errorf("Check %s: got status %s, want %s", name, result.Status, status)
+		}
+	}
+	if report.Passed != 3 || report.Failed != 0 {
+		t.Errorf("Got %d passed and %d failed checks, want 3 and 0", report.Passed, report.Failed)
+	}
+}
@@ -0,0 +1,188 @@
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// conn is the state of a running check: the test, a connection to the server
+// under test and the options passed to Run.
+type conn struct {
+	t      *testing.T
+	ctx    context.Context
+	cfg    *config
+	result *checkResult
+
+	transport transport.Interface
+	nextID    atomic.Int64
+
+	mu            sync.Mutex
+	notifications []mcp.JSONRPCNotification
+	notified      chan struct{}
+
+	// Initialized by initialize.
+	server mcp.InitializeResult
+}
+
+func newConn(t *testing.T, factory Factory, cfg *config, result *checkResult) *conn {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.timeout)
+	t.Cleanup(cancel)
+
+	c := &conn{
+		t:        t,
+		ctx:      ctx,
+		cfg:      cfg,
+		result:   result,
+		notified: make(chan struct{}, 1),
+	}
+
+	c.transport = factory(t)
+	if err := c.transport.Start(ctx); err != nil {
+		c.Fatalf("starting transport: %v", err)
+	}
+	t.Cleanup(func() { _ = c.transport.Close() })
+	c.transport.SetNotificationHandler(c.handleNotification)
+	return c
+}
+
+// Errorf reports a failure and continues the check.
+func (c *conn) Errorf(format string, args ...any) {
+	c.t.Helper()
+	message := fmt.Sprintf(format, args...)
+	c.result.add(message, false)
+	c.t.Error(message)
+}
+
+// Fatalf reports a failure and stops the check.
+func (c *conn) Fatalf(format string, args ...any) {
+	c.t.Helper()
+	message := fmt.Sprintf(format, args...)
+	c.result.add(message, false)
+	c.t.Fatal(message)
+}
+
+// Skipf marks the check as not applicable to the server and stops it.
+func (c *conn) Skipf(format string, args ...any) {
+	c.t.Helper()
+	message := fmt.Sprintf(format, args...)
+	c.result.add(message, true)
+	c.t.Skip(message)
+}
+
+// Capabilities returns the capabilities the server announced during initialization.
+func (c *conn) Capabilities() mcp.ServerCapabilities {
+	return c.server.Capabilities
+}
+
+// call sends a request and returns the raw response.
+func (c *conn) call(method string, params any) *transport.JSONRPCResponse {
+	c.t.Helper()
+	return c.callWithVersion(mcp.JSONRPC_VERSION, method, params)
+}
+
+func (c *conn) callWithVersion(version, method string, params any) *transport.JSONRPCResponse {
+	c.t.Helper()
+	response, err := c.transport.SendRequest(c.ctx, transport.JSONRPCRequest{
+		JSONRPC: version,
+		ID:      mcp.NewRequestId(c.nextID.Add(1)),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		c.Fatalf("%s: %v", method, err)
+	}
+	return response
+}
+
+// request sends a request, requires a successful response and decodes its result into result.
+func (c *conn) request(method string, params any, result any) {
+	c.t.Helper()
+	response := c.call(method, params)
+	if response.Error != nil {
+		c.Fatalf("%s: unexpected error %d: %s", method, response.Error.Code, response.Error.Message)
+	}
+	if result == nil {
+		return
+	}
+	if err := json.Unmarshal(response.Result, result); err != nil {
+		c.Fatalf("%s: decoding result: %v", method, err)
+	}
+}
+
+// expectError sends a request and checks that it fails with the given code.
+func (c *conn) expectError(method string, params any, code int) {
+	c.t.Helper()
+	response := c.call(method, params)
+	if response.Error == nil {
+		c.Errorf("%s: expected error %d, got result %s", method, code, response.Result)
+		return
+	}
+	if response.Error.Code != code {
+		c.Errorf("%s: expected error %d, got %d: %s", method, code, response.Error.Code, response.Error.Message)
+	}
+}
+
+// initialize performs the initialization handshake with the given protocol version.
+func (c *conn) initialize(version string) mcp.InitializeResult {
+	c.t.Helper()
+	var result mcp.InitializeResult
+	c.request(string(mcp.MethodInitialize), mcp.InitializeParams{
+		ProtocolVersion: version,
+		ClientInfo:      mcp.Implementation{Name: "mcp-go-conformance", Version: "1.0.0"},
+		Capabilities:    mcp.ClientCapabilities{},
+	}, &result)
+
+	if httpConnection, ok := c.transport.(transport.HTTPConnection); ok {
+		httpConnection.SetProtocolVersion(result.ProtocolVersion)
+	}
+	err := c.transport.SendNotification(c.ctx, mcp.JSONRPCNotification{
+		JSONRPC:      mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{Method: "notifications/initialized"},
+	})
+	if err != nil {
+		c.Fatalf("notifications/initialized: %v", err)
+	}
+	c.server = result
+	return result
+}
+
+func (c *conn) handleNotification(notification mcp.JSONRPCNotification) {
+	c.mu.Lock()
+	c.notifications = append(c.notifications, notification)
+	c.mu.Unlock()
+	select {
+	case c.notified <- struct{}{}:
+	default:
+	}
+}
+
+// waitForNotification waits until a notification with method has been received.
+func (c *conn) waitForNotification(method string, timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	for {
+		c.mu.Lock()
+		for _, notification := range c.notifications {
+			if notification.Method == method {
+				c.mu.Unlock()
+				return true
+			}
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-c.notified:
+		case <-deadline:
+			return false
+		case <-c.ctx.Done():
+			return false
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/invopop/jsonschema"
+)
+
+// ElicitationSchema is the restricted JSON Schema used as the requestedSchema of
+// a form mode elicitation request. The specification only allows flat objects
+// whose properties are primitive values (string, number, integer, boolean) or
+// string enums, so that clients can render them as simple forms.
+// https://modelcontextprotocol.io/specification/2025-06-18/client/elicitation#requested-schema
+type ElicitationSchema struct {
+	// Type is always "object".
+	Type string `json:"type"`
+	// Properties maps field names to their primitive schema definitions.
+	Properties map[string]ElicitationProperty `json:"properties"`
+	// Required lists the fields the user must provide.
+	Required []string `json:"required,omitempty"`
+}
+
+// ElicitationProperty is a primitive schema definition for a single field of an
+// ElicitationSchema.
+type ElicitationProperty struct {
+	// Type is one of "string", "number", "integer" or "boolean".
+	Type        string `json:"type"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Format is only valid for strings and is one of "email", "uri", "date" or "date-time".
+	Format string `json:"format,omitempty"`
+	// Enum restricts a string field to a fixed set of values.
+	Enum      []string `json:"enum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	Default   any      `json:"default,omitempty"`
+}
+
+// elicitationFormats are the string formats allowed by the elicitation specification.
+var elicitationFormats = []string{"email", "uri", "date", "date-time"}
+
+// NewElicitationSchema generates an ElicitationSchema from the struct type T.
+//
+// Field names, required fields and constraints are derived from the same
+// `json` and `jsonschema` struct tags used by WithInputSchema, for example:
+//
+//	type Contact struct {
+//		Name  string `json:"name" jsonschema:"title=Full name,minLength=1"`
+//		Email string `json:"email" jsonschema:"format=email"`
+//		Plan  string `json:"plan,omitempty" jsonschema:"enum=free,enum=pro,default=free"`
+//	}
+//
+// An error is returned if T is not a struct or if any field is not a primitive
+// value, since nested objects and arrays are not allowed in elicitation schemas.
+func NewElicitationSchema[T any]() (*ElicitationSchema, error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	reflector := jsonschema.Reflector{
+		DoNotReference: true, // Nested types must be inlined so they can be rejected
+		Anonymous:      true, // Hides auto-generated Schema IDs
+	}
+	schema := reflector.ReflectFromType(typ)
+
+	if schema.Type != "object" || schema.Properties == nil {
+		return nil, fmt.Errorf("elicitation schema for %s: type must be a struct", typ)
+	}
+
+	result := &ElicitationSchema{
+		Type:       "object",
+		Properties: make(map[string]ElicitationProperty, schema.Properties.Len()),
+		Required:   schema.Required,
+	}
+
+	for pair := schema.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		property, err := newElicitationProperty(pair.Value)
+		if err != nil {
+			return nil, fmt.Errorf("elicitation schema for %s: field %q: %w", typ, pair.Key, err)
+		}
+		result.Properties[pair.Key] = property
+	}
+
+	return result, nil
+}
+
+// newElicitationProperty converts a reflected JSON Schema into a primitive
+// schema definition, rejecting anything the elicitation specification forbids.
+func newElicitationProperty(schema *jsonschema.Schema) (ElicitationProperty, error) {
+	if schema.Ref != "" || len(schema.AllOf) > 0 || len(schema.AnyOf) > 0 || len(schema.OneOf) > 0 {
+		return ElicitationProperty{}, fmt.Errorf("composite schemas are not supported")
+	}
+
+	property := ElicitationProperty{
+		Type:        schema.Type,
+		Title:       schema.Title,
+		Description: schema.Description,
+		Default:     schema.Default,
+	}
+
+	switch schema.Type {
+	case "string":
+		if schema.Format != "" {
+			if !slices.Contains(elicitationFormats, schema.Format) {
+				return ElicitationProperty{}, fmt.Errorf("unsupported string format %q", schema.Format)
+			}
+			property.Format = schema.Format
+		}
+		for _, value := range schema.Enum {
+			s, ok := value.(string)
+			if !ok {
+				return ElicitationProperty{}, fmt.Errorf("enum values must be strings, got %T", value)
+			}
+			property.Enum = append(property.Enum, s)
+		}
+		if schema.MinLength != nil {
+			minLength := int(*schema.MinLength)
+			property.MinLength = &minLength
+		}
+		if schema.MaxLength != nil {
+			maxLength := int(*schema.MaxLength)
+			property.MaxLength = &maxLength
+		}
+	case "number", "integer":
+		if len(schema.Enum) > 0 {
+			return ElicitationProperty{}, fmt.Errorf("enums are only supported for strings")
+		}
+		if schema.Minimum != "" {
+			minimum, err := schema.Minimum.Float64()
+			if err != nil {
+				return ElicitationProperty{}, fmt.Errorf("invalid minimum: %w", err)
+			}
+			property.Minimum = &minimum
+		}
+		if schema.Maximum != "" {
+			maximum, err := schema.Maximum.Float64()
+			if err != nil {
+				return ElicitationProperty{}, fmt.Errorf("invalid maximum: %w", err)
+			}
+			property.Maximum = &maximum
+		}
+	case "boolean":
+		if len(schema.Enum) > 0 {
+			return ElicitationProperty{}, fmt.Errorf("enums are only supported for strings")
+		}
+	case "object":
+		return ElicitationProperty{}, fmt.Errorf("nested objects are not allowed in elicitation schemas")
+	case "array":
+		return ElicitationProperty{}, fmt.Errorf("arrays are not allowed in elicitation schemas")
+	default:
+		return ElicitationProperty{}, fmt.Errorf("field must be a string, number, integer or boolean")
+	}
+
+	return property, nil
+}
+
+// Validate checks that content, as returned by the client in an accepted
+// ElicitationResult, conforms to the schema.
+func (s *ElicitationSchema) Validate(content any) error {
+	values, ok := content.(map[string]any)
+	if !ok {
+		if content == nil && len(s.Required) == 0 {
+			return nil
+		}
+		return fmt.Errorf("content must be an object, got %T", content)
+	}
+
+	for _, name := range s.Required {
+		if values[name] == nil {
+			return fmt.Errorf("missing required field %q", name)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+		value, ok := values[name]
+		if !ok || value == nil {
+			continue
+		}
+		if err := s.Properties[name].validate(value); err != nil {
+			return fmt.Errorf("field %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// validate checks a single value against the property definition.
+func (p ElicitationProperty) validate(value any) error {
+	switch p.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string, got %T", value)
+		}
+		length := utf8.RuneCountInString(s)
+		if p.MinLength != nil && length < *p.MinLength {
+			return fmt.Errorf("length %d is less than minimum %d", length, *p.MinLength)
+		}
+		if p.MaxLength != nil && length > *p.MaxLength {
+			return fmt.Errorf("length %d exceeds maximum %d", length, *p.MaxLength)
+		}
+		if len(p.Enum) > 0 && !slices.Contains(p.Enum, s) {
+			return fmt.Errorf("value %q is not one of %v", s, p.Enum)
+		}
+		return validateElicitationFormat(p.Format, s)
+	case "number", "integer":
+		n, ok := elicitationNumber(value)
+		if !ok {
+			return fmt.Errorf("expected %s, got %T", p.Type, value)
+		}
+		if p.Type == "integer" && n != math.Trunc(n) {
+			return fmt.Errorf("expected integer, got %v", n)
+		}
+		if p.Minimum != nil && n < *p.Minimum {
+			return fmt.Errorf("value %v is less than minimum %v", n, *p.Minimum)
+		}
+		if p.Maximum != nil && n > *p.Maximum {
+			return fmt.Errorf("value %v exceeds maximum %v", n, *p.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected boolean, got %T", value)
+		}
+	default:
+		return fmt.Errorf("unsupported property type %q", p.Type)
+	}
+	return nil
+}
+
+// validateElicitationFormat checks a string against one of the formats allowed
+// in elicitation schemas. An empty format accepts any string.
+func validateElicitationFormat(format, value string) error {
+	var err error
+	switch format {
+	case "":
+		return nil
+	case "email":
+		var addr *mail.Address
+		addr, err = mail.ParseAddress(value)
+		if err == nil && addr.Address != value {
+			err = fmt.Errorf("unexpected display name")
+		}
+	case "uri":
+		var u *url.URL
+		u, err = url.Parse(value)
+		if err == nil && !u.IsAbs() {
+			err = fmt.Errorf("uri must be absolute")
+		}
+	case "date":
+		_, err = time.Parse(time.DateOnly, value)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	}
+	if err != nil {
+		return fmt.Errorf("value %q is not a valid %s: %w", value, format, err)
+	}
+	return nil
+}
+
+// elicitationNumber converts a decoded JSON number to float64. Content that did
+// not go through encoding/json (e.g. in-process clients) may carry Go integers.
+func elicitationNumber(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
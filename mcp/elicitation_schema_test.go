@@ -0,0 +1,174 @@
+package mcp_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type elicitationContact struct {
+	Name       string  `json:"name" jsonschema:"title=Full name,description=Your name,minLength=1,maxLength=10"`
+	Email      string  `json:"email" jsonschema:"format=email"`
+	Plan       string  `json:"plan,omitempty" jsonschema:"enum=free,enum=pro,default=free"`
+	Seats      int     `json:"seats,omitempty" jsonschema:"minimum=1,maximum=50"`
+	Budget     float64 `json:"budget,omitempty"`
+	Newsletter bool    `json:"newsletter,omitempty"`
+}
+
+func TestNewElicitationSchema(t *testing.T) {
+	schema, err := mcp.NewElicitationSchema[elicitationContact]()
+	require.NoError(t, err)
+
+	assert.Equal(t, "object", schema.Type)
+	assert.ElementsMatch(t, []string{"name", "email"}, schema.Required)
+	require.Len(t, schema.Properties, 6)
+
+	name := schema.Properties["name"]
+	assert.Equal(t, "string", name.Type)
+	assert.Equal(t, "Full name", name.Title)
+	assert.Equal(t, "Your name", name.Description)
+	require.NotNil(t, name.MinLength)
+	assert.Equal(t, 1, *name.MinLength)
+	require.NotNil(t, name.MaxLength)
+	assert.Equal(t, 10, *name.MaxLength)
+
+	assert.Equal(t, "email", schema.Properties["email"].Format)
+
+	plan := schema.Properties["plan"]
+	assert.Equal(t, []string{"free", "pro"}, plan.Enum)
+	assert.Equal(t, "free", plan.Default)
+
+	seats := schema.Properties["seats"]
+	assert.Equal(t, "integer", seats.Type)
+	require.NotNil(t, seats.Minimum)
+	assert.Equal(t, 1.0, *seats.Minimum)
+	require.NotNil(t, seats.Maximum)
+	assert.Equal(t, 50.0, *seats.Maximum)
+
+	assert.Equal(t, "number", schema.Properties["budget"].Type)
+	assert.Equal(t, "boolean", schema.Properties["newsletter"].Type)
+
+	// The schema must serialize as a plain JSON Schema object
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal(data, &raw))
+	assert.Equal(t, "object", raw["type"])
+	assert.Contains(t, raw["properties"], "email")
+}
+
+func TestNewElicitationSchema_RejectsForbiddenTypes(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+	}
+	type nested struct {
+		Address address `json:"address"`
+	}
+	type withSlice struct {
+		Tags []string `json:"tags"`
+	}
+	type withMap struct {
+		Extra map[string]string `json:"extra"`
+	}
+	type withFormat struct {
+		Host string `json:"host" jsonschema:"format=hostname"`
+	}
+	type withAny struct {
+		Value any `json:"value"`
+	}
+
+	tests := []struct {
+		name  string
+		build func() (*mcp.ElicitationSchema, error)
+	}{
+		{"nested struct", mcp.NewElicitationSchema[nested]},
+		{"slice", mcp.NewElicitationSchema[withSlice]},
+		{"map field", mcp.NewElicitationSchema[withMap]},
+		{"unsupported format", mcp.NewElicitationSchema[withFormat]},
+		{"untyped field", mcp.NewElicitationSchema[withAny]},
+		{"not a struct", mcp.NewElicitationSchema[string]},
+		{"top-level map", mcp.NewElicitationSchema[map[string]string]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := tt.build()
+			assert.Error(t, err)
+			assert.Nil(t, schema)
+		})
+	}
+}
+
+func TestElicitationSchema_Validate(t *testing.T) {
+	schema, err := mcp.NewElicitationSchema[elicitationContact]()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		content any
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			content: map[string]any{"name": "Ada", "email": "ada@example.com", "plan": "pro", "seats": float64(3)},
+		},
+		{
+			name:    "in-process integers",
+			content: map[string]any{"name": "Ada", "email": "ada@example.com", "seats": 3},
+		},
+		{
+			name:    "missing required",
+			content: map[string]any{"name": "Ada"},
+			wantErr: true,
+		},
+		{
+			name:    "wrong type",
+			content: map[string]any{"name": 42, "email": "ada@example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "too long",
+			content: map[string]any{"name": "Ada Lovelace-Byron", "email": "ada@example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid email",
+			content: map[string]any{"name": "Ada", "email": "not-an-email"},
+			wantErr: true,
+		},
+		{
+			name:    "enum mismatch",
+			content: map[string]any{"name": "Ada", "email": "ada@example.com", "plan": "enterprise"},
+			wantErr: true,
+		},
+		{
+			name:    "non integer",
+			content: map[string]any{"name": "Ada", "email": "ada@example.com", "seats": 1.5},
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			content: map[string]any{"name": "Ada", "email": "ada@example.com", "seats": float64(51)},
+			wantErr: true,
+		},
+		{
+			name:    "not an object",
+			content: "Ada",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(tt.content)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// contentSeeds are content blocks taken from the parsing tests.
+var contentSeeds = []string{
+	`{"type":"text","text":"Hello"}`,
+	`{"type":"text","text":"Hello","annotations":{"priority":1,"audience":["user","assistant"],"lastModified":"2025-01-01T00:00:00Z"}}`,
+	`{"type":"image","data":"aGVsbG8=","mimeType":"image/png"}`,
+	`{"type":"audio","data":"aGVsbG8=","mimeType":"audio/wav"}`,
+	`{"type":"resource_link","uri":"file:///a.txt","name":"a","description":"A file","mimeType":"text/plain"}`,
+	`{"type":"resource","resource":{"uri":"file:///a.txt","mimeType":"text/plain","text":"contents"}}`,
+	`{"type":"resource","resource":{"uri":"file:///a.bin","blob":"aGVsbG8=","_meta":{"k":"v"}}}`,
+	`{"type":"image"}`,
+	`{"type":"unknown"}`,
+	`{"annotations":{"priority":"high","audience":"user"}}`,
+}
+
+func FuzzParseContent(f *testing.F) {
+	for _, seed := range contentSeeds {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var contentMap map[string]any
+		if json.Unmarshal(data, &contentMap) != nil {
+			return
+		}
+		content, err := ParseContent(contentMap)
+		if err != nil {
+			return
+		}
+		if _, err := json.Marshal(content); err != nil {
+			t.Fatalf("marshaling parsed content: %v", err)
+		}
+	})
+}
+
+func FuzzParseCallToolResult(f *testing.F) {
+	f.Add([]byte(`{"content":[]}`))
+	f.Add([]byte(`{"content":[{"type":"text","text":"ok"}],"isError":true,"_meta":{"progressToken":1}}`))
+	f.Add([]byte(`{"content":[{"type":"text","text":"ok"}],"structuredContent":{"result":42}}`))
+	f.Add([]byte(`{"isError": false}`))
+	f.Add([]byte(`{"content": "not an array"}`))
+	f.Add([]byte(`{"content": ["not an object"]}`))
+	for _, seed := range contentSeeds {
+		f.Add([]byte(`{"content":[` + seed + `]}`))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		raw := json.RawMessage(data)
+		result, err := ParseCallToolResult(&raw)
+		if err != nil {
+			return
+		}
+
+		// A parsed result parses to the same result once marshaled.
+		marshaled, err := json.Marshal(result)
+		if err != nil {
+			t.Fatalf("marshaling parsed result: %v", err)
+		}
+		raw = marshaled
+		reparsed, err := ParseCallToolResult(&raw)
+		if err != nil {
+			t.Fatalf("parsing marshaled result %s: %v", marshaled, err)
+		}
+		remarshaled, err := json.Marshal(reparsed)
+		if err != nil {
+			t.Fatalf("marshaling reparsed result: %v", err)
+		}
+		if !bytes.Equal(marshaled, remarshaled) {
+			t.Fatalf("round trip is not stable:\n%s\n%s", marshaled, remarshaled)
+		}
+	})
+}
+
+func FuzzParseReadResourceResult(f *testing.F) {
+	f.Add([]byte(`{"contents":[]}`))
+	f.Add([]byte(`{"contents":[{"uri":"file:///a.txt","mimeType":"text/plain","text":"contents"}],"_meta":{"k":"v"}}`))
+	f.Add([]byte(`{"contents":[{"uri":"file:///a.bin","blob":"aGVsbG8="}]}`))
+	f.Add([]byte(`{"contents":[{"uri":"file:///a.txt","_meta":"not an object","text":"x"}]}`))
+	f.Add([]byte(`{}`))
+	f.Add([]byte(`{"contents": "not an array"}`))
+	f.Add([]byte(`{"contents": [123]}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		raw := json.RawMessage(data)
+		result, err := ParseReadResourceResult(&raw)
+		if err != nil {
+			return
+		}
+
+		marshaled, err := json.Marshal(result)
+		if err != nil {
+			t.Fatalf("marshaling parsed result: %v", err)
+		}
+		raw = marshaled
+		reparsed, err := ParseReadResourceResult(&raw)
+		if err != nil {
+			t.Fatalf("parsing marshaled result %s: %v", marshaled, err)
+		}
+		remarshaled, err := json.Marshal(reparsed)
+		if err != nil {
+			t.Fatalf("marshaling reparsed result: %v", err)
+		}
+		if !bytes.Equal(marshaled, remarshaled) {
+			t.Fatalf("round trip is not stable:\n%s\n%s", marshaled, remarshaled)
+		}
+	})
+}
+
+func FuzzRequestIdUnmarshalJSON(f *testing.F) {
+	for _, seed := range []string{`1`, `0`, `-1`, `1.5`, `1e3`, `"abc"`, `""`, `null`, `true`, `{}`, `[]`, `9007199254740993`} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var id RequestId
+		if id.UnmarshalJSON(data) != nil {
+			return
+		}
+
+		marshaled, err := json.Marshal(id)
+		if err != nil {
+			t.Fatalf("marshaling %s: %v", id, err)
+		}
+		var reparsed RequestId
+		if err := json.Unmarshal(marshaled, &reparsed); err != nil {
+			t.Fatalf("unmarshaling marshaled ID %s: %v", marshaled, err)
+		}
+		if reparsed.String() != id.String() {
+			t.Fatalf("round trip changed ID from %s to %s", id, reparsed)
+		}
+	})
+}
+
+func FuzzCallToolResultUnmarshalJSON(f *testing.F) {
+	f.Add([]byte(`{"content":[{"type":"text","text":"ok"}]}`))
+	f.Add([]byte(`{"content":[{"type":"image","data":"aGVsbG8=","mimeType":"image/png"}],"isError":true}`))
+	f.Add([]byte(`{"content":[],"structuredContent":{"result":42},"_meta":{"progressToken":"p"}}`))
+	f.Add([]byte(`{"content":[{"type":"resource","resource":{"uri":"file:///a.txt","text":"x"}}]}`))
+	f.Add([]byte(`{"content":"not an array","isError":"yes"}`))
+	f.Add([]byte(`{"content":[{"text":"missing type"}]}`))
+	for _, seed := range contentSeeds {
+		f.Add([]byte(`{"content":[` + seed + `]}`))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var result CallToolResult
+		if json.Unmarshal(data, &result) != nil {
+			return
+		}
+
+		marshaled, err := json.Marshal(result)
+		if err != nil {
+			t.Fatalf("marshaling unmarshaled result: %v", err)
+		}
+		var reparsed CallToolResult
+		if err := json.Unmarshal(marshaled, &reparsed); err != nil {
+			t.Fatalf("unmarshaling marshaled result %s: %v", marshaled, err)
+		}
+		remarshaled, err := json.Marshal(reparsed)
+		if err != nil {
+			t.Fatalf("marshaling reparsed result: %v", err)
+		}
+		if !bytes.Equal(marshaled, remarshaled) {
+			t.Fatalf("round trip is not stable:\n%s\n%s", marshaled, remarshaled)
+		}
+	})
+}
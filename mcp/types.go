@@ -35,6 +35,14 @@ const (
 	// https://modelcontextprotocol.io/specification/2024-11-05/server/resources/
 	MethodResourcesRead MCPMethod = "resources/read"
 
+	// MethodResourcesSubscribe requests notifications when a specific resource changes.
+	// https://modelcontextprotocol.io/specification/2025-06-18/server/resources#subscriptions
+	MethodResourcesSubscribe MCPMethod = "resources/subscribe"
+
+	// MethodResourcesUnsubscribe cancels a previous resources/subscribe request.
+	// https://modelcontextprotocol.io/specification/2025-06-18/server/resources#subscriptions
+	MethodResourcesUnsubscribe MCPMethod = "resources/unsubscribe"
+
 	// MethodPromptsList lists all available prompt templates.
 	// https://modelcontextprotocol.io/specification/2024-11-05/server/prompts/
 	MethodPromptsList MCPMethod = "prompts/list"
@@ -520,12 +528,56 @@ type ClientCapabilities struct {
 	} `json:"roots,omitempty"`
 	// Present if the client supports sampling from an LLM.
 	Sampling *struct{} `json:"sampling,omitempty"`
+	// Present if the client supports tools and toolChoice in sampling
+	// requests. It is sent as sampling.tools and implies Sampling.
+	SamplingTools *struct{} `json:"-"`
 	// Present if the client supports elicitation requests from the server.
 	Elicitation *ElicitationCapability `json:"elicitation,omitempty"`
 	// Present if the client supports task-based execution.
 	Tasks *TasksCapability `json:"tasks,omitempty"`
 }
 
+// samplingCapability is the JSON form of the sampling capabilities of a
+// client.
+type samplingCapability struct {
+	Tools *struct{} `json:"tools,omitempty"`
+}
+
+func (c ClientCapabilities) MarshalJSON() ([]byte, error) {
+	// Use a temporary type to avoid infinite recursion on MarshalJSON
+	type Alias ClientCapabilities
+	aux := struct {
+		Alias
+		Sampling *samplingCapability `json:"sampling,omitempty"`
+	}{
+		Alias: Alias(c),
+	}
+	if c.Sampling != nil || c.SamplingTools != nil {
+		aux.Sampling = &samplingCapability{Tools: c.SamplingTools}
+	}
+	return json.Marshal(aux)
+}
+
+func (c *ClientCapabilities) UnmarshalJSON(data []byte) error {
+	// Use a temporary type to avoid infinite recursion on UnmarshalJSON
+	type Alias ClientCapabilities
+	aux := &struct {
+		*Alias
+		Sampling *samplingCapability `json:"sampling,omitempty"`
+	}{
+		Alias: (*Alias)(c),
+	}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	c.Sampling, c.SamplingTools = nil, nil
+	if aux.Sampling != nil {
+		c.Sampling = &struct{}{}
+		c.SamplingTools = aux.Sampling.Tools
+	}
+	return nil
+}
+
 // ServerCapabilities represents capabilities that a server may support. Known
 // capabilities are defined here, in this schema, but this is not a closed set: any
 // server can define its own, additional capabilities.
@@ -638,6 +690,27 @@ type PaginatedParams struct {
 	// An opaque token representing the current pagination position.
 	// If provided, the server should return results starting after this cursor.
 	Cursor Cursor `json:"cursor,omitempty"`
+	// Meta carries request metadata, such as the PageSizeMetaKey hint.
+	Meta *Meta `json:"_meta,omitempty"`
+}
+
+// PageSizeMetaKey is the metadata key a client can set in the _meta field of a
+// list request to ask for smaller pages. Servers treat it as a hint: it never
+// raises the server's own pagination limit.
+const PageSizeMetaKey = "mcp-go/page-size"
+
+// WithPageSize returns a Meta carrying a page size hint for list requests.
+//
+// Example:
+//
+//	request := mcp.ListToolsRequest{}
+//	request.Params.Meta = mcp.WithPageSize(20)
+func WithPageSize(size int) *Meta {
+	return &Meta{
+		AdditionalFields: map[string]any{
+			PageSizeMetaKey: size,
+		},
+	}
 }
 
 type PaginatedResult struct {
@@ -1014,6 +1087,8 @@ type CreateMessageRequest struct {
 }
 
 type CreateMessageParams struct {
+	// Meta is a metadata object that is reserved by MCP for storing additional information.
+	Meta             *Meta             `json:"_meta,omitempty"`
 	Messages         []SamplingMessage `json:"messages"`
 	ModelPreferences *ModelPreferences `json:"modelPreferences,omitempty"`
 	SystemPrompt     string            `json:"systemPrompt,omitempty"`
@@ -1022,8 +1097,41 @@ type CreateMessageParams struct {
 	MaxTokens        int               `json:"maxTokens"`
 	StopSequences    []string          `json:"stopSequences,omitempty"`
 	Metadata         any               `json:"metadata,omitempty"`
+	// Tools the model may call. Requires the client's sampling.tools
+	// capability, see ClientCapabilities.SamplingTools.
+	Tools []Tool `json:"tools,omitempty"`
+	// Controls how the model uses Tools.
+	ToolChoice *ToolChoice `json:"toolChoice,omitempty"`
+}
+
+// ToolChoiceMode controls whether the model calls tools during sampling.
+type ToolChoiceMode string
+
+const (
+	// ToolChoiceAuto lets the model decide whether to call tools. This is the default.
+	ToolChoiceAuto ToolChoiceMode = "auto"
+	// ToolChoiceRequired makes the model call at least one tool.
+	ToolChoiceRequired ToolChoiceMode = "required"
+	// ToolChoiceNone prevents the model from calling tools.
+	ToolChoiceNone ToolChoiceMode = "none"
+)
+
+// ToolChoice controls how the model uses the tools of a sampling request.
+type ToolChoice struct {
+	Mode ToolChoiceMode `json:"mode,omitempty"`
 }
 
+// Reasons why sampling stopped, reported in CreateMessageResult.StopReason.
+// Clients may report other reasons as well.
+const (
+	StopReasonEndTurn      = "endTurn"
+	StopReasonStopSequence = "stopSequence"
+	StopReasonMaxTokens    = "maxTokens"
+	// StopReasonToolUse means the model stopped to call the tools in its
+	// ToolUseContent.
+	StopReasonToolUse = "toolUse"
+)
+
 // CreateMessageResult is the client's response to a sampling/create_message
 // request from the server. The client should inform the user before returning the
 // sampled message, to allow them to inspect the response (human in the loop) and
@@ -1039,8 +1147,10 @@ type CreateMessageResult struct {
 
 // SamplingMessage describes a message issued to or received from an LLM API.
 type SamplingMessage struct {
-	Role    Role `json:"role"`
-	Content any  `json:"content"` // Can be TextContent, ImageContent or AudioContent
+	Role Role `json:"role"`
+	// Can be TextContent, ImageContent, AudioContent, ToolUseContent or
+	// ToolResultContent, or a []Content holding several of them.
+	Content any `json:"content"`
 }
 
 type Annotations struct {
@@ -1145,6 +1255,62 @@ type EmbeddedResource struct {
 
 func (EmbeddedResource) isContent() {}
 
+// ToolUseContent is a request from the model to call a tool, returned by a
+// client in a sampling result.
+// It must have Type set to "tool_use".
+type ToolUseContent struct {
+	// Meta is a metadata object that is reserved by MCP for storing additional information.
+	Meta *Meta  `json:"_meta,omitempty"`
+	Type string `json:"type"`
+	// A unique identifier of this tool use, referenced by the ToolResultContent
+	// that answers it.
+	ID string `json:"id"`
+	// The name of the tool to call.
+	Name string `json:"name"`
+	// The arguments to call the tool with.
+	Input map[string]any `json:"input"`
+}
+
+func (ToolUseContent) isContent() {}
+
+// ToolResultContent is the result of a tool use, sent back to the model in
+// a later sampling request.
+// It must have Type set to "tool_result".
+type ToolResultContent struct {
+	// Meta is a metadata object that is reserved by MCP for storing additional information.
+	Meta *Meta  `json:"_meta,omitempty"`
+	Type string `json:"type"`
+	// The ID of the ToolUseContent this result answers.
+	ToolUseID string `json:"toolUseId"`
+	// The unstructured result of the tool call.
+	Content []Content `json:"content"`
+	// The structured result of the tool call, if any.
+	StructuredContent any `json:"structuredContent,omitempty"`
+	// Whether the tool call ended in an error.
+	IsError bool `json:"isError,omitempty"`
+}
+
+func (ToolResultContent) isContent() {}
+
+// UnmarshalJSON implements json.Unmarshaler, parsing Content into its
+// concrete types.
+func (c *ToolResultContent) UnmarshalJSON(data []byte) error {
+	var contentMap map[string]any
+	if err := json.Unmarshal(data, &contentMap); err != nil {
+		return err
+	}
+	parsed, err := ParseContent(contentMap)
+	if err != nil {
+		return err
+	}
+	result, ok := parsed.(ToolResultContent)
+	if !ok {
+		return fmt.Errorf("expected tool_result content, got %T", parsed)
+	}
+	*c = result
+	return nil
+}
+
 // ModelPreferences represents the server's preferences for model selection,
 // requested of the client during sampling.
 //
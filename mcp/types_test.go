@@ -455,3 +455,55 @@ func TestCompleteParamsUnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestClientCapabilitiesSamplingMarshalling(t *testing.T) {
+	tests := []struct {
+		name         string
+		json         string
+		capabilities ClientCapabilities
+		expected     ClientCapabilities
+	}{
+		{
+			name:         "no sampling",
+			json:         `{}`,
+			capabilities: ClientCapabilities{},
+			expected:     ClientCapabilities{},
+		},
+		{
+			name:         "sampling",
+			json:         `{"sampling":{}}`,
+			capabilities: ClientCapabilities{Sampling: &struct{}{}},
+			expected:     ClientCapabilities{Sampling: &struct{}{}},
+		},
+		{
+			name:         "sampling with tools",
+			json:         `{"sampling":{"tools":{}}}`,
+			capabilities: ClientCapabilities{Sampling: &struct{}{}, SamplingTools: &struct{}{}},
+			expected:     ClientCapabilities{Sampling: &struct{}{}, SamplingTools: &struct{}{}},
+		},
+		{
+			name:         "tools imply sampling",
+			json:         `{"sampling":{"tools":{}}}`,
+			capabilities: ClientCapabilities{SamplingTools: &struct{}{}},
+			expected:     ClientCapabilities{Sampling: &struct{}{}, SamplingTools: &struct{}{}},
+		},
+		{
+			name:         "other capabilities are kept",
+			json:         `{"elicitation":{},"sampling":{}}`,
+			capabilities: ClientCapabilities{Sampling: &struct{}{}, Elicitation: &ElicitationCapability{}},
+			expected:     ClientCapabilities{Sampling: &struct{}{}, Elicitation: &ElicitationCapability{}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.capabilities)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.json, string(data))
+
+			var capabilities ClientCapabilities
+			require.NoError(t, json.Unmarshal([]byte(tc.json), &capabilities))
+			assert.Equal(t, tc.expected, capabilities)
+		})
+	}
+}
@@ -267,6 +267,33 @@ func NewEmbeddedResource(resource ResourceContents) EmbeddedResource {
 	}
 }
 
+// NewToolUseContent creates a new ToolUseContent. A nil input is sent as an
+// empty object.
+func NewToolUseContent(id, name string, input map[string]any) ToolUseContent {
+	if input == nil {
+		input = map[string]any{}
+	}
+	return ToolUseContent{
+		Type:  ContentTypeToolUse,
+		ID:    id,
+		Name:  name,
+		Input: input,
+	}
+}
+
+// NewToolResultContent creates a new ToolResultContent answering the tool use
+// with the given ID.
+func NewToolResultContent(toolUseID string, content ...Content) ToolResultContent {
+	if content == nil {
+		content = []Content{}
+	}
+	return ToolResultContent{
+		Type:      ContentTypeToolResult,
+		ToolUseID: toolUseID,
+		Content:   content,
+	}
+}
+
 // NewToolResultText creates a new CallToolResult with a text content
 func NewToolResultText(text string) *CallToolResult {
 	return &CallToolResult{
@@ -642,11 +669,72 @@ func ParseContent(contentMap map[string]any) (Content, error) {
 		c := NewEmbeddedResource(resourceContents)
 		c.Annotations = annotations
 		return c, nil
+
+	case ContentTypeToolUse:
+		id := ExtractString(contentMap, "id")
+		name := ExtractString(contentMap, "name")
+		if id == "" || name == "" {
+			return nil, fmt.Errorf("tool_use id or name is missing")
+		}
+		c := NewToolUseContent(id, name, ExtractMap(contentMap, "input"))
+		if metaMap := ExtractMap(contentMap, "_meta"); metaMap != nil {
+			c.Meta = NewMetaFromMap(metaMap)
+		}
+		return c, nil
+
+	case ContentTypeToolResult:
+		toolUseID := ExtractString(contentMap, "toolUseId")
+		if toolUseID == "" {
+			return nil, fmt.Errorf("tool_result toolUseId is missing")
+		}
+		blocks, err := ParseSamplingContent(contentMap["content"])
+		if err != nil {
+			return nil, err
+		}
+		var content []Content
+		switch b := blocks.(type) {
+		case []Content:
+			content = b
+		case Content:
+			content = []Content{b}
+		}
+		c := NewToolResultContent(toolUseID, content...)
+		c.StructuredContent = contentMap["structuredContent"]
+		c.IsError, _ = contentMap["isError"].(bool)
+		if metaMap := ExtractMap(contentMap, "_meta"); metaMap != nil {
+			c.Meta = NewMetaFromMap(metaMap)
+		}
+		return c, nil
 	}
 
 	return nil, fmt.Errorf("unsupported content type: %s", contentType)
 }
 
+// ParseSamplingContent converts the content of a SamplingMessage decoded from
+// JSON, either a single content object or an array of them, into Content or
+// []Content. Content that is already typed is returned unchanged.
+func ParseSamplingContent(content any) (any, error) {
+	switch c := content.(type) {
+	case map[string]any:
+		return ParseContent(c)
+	case []any:
+		blocks := make([]Content, 0, len(c))
+		for i, item := range c {
+			contentMap, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("content block %d is not an object", i)
+			}
+			block, err := ParseContent(contentMap)
+			if err != nil {
+				return nil, fmt.Errorf("content block %d: %w", i, err)
+			}
+			blocks = append(blocks, block)
+		}
+		return blocks, nil
+	}
+	return content, nil
+}
+
 func ParseGetPromptResult(rawMessage *json.RawMessage) (*GetPromptResult, error) {
 	if rawMessage == nil {
 		return nil, fmt.Errorf("response is nil")
@@ -838,6 +926,8 @@ func ParseReadResourceResult(rawMessage *json.RawMessage) (*ReadResourceResult,
 		return nil, fmt.Errorf("contents is not an array")
 	}
 
+	// Contents is required, so an empty list must not marshal as null.
+	result.Contents = make([]ResourceContents, 0, len(contentArr))
 	for _, content := range contentArr {
 		// Extract content
 		contentMap, ok := content.(map[string]any)
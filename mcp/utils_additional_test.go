@@ -360,6 +360,17 @@ func TestParseReadResourceResult_Errors(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "not an object")
 	})
+
+	// Found by FuzzParseReadResourceResult.
+	t.Run("empty contents round trip", func(t *testing.T) {
+		raw := json.RawMessage(`{"contents": []}`)
+		result, err := ParseReadResourceResult(&raw)
+		require.NoError(t, err)
+
+		marshaled, err := json.Marshal(result)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"contents": []}`, string(marshaled))
+	})
 }
 
 // Test ParseStringMap
@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -430,3 +431,30 @@ func TestNewJSONRPCResponse(t *testing.T) {
 
 	require.Equal(t, want, got)
 }
+
+func TestParseSamplingContent_ToolUse(t *testing.T) {
+	message := SamplingMessage{
+		Role: RoleUser,
+		Content: []Content{
+			NewToolUseContent("call-1", "weather", map[string]any{"city": "Paris"}),
+			NewToolResultContent("call-1", NewTextContent("sunny")),
+		},
+	}
+	data, err := json.Marshal(message)
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	content, err := ParseSamplingContent(decoded["content"])
+	require.NoError(t, err)
+	assert.Equal(t, message.Content, content)
+
+	single, err := ParseSamplingContent(map[string]any{
+		"type": "tool_use", "id": "call-2", "name": "weather",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, NewToolUseContent("call-2", "weather", nil), single)
+
+	_, err = ParseSamplingContent(map[string]any{"type": "tool_use", "name": "weather"})
+	assert.Error(t, err)
+}
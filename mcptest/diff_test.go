@@ -0,0 +1,45 @@
+package mcptest
+
+import "testing"
+
+func TestDiffTranscripts(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		got  string
+		diff string
+	}{
+		{
+			name: "equal",
+			want: "a\nb\n",
+			got:  "a\nb\n",
+			diff: "",
+		},
+		{
+			name: "changed line",
+			want: "a\nb\nc\n",
+			got:  "a\nx\nc\n",
+			diff: "--- want\n+++ got\n a\n-b\n+x\n c\n",
+		},
+		{
+			name: "added and removed lines",
+			want: "a\nb\n",
+			got:  "b\nc\n",
+			diff: "--- want\n+++ got\n-a\n b\n+c\n",
+		},
+		{
+			name: "distant changes are shown with their context only",
+			want: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			got:  "0\n2\n3\n4\n5\n6\n7\n8\n9\n11\n",
+			diff: "--- want\n+++ got\n-1\n+0\n 2\n 3\n 4\n...\n 7\n 8\n 9\n-10\n+11\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := diffTranscripts(tt.want, tt.got); diff != tt.diff {
+				t.Errorf("diffTranscripts() =\n%s\nwant:\n%s", diff, tt.diff)
+			}
+		})
+	}
+}
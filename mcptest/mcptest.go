@@ -4,9 +4,11 @@ package mcptest
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net/http/httptest"
 	"sync"
 	"testing"
 
@@ -16,6 +18,10 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// ErrClientHandlersUnsupported is returned by Start when client handlers are
+// configured for a transport that cannot carry server-to-client requests.
+var ErrClientHandlersUnsupported = errors.New("mcptest: transport does not support client handlers")
+
 // Server encapsulates an MCP server and manages resources like pipes and context.
 type Server struct {
 	name string
@@ -26,15 +32,30 @@ type Server struct {
 	resourceTemplates []server.ServerResourceTemplate
 	clientInfo        mcp.Implementation
 
+	transportKind     Transport
+	serverOptions     []server.ServerOption
+	sseOptions        []server.SSEOption
+	httpOptions       []server.StreamableHTTPOption
+	clientOptions     []client.ClientOption
+	sampling          *recorder[mcp.CreateMessageRequest, *mcp.CreateMessageResult]
+	elicitation       *recorder[mcp.ElicitationRequest, *mcp.ElicitationResult]
+	roots             *recorder[mcp.ListRootsRequest, *mcp.ListRootsResult]
+	notificationsMu   sync.Mutex
+	notifications     []mcp.JSONRPCNotification
+	notificationsCond *sync.Cond
+	transcript        *Transcript
+
 	cancel func()
 
 	serverReader *io.PipeReader
 	serverWriter *io.PipeWriter
 	clientReader *io.PipeReader
 	clientWriter *io.PipeWriter
+	httpServer   *httptest.Server
 
 	logBuffer bytes.Buffer
 
+	mcpServer *server.MCPServer
 	transport transport.Interface
 	client    *client.Client
 
@@ -56,14 +77,17 @@ func NewServer(t *testing.T, tools ...server.ServerTool) (*Server, error) {
 
 // NewUnstartedServer creates a new MCP server instance with the given name, but does not start the server.
 // Useful for tests where you need to add tools before starting the server.
-func NewUnstartedServer(t *testing.T) *Server {
+// By default the server is served over stdio pipes; use WithTransport to choose another transport.
+func NewUnstartedServer(t *testing.T, opts ...Option) *Server {
 	server := &Server{
-		name: t.Name(),
+		name:          t.Name(),
+		transportKind: TransportStdio,
 	}
+	server.notificationsCond = sync.NewCond(&server.notificationsMu)
 
-	// Set up pipes for client-server communication
-	server.serverReader, server.clientWriter = io.Pipe()
-	server.clientReader, server.serverWriter = io.Pipe()
+	for _, opt := range opts {
+		opt(server)
+	}
 
 	// Return the configured server
 	return server
@@ -129,37 +153,72 @@ func (s *Server) SetClientInfo(info mcp.Implementation) {
 // Start starts the server in a goroutine. Make sure to defer Close() after Start().
 // When using NewServer(), the returned server is already started.
 func (s *Server) Start(ctx context.Context) error {
-	s.wg.Add(1)
+	if s.transportKind == TransportSSE && s.hasClientHandlers() {
+		return fmt.Errorf("%w: %s", ErrClientHandlersUnsupported, s.transportKind)
+	}
+	if s.transcript != nil && s.transportKind != TransportStdio {
+		return fmt.Errorf("%w: %s", ErrRecordingUnsupported, s.transportKind)
+	}
 
 	ctx, s.cancel = context.WithCancel(ctx)
 
-	// Start the MCP server in a goroutine
-	go func() {
-		defer s.wg.Done()
-
-		mcpServer := server.NewMCPServer(s.name, "1.0.0")
-
-		mcpServer.AddTools(s.tools...)
-		mcpServer.AddPrompts(s.prompts...)
-		mcpServer.AddResources(s.resources...)
-		mcpServer.AddResourceTemplates(s.resourceTemplates...)
+	serverOptions := append([]server.ServerOption(nil), s.serverOptions...)
+	if s.elicitation != nil {
+		serverOptions = append(serverOptions, server.WithElicitation())
+	}
+	if s.roots != nil {
+		serverOptions = append(serverOptions, server.WithRoots())
+	}
+	s.mcpServer = server.NewMCPServer(s.name, "1.0.0", serverOptions...)
+	if s.sampling != nil {
+		s.mcpServer.EnableSampling()
+	}
 
-		logger := log.New(&s.logBuffer, "", 0)
+	s.mcpServer.AddTools(s.tools...)
+	s.mcpServer.AddPrompts(s.prompts...)
+	s.mcpServer.AddResources(s.resources...)
+	s.mcpServer.AddResourceTemplates(s.resourceTemplates...)
 
-		stdioServer := server.NewStdioServer(mcpServer)
-		stdioServer.SetErrorLogger(logger)
+	clientOptions := s.clientOptions
+	if s.sampling != nil {
+		clientOptions = append(clientOptions, client.WithSamplingHandler(s.sampling))
+	}
+	if s.elicitation != nil {
+		clientOptions = append(clientOptions, client.WithElicitationHandler(s.elicitation))
+	}
+	if s.roots != nil {
+		clientOptions = append(clientOptions, client.WithRootsHandler(s.roots))
+	}
 
-		if err := stdioServer.Listen(ctx, s.serverReader, s.serverWriter); err != nil {
-			logger.Println("StdioServer.Listen failed:", err)
+	var err error
+	switch s.transportKind {
+	case TransportStdio:
+		s.transport = s.startStdio(ctx)
+	case TransportInProcess:
+		s.transport = s.startInProcess()
+	case TransportSSE:
+		s.httpServer = server.NewTestServer(s.mcpServer, s.sseOptions...)
+		s.transport, err = transport.NewSSE(s.httpServer.URL + "/sse")
+	case TransportStreamableHTTP:
+		s.httpServer = server.NewTestStreamableHTTPServer(s.mcpServer, s.httpOptions...)
+		var options []transport.StreamableHTTPCOption
+		if s.hasClientHandlers() {
+			// Server-to-client requests are delivered on the standalone SSE stream.
+			options = append(options, transport.WithContinuousListening())
 		}
-	}()
-
-	s.transport = transport.NewIO(s.clientReader, s.clientWriter, io.NopCloser(&s.logBuffer))
-	if err := s.transport.Start(ctx); err != nil {
-		return fmt.Errorf("transport.Start(): %w", err)
+		s.transport, err = transport.NewStreamableHTTP(s.httpServer.URL, options...)
+	default:
+		err = fmt.Errorf("mcptest: unknown transport %d", s.transportKind)
+	}
+	if err != nil {
+		return fmt.Errorf("transport: %w", err)
 	}
 
-	s.client = client.NewClient(s.transport)
+	s.client = client.NewClient(s.transport, clientOptions...)
+	s.client.OnNotification(s.recordNotification)
+	if err := s.client.Start(ctx); err != nil {
+		return fmt.Errorf("client.Start(): %w", err)
+	}
 
 	var initReq mcp.InitializeRequest
 	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
@@ -171,6 +230,56 @@ func (s *Server) Start(ctx context.Context) error {
 	return nil
 }
 
+// startStdio serves the MCP server over in-memory pipes and returns the client side.
+func (s *Server) startStdio(ctx context.Context) transport.Interface {
+	// Set up pipes for client-server communication
+	s.serverReader, s.clientWriter = io.Pipe()
+	s.clientReader, s.serverWriter = io.Pipe()
+
+	var serverWriter, clientWriter io.WriteCloser = s.serverWriter, s.clientWriter
+	if s.transcript != nil {
+		serverWriter = &recordingWriter{w: serverWriter, transcript: s.transcript, from: SenderServer}
+		clientWriter = &recordingWriter{w: clientWriter, transcript: s.transcript, from: SenderClient}
+	}
+
+	logger := log.New(&s.logBuffer, "", 0)
+
+	stdioServer := server.NewStdioServer(s.mcpServer)
+	stdioServer.SetErrorLogger(logger)
+
+	// Start the MCP server in a goroutine
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		if err := stdioServer.Listen(ctx, s.serverReader, serverWriter); err != nil {
+			logger.Println("StdioServer.Listen failed:", err)
+		}
+	}()
+
+	return transport.NewIO(s.clientReader, clientWriter, io.NopCloser(&s.logBuffer))
+}
+
+// startInProcess connects to the MCP server in-process. Client handlers are
+// installed on the transport, which calls them directly.
+func (s *Server) startInProcess() transport.Interface {
+	var options []transport.InProcessOption
+	if s.sampling != nil {
+		options = append(options, transport.WithSamplingHandler(s.sampling))
+	}
+	if s.elicitation != nil {
+		options = append(options, transport.WithElicitationHandler(s.elicitation))
+	}
+	if s.roots != nil {
+		options = append(options, transport.WithRootsHandler(s.roots))
+	}
+	return transport.NewInProcessTransportWithOptions(s.mcpServer, options...)
+}
+
+func (s *Server) hasClientHandlers() bool {
+	return s.sampling != nil || s.elicitation != nil || s.roots != nil
+}
+
 // Close stops the server and cleans up resources like temporary directories.
 func (s *Server) Close() {
 	if s.transport != nil {
@@ -184,16 +293,24 @@ func (s *Server) Close() {
 		s.cancel = nil
 	}
 
-	// Wait for server goroutine to finish
-	s.wg.Wait()
+	if s.httpServer != nil {
+		s.httpServer.Close()
+		s.httpServer = nil
+	}
 
-	s.serverWriter.Close()
-	s.serverReader.Close()
-	s.serverReader, s.serverWriter = nil, nil
+	// Close the pipes so a blocked stdio server goroutine returns
+	if s.serverWriter != nil {
+		s.serverWriter.Close()
+		s.serverReader.Close()
+		s.serverReader, s.serverWriter = nil, nil
+
+		s.clientWriter.Close()
+		s.clientReader.Close()
+		s.clientReader, s.clientWriter = nil, nil
+	}
 
-	s.clientWriter.Close()
-	s.clientReader.Close()
-	s.clientReader, s.clientWriter = nil, nil
+	// Wait for server goroutine to finish
+	s.wg.Wait()
 }
 
 // Client returns an MCP client connected to the server.
@@ -201,3 +318,69 @@ func (s *Server) Close() {
 func (s *Server) Client() *client.Client {
 	return s.client
 }
+
+// MCPServer returns the underlying MCP server, e.g. to send notifications or
+// change the registered tools while the test is running. It is nil until the
+// server has been started.
+func (s *Server) MCPServer() *server.MCPServer {
+	return s.mcpServer
+}
+
+// Transport returns the transport the server is served over.
+func (s *Server) Transport() Transport {
+	return s.transportKind
+}
+
+// SamplingRequests returns the sampling requests the client received so far.
+func (s *Server) SamplingRequests() []mcp.CreateMessageRequest {
+	return s.sampling.received()
+}
+
+// ElicitationRequests returns the elicitation requests the client received so far.
+func (s *Server) ElicitationRequests() []mcp.ElicitationRequest {
+	return s.elicitation.received()
+}
+
+// RootsRequests returns the roots list requests the client received so far.
+func (s *Server) RootsRequests() []mcp.ListRootsRequest {
+	return s.roots.received()
+}
+
+// Notifications returns the notifications the client received so far.
+func (s *Server) Notifications() []mcp.JSONRPCNotification {
+	s.notificationsMu.Lock()
+	defer s.notificationsMu.Unlock()
+	return append([]mcp.JSONRPCNotification(nil), s.notifications...)
+}
+
+// WaitForNotification blocks until the client has received a notification
+// with the given method and returns it, or returns an error when ctx is done.
+func (s *Server) WaitForNotification(ctx context.Context, method string) (mcp.JSONRPCNotification, error) {
+	stop := context.AfterFunc(ctx, func() {
+		s.notificationsMu.Lock()
+		defer s.notificationsMu.Unlock()
+		s.notificationsCond.Broadcast()
+	})
+	defer stop()
+
+	s.notificationsMu.Lock()
+	defer s.notificationsMu.Unlock()
+	for {
+		for _, notification := range s.notifications {
+			if notification.Method == method {
+				return notification, nil
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return mcp.JSONRPCNotification{}, fmt.Errorf("waiting for %s: %w", method, err)
+		}
+		s.notificationsCond.Wait()
+	}
+}
+
+func (s *Server) recordNotification(notification mcp.JSONRPCNotification) {
+	s.notificationsMu.Lock()
+	defer s.notificationsMu.Unlock()
+	s.notifications = append(s.notifications, notification)
+	s.notificationsCond.Broadcast()
+}
@@ -2,10 +2,13 @@ package mcptest_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -455,3 +458,208 @@ func TestSimulateClientInfo(t *testing.T) {
 		t.Errorf("Got %q, want %q", got, want)
 	}
 }
+
+func TestParallelServers(t *testing.T) {
+	for i := range 5 {
+		t.Run(fmt.Sprintf("server-%d", i), func(t *testing.T) {
+			t.Parallel()
+
+			name := fmt.Sprintf("client-%d", i)
+			srv, err := mcptest.NewServer(t, server.ServerTool{
+				Tool:    mcp.NewTool("hello", mcp.WithString("name")),
+				Handler: helloWorldHandler,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer srv.Close()
+
+			var req mcp.CallToolRequest
+			req.Params.Name = "hello"
+			req.Params.Arguments = map[string]any{"name": name}
+
+			result, err := srv.Client().CallTool(context.Background(), req)
+			if err != nil {
+				t.Fatal("CallTool:", err)
+			}
+
+			got, err := resultToString(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := fmt.Sprintf("Hello, %s!", name); got != want {
+				t.Errorf("Got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestTransportMatrix(t *testing.T) {
+	mcptest.ForEachTransport(t, func(t *testing.T, tr mcptest.Transport) {
+		ctx := context.Background()
+
+		var calls atomic.Int32
+		hooks := &server.Hooks{}
+		hooks.AddBeforeCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest) {
+			calls.Add(1)
+		})
+
+		srv := mcptest.NewUnstartedServer(t,
+			mcptest.WithTransport(tr),
+			mcptest.WithServerOptions(server.WithHooks(hooks), server.WithLogging()),
+		)
+		defer srv.Close()
+		srv.AddTool(mcp.NewTool("hello", mcp.WithString("name")), helloWorldHandler)
+		if err := srv.Start(ctx); err != nil {
+			t.Fatal("Start:", err)
+		}
+		if srv.Transport() != tr {
+			t.Errorf("Got transport %s, want %s", srv.Transport(), tr)
+		}
+
+		var req mcp.CallToolRequest
+		req.Params.Name = "hello"
+		req.Params.Arguments = map[string]any{"name": tr.String()}
+
+		result, err := srv.Client().CallTool(ctx, req)
+		if err != nil {
+			t.Fatal("CallTool:", err)
+		}
+		got, err := resultToString(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("Hello, %s!", tr); got != want {
+			t.Errorf("Got %q, want %q", got, want)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("Got %d hook calls, want 1", calls.Load())
+		}
+	})
+}
+
+func TestClientHandlers(t *testing.T) {
+	mcptest.ForEachTransport(t, func(t *testing.T, tr mcptest.Transport) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		srv := mcptest.NewUnstartedServer(t,
+			mcptest.WithTransport(tr),
+			mcptest.WithSamplingResponses(&mcp.CreateMessageResult{
+				SamplingMessage: mcp.SamplingMessage{
+					Role:    mcp.RoleAssistant,
+					Content: mcp.NewTextContent("scripted answer"),
+				},
+				Model: "test-model",
+			}),
+			mcptest.WithElicitationResponses(&mcp.ElicitationResult{
+				ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionDecline},
+			}),
+			mcptest.WithRoots(mcp.Root{URI: "file:///workspace", Name: "workspace"}),
+		)
+		defer srv.Close()
+
+		srv.AddTool(mcp.NewTool("ask"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			mcpServer := server.ServerFromContext(ctx)
+
+			var samplingRequest mcp.CreateMessageRequest
+			samplingRequest.Messages = []mcp.SamplingMessage{{Role: mcp.RoleUser, Content: mcp.NewTextContent("question")}}
+			samplingRequest.MaxTokens = 10
+			sampled, err := mcpServer.RequestSampling(ctx, samplingRequest)
+			if err != nil {
+				return nil, fmt.Errorf("sampling: %w", err)
+			}
+
+			var elicitationRequest mcp.ElicitationRequest
+			elicitationRequest.Params.Message = "confirm?"
+			elicitationRequest.Params.RequestedSchema = map[string]any{"type": "object"}
+			elicited, err := mcpServer.RequestElicitation(ctx, elicitationRequest)
+			if err != nil {
+				return nil, fmt.Errorf("elicitation: %w", err)
+			}
+
+			roots, err := mcpServer.RequestRoots(ctx, mcp.ListRootsRequest{})
+			if err != nil {
+				return nil, fmt.Errorf("roots: %w", err)
+			}
+
+			text := sampled.Content.(mcp.TextContent).Text
+			return mcp.NewToolResultText(fmt.Sprintf("%s/%s/%s", text, elicited.Action, roots.Roots[0].Name)), nil
+		})
+
+		err := srv.Start(ctx)
+		if !tr.SupportsClientHandlers() {
+			if !errors.Is(err, mcptest.ErrClientHandlersUnsupported) {
+				t.Fatalf("Got error %v, want ErrClientHandlersUnsupported", err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatal("Start:", err)
+		}
+
+		// The client handlers enable the matching server capabilities.
+		capabilities := srv.Client().GetServerCapabilities()
+		if capabilities.Sampling == nil || capabilities.Elicitation == nil || capabilities.Roots == nil {
+			t.Errorf("Got capabilities %+v, want sampling, elicitation and roots", capabilities)
+		}
+
+		var req mcp.CallToolRequest
+		req.Params.Name = "ask"
+		result, err := srv.Client().CallTool(ctx, req)
+		if err != nil {
+			t.Fatal("CallTool:", err)
+		}
+		got, err := resultToString(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "scripted answer/decline/workspace"; got != want {
+			t.Errorf("Got %q, want %q", got, want)
+		}
+
+		if n := len(srv.SamplingRequests()); n != 1 {
+			t.Errorf("Got %d sampling requests, want 1", n)
+		}
+		if n := len(srv.ElicitationRequests()); n != 1 {
+			t.Errorf("Got %d elicitation requests, want 1", n)
+		}
+		if n := len(srv.RootsRequests()); n != 1 {
+			t.Errorf("Got %d roots requests, want 1", n)
+		}
+	})
+}
+
+func TestNotifications(t *testing.T) {
+	mcptest.ForEachTransport(t, func(t *testing.T, tr mcptest.Transport) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		srv := mcptest.NewUnstartedServer(t, mcptest.WithTransport(tr))
+		defer srv.Close()
+		srv.AddTool(mcp.NewTool("progress"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			err := server.ServerFromContext(ctx).SendNotificationToClient(ctx, "test/progress", map[string]any{"done": true})
+			if err != nil {
+				return nil, err
+			}
+			return mcp.NewToolResultText("ok"), nil
+		})
+		if err := srv.Start(ctx); err != nil {
+			t.Fatal("Start:", err)
+		}
+
+		var req mcp.CallToolRequest
+		req.Params.Name = "progress"
+		if _, err := srv.Client().CallTool(ctx, req); err != nil {
+			t.Fatal("CallTool:", err)
+		}
+
+		notification, err := srv.WaitForNotification(ctx, "test/progress")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done := notification.Params.AdditionalFields["done"]; done != true {
+			t.Errorf("Got done=%v, want true", done)
+		}
+	})
+}
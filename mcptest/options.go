@@ -0,0 +1,238 @@
+package mcptest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Transport selects how the test client is connected to the server.
+type Transport int
+
+const (
+	// TransportStdio serves the server with a StdioServer over in-memory pipes.
+	TransportStdio Transport = iota
+	// TransportInProcess connects the client to the server in-process.
+	TransportInProcess
+	// TransportSSE serves the server with an SSEServer over HTTP.
+	TransportSSE
+	// TransportStreamableHTTP serves the server with a StreamableHTTPServer over HTTP.
+	TransportStreamableHTTP
+)
+
+// Transports lists every supported transport, for table-driven tests.
+var Transports = []Transport{
+	TransportStdio,
+	TransportInProcess,
+	TransportSSE,
+	TransportStreamableHTTP,
+}
+
+func (t Transport) String() string {
+	switch t {
+	case TransportStdio:
+		return "stdio"
+	case TransportInProcess:
+		return "inprocess"
+	case TransportSSE:
+		return "sse"
+	case TransportStreamableHTTP:
+		return "streamable-http"
+	default:
+		return fmt.Sprintf("Transport(%d)", int(t))
+	}
+}
+
+// SupportsClientHandlers reports whether the server can send sampling,
+// elicitation and roots requests to the client over the transport.
+func (t Transport) SupportsClientHandlers() bool {
+	return t != TransportSSE
+}
+
+// ForEachTransport runs fn as a subtest for every transport in Transports.
+//
+//	mcptest.ForEachTransport(t, func(t *testing.T, tr mcptest.Transport) {
+//		srv := mcptest.NewUnstartedServer(t, mcptest.WithTransport(tr))
+//		...
+//	})
+func ForEachTransport(t *testing.T, fn func(t *testing.T, transport Transport)) {
+	t.Helper()
+	for _, transport := range Transports {
+		t.Run(transport.String(), func(t *testing.T) {
+			fn(t, transport)
+		})
+	}
+}
+
+// Option configures a Server created with NewUnstartedServer.
+type Option func(*Server)
+
+// WithTransport selects the transport the server is served over. The default
+// is TransportStdio.
+func WithTransport(transport Transport) Option {
+	return func(s *Server) {
+		s.transportKind = transport
+	}
+}
+
+// WithServerOptions passes options, such as hooks or middleware, to the MCP server.
+func WithServerOptions(opts ...server.ServerOption) Option {
+	return func(s *Server) {
+		s.serverOptions = append(s.serverOptions, opts...)
+	}
+}
+
+// WithSSEOptions passes options to the SSE server used by TransportSSE.
+func WithSSEOptions(opts ...server.SSEOption) Option {
+	return func(s *Server) {
+		s.sseOptions = append(s.sseOptions, opts...)
+	}
+}
+
+// WithStreamableHTTPOptions passes options to the streamable HTTP server used
+// by TransportStreamableHTTP.
+func WithStreamableHTTPOptions(opts ...server.StreamableHTTPOption) Option {
+	return func(s *Server) {
+		s.httpOptions = append(s.httpOptions, opts...)
+	}
+}
+
+// WithClientOptions passes options to the test client. Use WithSamplingHandler,
+// WithElicitationHandler and WithRootsHandler rather than the client options
+// of the same name, so that the handlers are wired up for every transport.
+func WithClientOptions(opts ...client.ClientOption) Option {
+	return func(s *Server) {
+		s.clientOptions = append(s.clientOptions, opts...)
+	}
+}
+
+// WithSamplingHandler installs a sampling handler on the test client and
+// enables sampling on the server.
+func WithSamplingHandler(handler client.SamplingHandler) Option {
+	return func(s *Server) {
+		s.sampling = newRecorder(handler.CreateMessage)
+	}
+}
+
+// WithElicitationHandler installs an elicitation handler on the test client
+// and enables elicitation on the server.
+func WithElicitationHandler(handler client.ElicitationHandler) Option {
+	return func(s *Server) {
+		s.elicitation = newRecorder(handler.Elicit)
+	}
+}
+
+// WithRootsHandler installs a roots handler on the test client and enables
+// roots on the server.
+func WithRootsHandler(handler client.RootsHandler) Option {
+	return func(s *Server) {
+		s.roots = newRecorder(handler.ListRoots)
+	}
+}
+
+// WithSamplingResponses scripts the client's answers to sampling requests.
+// Each request receives the next result in order; once they are exhausted,
+// requests fail.
+func WithSamplingResponses(results ...*mcp.CreateMessageResult) Option {
+	return WithSamplingHandler(SamplingHandlerFunc(scripted[mcp.CreateMessageRequest]("sampling", results)))
+}
+
+// WithElicitationResponses scripts the client's answers to elicitation
+// requests. Each request receives the next result in order; once they are
+// exhausted, requests fail.
+func WithElicitationResponses(results ...*mcp.ElicitationResult) Option {
+	return WithElicitationHandler(ElicitationHandlerFunc(scripted[mcp.ElicitationRequest]("elicitation", results)))
+}
+
+// WithRoots makes the client answer every roots list request with roots.
+func WithRoots(roots ...mcp.Root) Option {
+	return WithRootsHandler(RootsHandlerFunc(func(context.Context, mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
+		return &mcp.ListRootsResult{Roots: roots}, nil
+	}))
+}
+
+// SamplingHandlerFunc adapts a function to client.SamplingHandler.
+type SamplingHandlerFunc func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+
+// CreateMessage calls f(ctx, request).
+func (f SamplingHandlerFunc) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	return f(ctx, request)
+}
+
+// ElicitationHandlerFunc adapts a function to client.ElicitationHandler.
+type ElicitationHandlerFunc func(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error)
+
+// Elicit calls f(ctx, request).
+func (f ElicitationHandlerFunc) Elicit(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	return f(ctx, request)
+}
+
+// RootsHandlerFunc adapts a function to client.RootsHandler.
+type RootsHandlerFunc func(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error)
+
+// ListRoots calls f(ctx, request).
+func (f RootsHandlerFunc) ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
+	return f(ctx, request)
+}
+
+// scripted returns a handler function that answers with results in order.
+func scripted[Req, Res any](kind string, results []Res) func(context.Context, Req) (Res, error) {
+	var mu sync.Mutex
+	next := 0
+	return func(context.Context, Req) (Res, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if next >= len(results) {
+			var zero Res
+			return zero, fmt.Errorf("mcptest: no scripted %s response left (%d used)", kind, len(results))
+		}
+		next++
+		return results[next-1], nil
+	}
+}
+
+// recorder wraps a client handler function and records the requests it receives.
+// It implements the client and server handler interfaces for its request type.
+type recorder[Req, Res any] struct {
+	handle func(context.Context, Req) (Res, error)
+
+	mu       sync.Mutex
+	requests []Req
+}
+
+func newRecorder[Req, Res any](handle func(context.Context, Req) (Res, error)) *recorder[Req, Res] {
+	return &recorder[Req, Res]{handle: handle}
+}
+
+func (r *recorder[Req, Res]) call(ctx context.Context, request Req) (Res, error) {
+	r.mu.Lock()
+	r.requests = append(r.requests, request)
+	r.mu.Unlock()
+	return r.handle(ctx, request)
+}
+
+func (r *recorder[Req, Res]) received() []Req {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Req(nil), r.requests...)
+}
+
+func (r *recorder[Req, Res]) CreateMessage(ctx context.Context, request Req) (Res, error) {
+	return r.call(ctx, request)
+}
+
+func (r *recorder[Req, Res]) Elicit(ctx context.Context, request Req) (Res, error) {
+	return r.call(ctx, request)
+}
+
+func (r *recorder[Req, Res]) ListRoots(ctx context.Context, request Req) (Res, error) {
+	return r.call(ctx, request)
+}
@@ -0,0 +1,315 @@
+package mcptest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ReplayOption configures how transcripts are replayed and compared.
+type ReplayOption func(*replayConfig)
+
+type replayConfig struct {
+	fields  []string
+	timeout time.Duration
+}
+
+func newReplayConfig(opts []ReplayOption) *replayConfig {
+	config := &replayConfig{
+		fields:  append([]string(nil), DefaultVolatileFields...),
+		timeout: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// WithVolatileFields normalises the values of the given object keys in
+// addition to DefaultVolatileFields.
+func WithVolatileFields(fields ...string) ReplayOption {
+	return func(c *replayConfig) {
+		c.fields = append(c.fields, fields...)
+	}
+}
+
+// WithReplayTimeout sets how long a replay waits for each expected message.
+// The default is 5 seconds.
+func WithReplayTimeout(timeout time.Duration) ReplayOption {
+	return func(c *replayConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithRecording records the JSON-RPC traffic between the test client and the
+// server. The recording is available from Server.Transcript. Recording is
+// only supported over TransportStdio.
+func WithRecording() Option {
+	return func(s *Server) {
+		s.transcript = NewTranscript()
+	}
+}
+
+// Transcript returns the traffic recorded since the server was started with
+// WithRecording, or nil if recording is disabled.
+func (s *Server) Transcript() *Transcript {
+	return s.transcript
+}
+
+// CompareGolden compares transcript with the golden transcript stored at path
+// and reports a diff of the normalised messages if they differ. When the
+// MCPTEST_UPDATE_GOLDEN environment variable is set, the golden file is
+// written instead.
+func CompareGolden(t testing.TB, path string, transcript *Transcript, opts ...ReplayOption) {
+	t.Helper()
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := transcript.Save(path); err != nil {
+			t.Fatalf("mcptest: writing golden transcript: %v", err)
+		}
+		return
+	}
+
+	golden, err := LoadTranscript(path)
+	if err != nil {
+		t.Fatalf("mcptest: loading golden transcript (set %s=1 to create it): %v", UpdateGoldenEnv, err)
+	}
+
+	config := newReplayConfig(opts)
+	want := renderExchanges(newNormalizer(config.fields), golden.exchanges(SenderClient))
+	got := renderExchanges(newNormalizer(config.fields), transcript.exchanges(SenderClient))
+	if diff := diffTranscripts(want, got); diff != "" {
+		t.Errorf("mcptest: transcript differs from %s (-want +got):\n%s", path, diff)
+	}
+}
+
+// ReplayServer sends the client messages of transcript to mcpServer over
+// stdio and compares the server's responses with the recorded ones. After
+// each client message, it waits for as many server messages as were recorded
+// before the next client message; messages within such an exchange are
+// compared regardless of their order. Differences are reported as a diff of
+// the normalised messages.
+//
+//	transcript, err := mcptest.LoadTranscript("testdata/session.jsonl")
+//	...
+//	mcptest.ReplayServer(t, newServer(), transcript)
+func ReplayServer(t testing.TB, mcpServer *server.MCPServer, transcript *Transcript, opts ...ReplayOption) {
+	t.Helper()
+	config := newReplayConfig(opts)
+
+	serverReader, clientWriter := io.Pipe()
+	clientReader, serverWriter := io.Pipe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		stdioServer := server.NewStdioServer(mcpServer)
+		stdioServer.SetErrorLogger(log.New(testLogWriter{t}, "", 0))
+		_ = stdioServer.Listen(ctx, serverReader, serverWriter)
+	}()
+	defer func() {
+		cancel()
+		clientWriter.Close()
+		clientReader.Close()
+		<-done
+	}()
+
+	received := make(chan json.RawMessage, 16)
+	go readLines(clientReader, received, ctx.Done())
+
+	expected := transcript.exchanges(SenderClient)
+	actual := make([]exchange, 0, len(expected))
+	for _, recorded := range expected {
+		got := exchange{message: recorded.message}
+		if recorded.message.Message != nil {
+			line := append(bytes.Clone(recorded.message.Message), '\n')
+			if _, err := clientWriter.Write(line); err != nil {
+				t.Fatalf("mcptest: writing to server: %v", err)
+			}
+		}
+		timeout := time.After(config.timeout)
+	wait:
+		for len(got.replies) < len(recorded.replies) {
+			select {
+			case message, ok := <-received:
+				if !ok {
+					break wait
+				}
+				got.replies = append(got.replies, TranscriptMessage{From: SenderServer, Message: message})
+			case <-timeout:
+				break wait
+			}
+		}
+		actual = append(actual, got)
+	}
+
+	want := renderExchanges(newNormalizer(config.fields), expected)
+	got := renderExchanges(newNormalizer(config.fields), actual)
+	if diff := diffTranscripts(want, got); diff != "" {
+		t.Errorf("mcptest: server responses differ from transcript (-want +got):\n%s", diff)
+	}
+}
+
+// ClientReplay plays the server half of a transcript to a client. Each
+// message the client sends is compared with the next recorded client message
+// and answered with the server messages recorded after it. Responses are
+// rewritten to carry the ID of the request the client actually sent.
+type ClientReplay struct {
+	t         testing.TB
+	config    *replayConfig
+	exchanges []exchange
+
+	transport    *transport.Stdio
+	clientReader *io.PipeReader
+	clientWriter *io.PipeWriter
+	serverReader *io.PipeReader
+	serverWriter *io.PipeWriter
+	done         chan struct{}
+}
+
+// NewClientReplay creates a replay of the server half of transcript. Connect
+// the client under test to Transport. Close, which is also registered as a
+// test cleanup, reports recorded client messages the client never sent.
+//
+//	replay := mcptest.NewClientReplay(t, transcript)
+//	c := client.NewClient(replay.Transport())
+func NewClientReplay(t testing.TB, transcript *Transcript, opts ...ReplayOption) *ClientReplay {
+	r := &ClientReplay{
+		t:         t,
+		config:    newReplayConfig(opts),
+		exchanges: transcript.exchanges(SenderClient),
+		done:      make(chan struct{}),
+	}
+	r.serverReader, r.clientWriter = io.Pipe()
+	r.clientReader, r.serverWriter = io.Pipe()
+	r.transport = transport.NewIO(r.clientReader, r.clientWriter, io.NopCloser(&bytes.Buffer{}))
+
+	go r.run()
+	t.Cleanup(r.Close)
+	return r
+}
+
+// Transport returns the client transport connected to the replay.
+func (r *ClientReplay) Transport() transport.Interface {
+	return r.transport
+}
+
+// Close stops the replay and reports any recorded client messages that were
+// not received.
+func (r *ClientReplay) Close() {
+	r.serverWriter.Close()
+	r.serverReader.Close()
+	r.clientWriter.Close()
+	r.clientReader.Close()
+	<-r.done
+}
+
+func (r *ClientReplay) run() {
+	defer close(r.done)
+
+	received := make(chan json.RawMessage, 16)
+	stop := make(chan struct{})
+	defer close(stop)
+	go readLines(r.serverReader, received, stop)
+
+	// The recorded and the sent messages are normalised separately, so that
+	// each side's volatile values are numbered in order of appearance.
+	wantNormalizer := newNormalizer(r.config.fields)
+	gotNormalizer := newNormalizer(r.config.fields)
+	for i, recorded := range r.exchanges {
+		if recorded.message.Message != nil {
+			var message json.RawMessage
+			var ok bool
+			select {
+			case message, ok = <-received:
+			case <-time.After(r.config.timeout):
+			}
+			if !ok {
+				r.t.Errorf("mcptest: client did not send recorded message %d:\n%s",
+					i, wantNormalizer.render(recorded.message.Message))
+				return
+			}
+			want := renderExchanges(wantNormalizer, []exchange{{message: recorded.message}})
+			got := renderExchanges(gotNormalizer, []exchange{{message: TranscriptMessage{From: SenderClient, Message: message}}})
+			if diff := diffTranscripts(want, got); diff != "" {
+				r.t.Errorf("mcptest: client message %d differs from transcript (-want +got):\n%s", i, diff)
+			}
+			recorded = rewriteResponseIDs(recorded, message)
+		}
+		for _, reply := range recorded.replies {
+			if _, err := r.serverWriter.Write(append(bytes.Clone(reply.Message), '\n')); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// rewriteResponseIDs replaces the ID of recorded responses to the recorded
+// request with the ID of the request that was actually sent.
+func rewriteResponseIDs(recorded exchange, sent json.RawMessage) exchange {
+	var wantID, gotID struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if json.Unmarshal(recorded.message.Message, &wantID) != nil || json.Unmarshal(sent, &gotID) != nil ||
+		wantID.ID == nil || gotID.ID == nil || bytes.Equal(wantID.ID, gotID.ID) {
+		return recorded
+	}
+
+	replies := make([]TranscriptMessage, len(recorded.replies))
+	for i, reply := range recorded.replies {
+		replies[i] = reply
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(reply.Message, &fields) != nil {
+			continue
+		}
+		_, isRequest := fields["method"]
+		if isRequest || !bytes.Equal(fields["id"], wantID.ID) {
+			continue
+		}
+		fields["id"] = gotID.ID
+		if message, err := json.Marshal(fields); err == nil {
+			replies[i].Message = message
+		}
+	}
+	recorded.replies = replies
+	return recorded
+}
+
+// readLines sends every non-empty line read from r to lines and closes lines
+// when r is exhausted or done is closed.
+func readLines(r io.Reader, lines chan<- json.RawMessage, done <-chan struct{}) {
+	defer close(lines)
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			select {
+			case lines <- json.RawMessage(line):
+			case <-done:
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// testLogWriter writes log output to the test log.
+type testLogWriter struct {
+	t testing.TB
+}
+
+func (w testLogWriter) Write(p []byte) (int, error) {
+	w.t.Log(string(bytes.TrimRight(p, "\n")))
+	return len(p), nil
+}
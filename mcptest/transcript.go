@@ -0,0 +1,394 @@
+package mcptest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// UpdateGoldenEnv is the environment variable that makes CompareGolden
+// rewrite golden files instead of comparing against them.
+const UpdateGoldenEnv = "MCPTEST_UPDATE_GOLDEN"
+
+// DefaultVolatileFields are the object keys whose values are normalised
+// before transcripts are compared, as they differ from run to run.
+var DefaultVolatileFields = []string{
+	"sessionId",
+	"taskId",
+	"elicitationId",
+	"createdAt",
+	"lastUpdatedAt",
+	"timestamp",
+}
+
+// ErrRecordingUnsupported is returned by Start when recording is enabled for
+// a transport other than TransportStdio.
+var ErrRecordingUnsupported = errors.New("mcptest: recording is only supported over stdio")
+
+// Sender identifies the side of a session that sent a message.
+type Sender string
+
+const (
+	SenderClient Sender = "client"
+	SenderServer Sender = "server"
+)
+
+// TranscriptMessage is a single JSON-RPC message of a transcript.
+type TranscriptMessage struct {
+	From    Sender          `json:"from"`
+	Message json.RawMessage `json:"message"`
+}
+
+// Transcript is the JSON-RPC traffic of a session in the order it was
+// observed. Transcripts are stored as JSON Lines, one TranscriptMessage per
+// line, which keeps golden files easy to review.
+type Transcript struct {
+	mu       sync.Mutex
+	messages []TranscriptMessage
+}
+
+// NewTranscript creates a transcript holding messages.
+func NewTranscript(messages ...TranscriptMessage) *Transcript {
+	return &Transcript{messages: messages}
+}
+
+// Messages returns a copy of the messages of the transcript.
+func (t *Transcript) Messages() []TranscriptMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TranscriptMessage(nil), t.messages...)
+}
+
+// Append adds a message to the transcript.
+func (t *Transcript) Append(from Sender, message json.RawMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = append(t.messages, TranscriptMessage{From: from, Message: bytes.Clone(message)})
+}
+
+// WriteTo writes the transcript to w as JSON Lines.
+func (t *Transcript) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	for _, message := range t.Messages() {
+		line, err := json.Marshal(message)
+		if err != nil {
+			return 0, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.WriteTo(w)
+}
+
+// Save writes the transcript to the file at path.
+func (t *Transcript) Save(path string) error {
+	var buf bytes.Buffer
+	if _, err := t.WriteTo(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// ReadTranscript reads a transcript written by Transcript.WriteTo.
+func ReadTranscript(r io.Reader) (*Transcript, error) {
+	transcript := &Transcript{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var message TranscriptMessage
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if message.From != SenderClient && message.From != SenderServer {
+			return nil, fmt.Errorf("line %d: unknown sender %q", line, message.From)
+		}
+		transcript.messages = append(transcript.messages, message)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return transcript, nil
+}
+
+// LoadTranscript reads the transcript stored in the file at path.
+func LoadTranscript(path string) (*Transcript, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadTranscript(f)
+}
+
+// exchange is a message sent by one side, followed by the messages the other
+// side sent in reply before the next message of the first side.
+type exchange struct {
+	message TranscriptMessage
+	replies []TranscriptMessage
+}
+
+// exchanges splits the transcript into the messages sent by from and the
+// replies that follow each of them. Messages of the other side that precede
+// the first message of from are returned as the replies of a leading
+// exchange with an empty message.
+func (t *Transcript) exchanges(from Sender) []exchange {
+	var result []exchange
+	for _, message := range t.Messages() {
+		if message.From == from {
+			result = append(result, exchange{message: message})
+			continue
+		}
+		if len(result) == 0 {
+			result = append(result, exchange{})
+		}
+		result[len(result)-1].replies = append(result[len(result)-1].replies, message)
+	}
+	return result
+}
+
+// normalizer replaces the values of volatile fields with stable placeholders.
+// The same value is always replaced by the same placeholder, so references
+// between messages, such as a task ID returned by one call and passed to the
+// next, are preserved.
+type normalizer struct {
+	fields       map[string]bool
+	placeholders map[string]string
+	counts       map[string]int
+}
+
+func newNormalizer(fields []string) *normalizer {
+	n := &normalizer{
+		fields:       make(map[string]bool, len(fields)),
+		placeholders: make(map[string]string),
+		counts:       make(map[string]int),
+	}
+	for _, field := range fields {
+		n.fields[field] = true
+	}
+	return n
+}
+
+// render returns the normalised, indented form of a message.
+func (n *normalizer) render(message json.RawMessage) string {
+	return n.renderWith(message, n.placeholder)
+}
+
+// sortKey returns the indented form of a message with the values of the
+// volatile fields masked. It does not assign placeholders, so it can be used
+// to order messages before rendering them.
+func (n *normalizer) sortKey(message json.RawMessage) string {
+	return n.renderWith(message, func(key, _ string) string {
+		return "<" + key + ">"
+	})
+}
+
+// renderWith returns the indented form of a message, with the values of the
+// volatile fields replaced by the result of replace.
+func (n *normalizer) renderWith(message json.RawMessage, replace func(key, raw string) string) string {
+	decoder := json.NewDecoder(bytes.NewReader(message))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return string(message)
+	}
+	rendered, err := json.MarshalIndent(n.normalize("", value, replace), "", "  ")
+	if err != nil {
+		return string(message)
+	}
+	return string(rendered)
+}
+
+// normalize replaces the values of the volatile fields in value. Object keys
+// are visited in sorted order, so placeholders are numbered the same way in
+// every run.
+func (n *normalizer) normalize(key string, value any, replace func(key, raw string) string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v[k] = n.normalize(k, v[k], replace)
+		}
+		return v
+	case []any:
+		for i, child := range v {
+			v[i] = n.normalize(key, child, replace)
+		}
+		return v
+	}
+	if !n.fields[key] || value == nil {
+		return value
+	}
+	return replace(key, fmt.Sprint(value))
+}
+
+// placeholder returns the placeholder of the value raw of the field key,
+// assigning the next one if the value has not been seen yet.
+func (n *normalizer) placeholder(key, raw string) string {
+	placeholder, ok := n.placeholders[key+"\x00"+raw]
+	if !ok {
+		n.counts[key]++
+		placeholder = fmt.Sprintf("<%s-%d>", key, n.counts[key])
+		n.placeholders[key+"\x00"+raw] = placeholder
+	}
+	return placeholder
+}
+
+// renderExchanges renders exchanges for comparison. Replies within an
+// exchange are sorted, as their relative order is not deterministic. They are
+// sorted by their form with the volatile fields masked before placeholders
+// are assigned, so the numbering does not depend on the order of arrival.
+func renderExchanges(n *normalizer, exchanges []exchange) string {
+	var b strings.Builder
+	for _, exchange := range exchanges {
+		if exchange.message.Message != nil {
+			writeRendered(&b, exchange.message.From, n.render(exchange.message.Message))
+		}
+		type sortedReply struct {
+			message TranscriptMessage
+			key     string
+		}
+		replies := make([]sortedReply, len(exchange.replies))
+		for i, reply := range exchange.replies {
+			replies[i] = sortedReply{message: reply, key: n.sortKey(reply.Message)}
+		}
+		sort.SliceStable(replies, func(i, j int) bool {
+			return replies[i].key < replies[j].key
+		})
+		for _, reply := range replies {
+			writeRendered(&b, reply.message.From, n.render(reply.message.Message))
+		}
+	}
+	return b.String()
+}
+
+func writeRendered(b *strings.Builder, from Sender, rendered string) {
+	if from == SenderClient {
+		b.WriteString("--> client\n")
+	} else {
+		b.WriteString("<-- server\n")
+	}
+	b.WriteString(rendered)
+	b.WriteString("\n")
+}
+
+// diffContext is the number of unchanged lines shown around the changes in
+// the output of diffTranscripts.
+const diffContext = 3
+
+// diffTranscripts returns a line diff of two rendered transcripts, or an
+// empty string if they are equal. Removed lines start with "-", added lines
+// with "+", and unchanged lines around them with a space.
+func diffTranscripts(want, got string) string {
+	if want == got {
+		return ""
+	}
+	a := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type diffLine struct {
+		op   byte
+		text string
+	}
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+
+	// Show the changed lines and the unchanged ones close to a change.
+	show := make([]bool, len(lines))
+	for k, line := range lines {
+		if line.op == ' ' {
+			continue
+		}
+		for c := max(0, k-diffContext); c <= min(len(lines)-1, k+diffContext); c++ {
+			show[c] = true
+		}
+	}
+	var out strings.Builder
+	out.WriteString("--- want\n+++ got\n")
+	for k, line := range lines {
+		if !show[k] {
+			continue
+		}
+		if k > 0 && !show[k-1] {
+			out.WriteString("...\n")
+		}
+		out.WriteByte(line.op)
+		out.WriteString(line.text)
+		out.WriteByte('\n')
+	}
+	return out.String()
+}
+
+// recordingWriter records every line written to it as a message of from.
+type recordingWriter struct {
+	w          io.WriteCloser
+	transcript *Transcript
+	from       Sender
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (r *recordingWriter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	r.buf = append(r.buf, p...)
+	for {
+		i := bytes.IndexByte(r.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if line := bytes.TrimSpace(r.buf[:i]); len(line) > 0 {
+			r.transcript.Append(r.from, line)
+		}
+		r.buf = r.buf[i+1:]
+	}
+	r.mu.Unlock()
+	return r.w.Write(p)
+}
+
+func (r *recordingWriter) Close() error {
+	return r.w.Close()
+}
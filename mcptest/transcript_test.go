@@ -0,0 +1,251 @@
+package mcptest_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/mcptest"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// failureRecorder captures failures reported by the helpers under test.
+type failureRecorder struct {
+	testing.TB
+
+	mu       sync.Mutex
+	failures []string
+}
+
+func (r *failureRecorder) Errorf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *failureRecorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+func (r *failureRecorder) Failures() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.failures...)
+}
+
+// recordHelloSession records a session that lists the tools and calls hello.
+func recordHelloSession(t *testing.T) *mcptest.Transcript {
+	t.Helper()
+	ctx := context.Background()
+
+	srv := mcptest.NewUnstartedServer(t, mcptest.WithRecording())
+	defer srv.Close()
+	srv.AddTool(mcp.NewTool("hello", mcp.WithString("name")), helloWorldHandler)
+	if err := srv.Start(ctx); err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	if _, err := srv.Client().ListTools(ctx, mcp.ListToolsRequest{}); err != nil {
+		t.Fatal("ListTools:", err)
+	}
+	var req mcp.CallToolRequest
+	req.Params.Name = "hello"
+	req.Params.Arguments = map[string]any{"name": "Replay"}
+	if _, err := srv.Client().CallTool(ctx, req); err != nil {
+		t.Fatal("CallTool:", err)
+	}
+
+	return srv.Transcript()
+}
+
+// newHelloServer builds the server recorded by recordHelloSession for the test name.
+func newHelloServer(name string, handler server.ToolHandlerFunc) *server.MCPServer {
+	mcpServer := server.NewMCPServer(name, "1.0.0")
+	mcpServer.AddTool(mcp.NewTool("hello", mcp.WithString("name")), handler)
+	// mcptest.Server registers its prompts and resources even when there are none.
+	mcpServer.AddPrompts()
+	mcpServer.AddResources()
+	return mcpServer
+}
+
+func TestRecording(t *testing.T) {
+	transcript := recordHelloSession(t)
+
+	var methods []string
+	for _, message := range transcript.Messages() {
+		if message.From == mcptest.SenderClient {
+			methods = append(methods, string(message.Message))
+		}
+	}
+	if len(methods) != 4 {
+		t.Fatalf("Got %d client messages, want 4 (initialize, initialized, list, call)", len(methods))
+	}
+	for i, method := range []string{"initialize", "notifications/initialized", "tools/list", "tools/call"} {
+		if !strings.Contains(methods[i], `"method":"`+method+`"`) {
+			t.Errorf("Client message %d is %s, want %s", i, methods[i], method)
+		}
+	}
+
+	golden := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := transcript.Save(golden); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := mcptest.LoadTranscript(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(loaded.Messages()), len(transcript.Messages()); got != want {
+		t.Errorf("Got %d messages after loading, want %d", got, want)
+	}
+}
+
+func TestReplayServer(t *testing.T) {
+	transcript := recordHelloSession(t)
+	name := t.Name()
+
+	t.Run("unchanged", func(t *testing.T) {
+		recorder := &failureRecorder{TB: t}
+		mcptest.ReplayServer(recorder, newHelloServer(name, helloWorldHandler), transcript)
+		if failures := recorder.Failures(); len(failures) > 0 {
+			t.Errorf("Unexpected failures: %v", failures)
+		}
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		recorder := &failureRecorder{TB: t}
+		changed := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("Goodbye!"), nil
+		}
+		mcptest.ReplayServer(recorder, newHelloServer(name, changed), transcript)
+
+		failures := recorder.Failures()
+		if len(failures) != 1 {
+			t.Fatalf("Got %d failures, want 1: %v", len(failures), failures)
+		}
+		for _, want := range []string{`"text": "Hello, Replay!"`, `"text": "Goodbye!"`} {
+			if !strings.Contains(failures[0], want) {
+				t.Errorf("Diff does not contain %q:\n%s", want, failures[0])
+			}
+		}
+	})
+}
+
+func TestCompareGolden(t *testing.T) {
+	mcptest.CompareGolden(t, filepath.Join("testdata", "hello.jsonl"), recordHelloSession(t))
+}
+
+func TestCompareGolden_NormalisesVolatileFields(t *testing.T) {
+	message := func(from mcptest.Sender, body string) mcptest.TranscriptMessage {
+		return mcptest.TranscriptMessage{From: from, Message: []byte(body)}
+	}
+	golden := mcptest.NewTranscript(
+		message(mcptest.SenderClient, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","task":{}}}`),
+		message(mcptest.SenderServer, `{"jsonrpc":"2.0","id":1,"result":{"task":{"taskId":"a","createdAt":"2024-01-01T00:00:00Z"}}}`),
+		message(mcptest.SenderClient, `{"jsonrpc":"2.0","id":2,"method":"tasks/get","params":{"taskId":"a"}}`),
+	)
+	path := filepath.Join(t.TempDir(), "golden.jsonl")
+	if err := golden.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	same := mcptest.NewTranscript(
+		message(mcptest.SenderClient, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","task":{}}}`),
+		message(mcptest.SenderServer, `{"jsonrpc":"2.0","id":1,"result":{"task":{"taskId":"b","createdAt":"2025-06-01T12:00:00Z"}}}`),
+		message(mcptest.SenderClient, `{"jsonrpc":"2.0","id":2,"method":"tasks/get","params":{"taskId":"b"}}`),
+	)
+	recorder := &failureRecorder{TB: t}
+	mcptest.CompareGolden(recorder, path, same)
+	if failures := recorder.Failures(); len(failures) > 0 {
+		t.Errorf("Unexpected failures: %v", failures)
+	}
+
+	// A task ID that no longer refers to the task created earlier is a change.
+	different := mcptest.NewTranscript(
+		message(mcptest.SenderClient, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","task":{}}}`),
+		message(mcptest.SenderServer, `{"jsonrpc":"2.0","id":1,"result":{"task":{"taskId":"b","createdAt":"2025-06-01T12:00:00Z"}}}`),
+		message(mcptest.SenderClient, `{"jsonrpc":"2.0","id":2,"method":"tasks/get","params":{"taskId":"c"}}`),
+	)
+	recorder = &failureRecorder{TB: t}
+	mcptest.CompareGolden(recorder, path, different)
+	if len(recorder.Failures()) != 1 {
+		t.Errorf("Got %d failures, want 1", len(recorder.Failures()))
+	}
+}
+
+func TestCompareGolden_ReplyOrder(t *testing.T) {
+	message := func(from mcptest.Sender, body string) mcptest.TranscriptMessage {
+		return mcptest.TranscriptMessage{From: from, Message: []byte(body)}
+	}
+	golden := mcptest.NewTranscript(
+		message(mcptest.SenderClient, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","task":{}}}`),
+		message(mcptest.SenderServer, `{"jsonrpc":"2.0","method":"notifications/tasks/status","params":{"taskId":"a","status":"working"}}`),
+		message(mcptest.SenderServer, `{"jsonrpc":"2.0","method":"notifications/tasks/status","params":{"taskId":"b","status":"completed"}}`),
+		message(mcptest.SenderServer, `{"jsonrpc":"2.0","id":1,"result":{"first":{"taskId":"a"},"second":{"taskId":"b"}}}`),
+		message(mcptest.SenderClient, `{"jsonrpc":"2.0","id":2,"method":"tasks/get","params":{"taskId":"a"}}`),
+	)
+	path := filepath.Join(t.TempDir(), "golden.jsonl")
+	if err := golden.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// The replies arrive in another order, which must not change the
+	// placeholders of the task IDs.
+	reordered := mcptest.NewTranscript(
+		message(mcptest.SenderClient, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","task":{}}}`),
+		message(mcptest.SenderServer, `{"jsonrpc":"2.0","id":1,"result":{"first":{"taskId":"c"},"second":{"taskId":"d"}}}`),
+		message(mcptest.SenderServer, `{"jsonrpc":"2.0","method":"notifications/tasks/status","params":{"taskId":"d","status":"completed"}}`),
+		message(mcptest.SenderServer, `{"jsonrpc":"2.0","method":"notifications/tasks/status","params":{"taskId":"c","status":"working"}}`),
+		message(mcptest.SenderClient, `{"jsonrpc":"2.0","id":2,"method":"tasks/get","params":{"taskId":"c"}}`),
+	)
+	for i := 0; i < 20; i++ {
+		recorder := &failureRecorder{TB: t}
+		mcptest.CompareGolden(recorder, path, reordered)
+		if failures := recorder.Failures(); len(failures) > 0 {
+			t.Fatalf("Unexpected failures: %v", failures)
+		}
+	}
+}
+
+func TestClientReplay(t *testing.T) {
+	transcript := recordHelloSession(t)
+	ctx := context.Background()
+
+	replay := mcptest.NewClientReplay(t, transcript)
+	c := client.NewClient(replay.Transport())
+	if err := c.Start(ctx); err != nil {
+		t.Fatal("Start:", err)
+	}
+
+	var initReq mcp.InitializeRequest
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := c.Initialize(ctx, initReq); err != nil {
+		t.Fatal("Initialize:", err)
+	}
+	tools, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatal("ListTools:", err)
+	}
+	if len(tools.Tools) != 1 || tools.Tools[0].Name != "hello" {
+		t.Errorf("Got tools %v, want [hello]", tools.Tools)
+	}
+
+	var req mcp.CallToolRequest
+	req.Params.Name = "hello"
+	req.Params.Arguments = map[string]any{"name": "Replay"}
+	result, err := c.CallTool(ctx, req)
+	if err != nil {
+		t.Fatal("CallTool:", err)
+	}
+	got, err := resultToString(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Hello, Replay!"; got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,203 @@
+package observability
+
+import (
+	"context"
+	"crypto/rand"
+	"slices"
+	"sync"
+	"time"
+)
+
+// SpanData is a span recorded by a MemoryExporter.
+type SpanData struct {
+	Name              string
+	Kind              SpanKind
+	SpanContext       SpanContext
+	Parent            SpanContext
+	Attributes        []Attribute
+	Status            StatusCode
+	StatusDescription string
+	Errors            []error
+	Start             time.Time
+	End               time.Time
+	Ended             bool
+}
+
+// Attribute returns the value of the attribute with key.
+func (s SpanData) Attribute(key string) (any, bool) {
+	for i := len(s.Attributes) - 1; i >= 0; i-- {
+		if s.Attributes[i].Key == key {
+			return s.Attributes[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Measurement is a value recorded by an instrument of a MemoryExporter.
+type Measurement struct {
+	Value      float64
+	Attributes []Attribute
+}
+
+// MemoryExporter is a Tracer and Meter that keeps all spans and measurements
+// in memory. It is meant for tests.
+type MemoryExporter struct {
+	mu           sync.Mutex
+	spans        []*memorySpan
+	measurements map[string][]Measurement
+}
+
+var (
+	_ Tracer = (*MemoryExporter)(nil)
+	_ Meter  = (*MemoryExporter)(nil)
+)
+
+// NewMemoryExporter creates an empty MemoryExporter.
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{measurements: make(map[string][]Measurement)}
+}
+
+// Start starts a span. The span is sampled and belongs to the trace of the
+// span context in ctx, or to a new trace.
+func (e *MemoryExporter) Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span) {
+	span := &memorySpan{data: SpanData{Name: name, Kind: kind, Start: time.Now()}}
+	span.data.SpanContext.Sampled = true
+	if parent, ok := SpanContextFromContext(ctx); ok {
+		span.data.Parent = parent
+		span.data.SpanContext.TraceID = parent.TraceID
+	} else {
+		_, _ = rand.Read(span.data.SpanContext.TraceID[:])
+	}
+	_, _ = rand.Read(span.data.SpanContext.SpanID[:])
+
+	e.mu.Lock()
+	e.spans = append(e.spans, span)
+	e.mu.Unlock()
+	return ContextWithSpanContext(ctx, span.data.SpanContext), span
+}
+
+// Spans returns the spans started so far, in the order they were started.
+func (e *MemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	spans := make([]SpanData, len(e.spans))
+	for i, span := range e.spans {
+		spans[i] = span.snapshot()
+	}
+	return spans
+}
+
+// Float64Histogram returns a histogram recording into the exporter.
+func (e *MemoryExporter) Float64Histogram(name, description, unit string) Histogram {
+	return memoryInstrument{exporter: e, name: name}
+}
+
+// Int64UpDownCounter returns a counter recording into the exporter.
+func (e *MemoryExporter) Int64UpDownCounter(name, description, unit string) UpDownCounter {
+	return memoryInstrument{exporter: e, name: name}
+}
+
+// Measurements returns the values recorded by the instrument with name.
+func (e *MemoryExporter) Measurements(name string) []Measurement {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.measurements[name])
+}
+
+// Sum returns the sum of the values recorded by the instrument with name
+// whose attributes include all of attributes. For an UpDownCounter, this is
+// its current value.
+func (e *MemoryExporter) Sum(name string, attributes ...Attribute) float64 {
+	var sum float64
+	for _, measurement := range e.Measurements(name) {
+		if containsAll(measurement.Attributes, attributes) {
+			sum += measurement.Value
+		}
+	}
+	return sum
+}
+
+// Reset discards all spans and measurements.
+func (e *MemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+	e.measurements = make(map[string][]Measurement)
+}
+
+func containsAll(attributes, want []Attribute) bool {
+	for _, attribute := range want {
+		if !slices.Contains(attributes, attribute) {
+			return false
+		}
+	}
+	return true
+}
+
+type memoryInstrument struct {
+	exporter *MemoryExporter
+	name     string
+}
+
+func (i memoryInstrument) Record(ctx context.Context, value float64, attributes ...Attribute) {
+	i.record(value, attributes)
+}
+
+func (i memoryInstrument) Add(ctx context.Context, delta int64, attributes ...Attribute) {
+	i.record(float64(delta), attributes)
+}
+
+func (i memoryInstrument) record(value float64, attributes []Attribute) {
+	i.exporter.mu.Lock()
+	defer i.exporter.mu.Unlock()
+	i.exporter.measurements[i.name] = append(i.exporter.measurements[i.name], Measurement{
+		Value:      value,
+		Attributes: slices.Clone(attributes),
+	})
+}
+
+type memorySpan struct {
+	mu   sync.Mutex
+	data SpanData
+}
+
+func (s *memorySpan) SpanContext() SpanContext {
+	return s.data.SpanContext
+}
+
+func (s *memorySpan) SetAttributes(attributes ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attributes...)
+}
+
+func (s *memorySpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Errors = append(s.data.Errors, err)
+}
+
+func (s *memorySpan) SetStatus(code StatusCode, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Status = code
+	s.data.StatusDescription = description
+}
+
+func (s *memorySpan) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.data.Ended {
+		s.data.End = time.Now()
+		s.data.Ended = true
+	}
+}
+
+func (s *memorySpan) snapshot() SpanData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := s.data
+	data.Attributes = slices.Clone(s.data.Attributes)
+	data.Errors = slices.Clone(s.data.Errors)
+	return data
+}
@@ -0,0 +1,216 @@
+// Package observability traces and measures the JSON-RPC requests handled by
+// an MCP server and sent by an MCP client.
+//
+// The package depends only on the small Tracer and Meter interfaces defined
+// here, so any tracing or metrics backend can be plugged in with a thin
+// adapter. MemoryExporter implements both and records everything in memory
+// for tests.
+//
+//	observer := observability.New(
+//		observability.WithTracer(tracer),
+//		observability.WithMeter(meter),
+//	)
+//	mcpServer := server.NewMCPServer("example", "1.0.0", observer.ServerOption())
+//	mcpClient := client.NewClient(observer.Transport(clientTransport))
+//
+// Trace context is propagated in the traceparent field of a request's _meta,
+// using the W3C Trace Context format. Requests sent by an instrumented client
+// and requests an instrumented server sends back to the client, such as
+// sampling requests, carry it automatically.
+package observability
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TraceparentMetaKey is the _meta key carrying the W3C traceparent of the
+// caller.
+const TraceparentMetaKey = "traceparent"
+
+// Attribute keys set on spans and measurements.
+const (
+	AttributeMethod    = "mcp.method.name"
+	AttributeToolName  = "gen_ai.tool.name"
+	AttributeSessionID = "mcp.session.id"
+	AttributeRequestID = "jsonrpc.request.id"
+	AttributeErrorCode = "rpc.jsonrpc.error_code"
+)
+
+// Metric names.
+const (
+	// MetricServerRequestDuration is a histogram of the time, in seconds, the
+	// server took to handle a request.
+	MetricServerRequestDuration = "mcp.server.request.duration"
+	// MetricServerRequestsInFlight counts the requests the server is handling.
+	MetricServerRequestsInFlight = "mcp.server.requests.in_flight"
+	// MetricClientRequestDuration is a histogram of the time, in seconds, the
+	// client waited for a response.
+	MetricClientRequestDuration = "mcp.client.request.duration"
+	// MetricClientRequestsInFlight counts the requests the client is waiting on.
+	MetricClientRequestsInFlight = "mcp.client.requests.in_flight"
+)
+
+// ErrInvalidTraceparent is returned when a traceparent value is malformed.
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// Attribute is a key-value pair describing a span or a measurement.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// SpanKind describes the role of a span in a request.
+type SpanKind int
+
+const (
+	// SpanKindServer is a span for handling a request.
+	SpanKindServer SpanKind = iota + 1
+	// SpanKindClient is a span for sending a request and waiting for its response.
+	SpanKindClient
+)
+
+// StatusCode is the status of a finished span.
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = iota
+	StatusOK
+	StatusError
+)
+
+// Tracer starts spans. The parent of a new span is the span context stored in
+// ctx, see SpanContextFromContext.
+type Tracer interface {
+	Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span)
+}
+
+// Span is a single traced operation.
+type Span interface {
+	SpanContext() SpanContext
+	SetAttributes(attributes ...Attribute)
+	RecordError(err error)
+	SetStatus(code StatusCode, description string)
+	End()
+}
+
+// Meter creates instruments for recording measurements.
+type Meter interface {
+	Float64Histogram(name, description, unit string) Histogram
+	Int64UpDownCounter(name, description, unit string) UpDownCounter
+}
+
+// Histogram records a distribution of values, such as latencies.
+type Histogram interface {
+	Record(ctx context.Context, value float64, attributes ...Attribute)
+}
+
+// UpDownCounter records a value that can go up and down, such as the number
+// of requests in flight.
+type UpDownCounter interface {
+	Add(ctx context.Context, delta int64, attributes ...Attribute)
+}
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// SpanContext identifies a span across process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether both the trace ID and the span ID are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// Traceparent formats sc as a W3C traceparent value.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), flags)
+}
+
+// ParseTraceparent parses a W3C traceparent value.
+func ParseTraceparent(traceparent string) (SpanContext, error) {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		(parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, fmt.Errorf("%w: %q", ErrInvalidTraceparent, traceparent)
+	}
+
+	var sc SpanContext
+	var flags [1]byte
+	for _, field := range []struct {
+		value string
+		dst   []byte
+	}{
+		{parts[0], make([]byte, 1)},
+		{parts[1], sc.TraceID[:]},
+		{parts[2], sc.SpanID[:]},
+		{parts[3], flags[:]},
+	} {
+		if len(field.value) != 2*len(field.dst) || strings.ToLower(field.value) != field.value {
+			return SpanContext{}, fmt.Errorf("%w: %q", ErrInvalidTraceparent, traceparent)
+		}
+		if _, err := hex.Decode(field.dst, []byte(field.value)); err != nil {
+			return SpanContext{}, fmt.Errorf("%w: %q", ErrInvalidTraceparent, traceparent)
+		}
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("%w: %q", ErrInvalidTraceparent, traceparent)
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, nil
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx carrying sc as the current span
+// context, which becomes the parent of spans started from it.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the current span context stored in ctx.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// noop implements Tracer, Span and Meter without recording anything.
+type noop struct{}
+
+func (noop) Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span) {
+	return ctx, noop{}
+}
+
+func (noop) SpanContext() SpanContext                                           { return SpanContext{} }
+func (noop) SetAttributes(attributes ...Attribute)                              {}
+func (noop) RecordError(err error)                                              {}
+func (noop) SetStatus(code StatusCode, description string)                      {}
+func (noop) End()                                                               {}
+func (noop) Float64Histogram(name, description, unit string) Histogram          { return noop{} }
+func (noop) Int64UpDownCounter(name, description, unit string) UpDownCounter    { return noop{} }
+func (noop) Record(ctx context.Context, value float64, attributes ...Attribute) {}
+func (noop) Add(ctx context.Context, delta int64, attributes ...Attribute)      {}
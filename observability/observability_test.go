@@ -0,0 +1,214 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	const valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	sc, err := ParseTraceparent(valid)
+	require.NoError(t, err)
+	assert.True(t, sc.IsValid())
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, valid, sc.Traceparent())
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01",
+	} {
+		_, err := ParseTraceparent(invalid)
+		assert.ErrorIs(t, err, ErrInvalidTraceparent, invalid)
+	}
+}
+
+type samplingHandlerFunc func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+
+func (f samplingHandlerFunc) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	return f(ctx, request)
+}
+
+// findSpan returns the only span with name and kind.
+func findSpan(t *testing.T, spans []SpanData, name string, kind SpanKind) SpanData {
+	t.Helper()
+	var found []SpanData
+	for _, span := range spans {
+		if span.Name == name && span.Kind == kind {
+			found = append(found, span)
+		}
+	}
+	require.Len(t, found, 1, "spans named %q", name)
+	return found[0]
+}
+
+func TestObserver(t *testing.T) {
+	ctx := context.Background()
+	exporter := NewMemoryExporter()
+	observer := New(WithTracer(exporter), WithMeter(exporter))
+
+	mcpServer := server.NewMCPServer("observability-test", "1.0.0", observer.ServerOption())
+	mcpServer.EnableSampling()
+	mcpServer.AddTool(mcp.NewTool("ask"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var samplingRequest mcp.CreateMessageRequest
+		samplingRequest.Messages = []mcp.SamplingMessage{{Role: mcp.RoleUser, Content: mcp.NewTextContent("question")}}
+		samplingRequest.MaxTokens = 10
+		result, err := server.ServerFromContext(ctx).RequestSampling(ctx, samplingRequest)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(result.Content.(mcp.TextContent).Text), nil
+	})
+
+	httpServer := server.NewTestStreamableHTTPServer(mcpServer)
+	defer httpServer.Close()
+	httpTransport, err := transport.NewStreamableHTTP(httpServer.URL, transport.WithContinuousListening())
+	require.NoError(t, err)
+
+	var samplingParent SpanContext
+	samplingHandler := samplingHandlerFunc(func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		samplingParent, _ = SpanContextFromContext(ctx)
+		return &mcp.CreateMessageResult{
+			SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent("answer")},
+			Model:           "test",
+		}, nil
+	})
+	mcpClient := client.NewClient(observer.Transport(httpTransport), client.WithSamplingHandler(samplingHandler))
+	require.NoError(t, mcpClient.Start(ctx))
+	defer mcpClient.Close()
+
+	var initRequest mcp.InitializeRequest
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	_, err = mcpClient.Initialize(ctx, initRequest)
+	require.NoError(t, err)
+
+	var callRequest mcp.CallToolRequest
+	callRequest.Params.Name = "ask"
+	result, err := mcpClient.CallTool(ctx, callRequest)
+	require.NoError(t, err)
+	require.Equal(t, "answer", result.Content[0].(mcp.TextContent).Text)
+
+	callRequest.Params.Name = "missing"
+	_, err = mcpClient.CallTool(ctx, callRequest)
+	require.Error(t, err)
+
+	spans := exporter.Spans()
+
+	t.Run("trace propagation", func(t *testing.T) {
+		clientCall := findSpan(t, spans, "tools/call ask", SpanKindClient)
+		serverCall := findSpan(t, spans, "tools/call ask", SpanKindServer)
+		samplingRequest := findSpan(t, spans, "sampling/createMessage", SpanKindClient)
+		sampling := findSpan(t, spans, "sampling/createMessage", SpanKindServer)
+
+		assert.False(t, clientCall.Parent.IsValid(), "client span starts a new trace")
+		assert.Equal(t, clientCall.SpanContext, serverCall.Parent)
+		assert.Equal(t, serverCall.SpanContext, samplingRequest.Parent)
+		assert.Equal(t, samplingRequest.SpanContext, sampling.Parent)
+		assert.Equal(t, sampling.SpanContext, samplingParent)
+		for _, span := range []SpanData{clientCall, serverCall, samplingRequest, sampling} {
+			assert.True(t, span.Ended, span.Name)
+		}
+	})
+
+	t.Run("attributes", func(t *testing.T) {
+		serverCall := findSpan(t, spans, "tools/call ask", SpanKindServer)
+		method, _ := serverCall.Attribute(AttributeMethod)
+		tool, _ := serverCall.Attribute(AttributeToolName)
+		sessionID, _ := serverCall.Attribute(AttributeSessionID)
+		assert.Equal(t, "tools/call", method)
+		assert.Equal(t, "ask", tool)
+		assert.Equal(t, httpTransport.GetSessionId(), sessionID)
+		assert.NotEqual(t, "", sessionID)
+		assert.Equal(t, StatusUnset, serverCall.Status)
+
+		for _, kind := range []SpanKind{SpanKindClient, SpanKindServer} {
+			missing := findSpan(t, spans, "tools/call missing", kind)
+			code, ok := missing.Attribute(AttributeErrorCode)
+			assert.True(t, ok)
+			assert.Equal(t, mcp.INVALID_PARAMS, code)
+			assert.Equal(t, StatusError, missing.Status)
+		}
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		assert.Zero(t, exporter.Sum(MetricServerRequestsInFlight))
+		assert.Zero(t, exporter.Sum(MetricClientRequestsInFlight))
+
+		for _, name := range []string{MetricServerRequestDuration, MetricClientRequestDuration} {
+			var calls, errorCalls int
+			for _, measurement := range exporter.Measurements(name) {
+				if containsAll(measurement.Attributes, []Attribute{String(AttributeMethod, "tools/call")}) {
+					calls++
+					assert.Positive(t, measurement.Value)
+				}
+				if containsAll(measurement.Attributes, []Attribute{Int(AttributeErrorCode, mcp.INVALID_PARAMS)}) {
+					errorCalls++
+				}
+			}
+			assert.Equal(t, 2, calls, name)
+			assert.Equal(t, 1, errorCalls, name)
+		}
+	})
+}
+
+func TestObserver_TransportError(t *testing.T) {
+	exporter := NewMemoryExporter()
+	observer := New(WithTracer(exporter), WithMeter(exporter))
+
+	failing := observer.Transport(failingTransport{})
+	_, err := failing.SendRequest(context.Background(), transport.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(int64(1)),
+		Method:  string(mcp.MethodPing),
+	})
+	require.Error(t, err)
+
+	span := findSpan(t, exporter.Spans(), "ping", SpanKindClient)
+	assert.Equal(t, StatusError, span.Status)
+	assert.Len(t, span.Errors, 1)
+	assert.Zero(t, exporter.Sum(MetricClientRequestsInFlight))
+}
+
+func TestObserver_TransportInterfaces(t *testing.T) {
+	observer := New()
+
+	plain := observer.Transport(failingTransport{})
+	assert.NotImplements(t, (*transport.BidirectionalInterface)(nil), plain)
+	assert.NotImplements(t, (*transport.HTTPConnection)(nil), plain)
+
+	httpTransport, err := transport.NewStreamableHTTP("http://localhost")
+	require.NoError(t, err)
+	instrumented := observer.Transport(httpTransport)
+	assert.Implements(t, (*transport.BidirectionalInterface)(nil), instrumented)
+	assert.Implements(t, (*transport.HTTPConnection)(nil), instrumented)
+}
+
+var errUnavailable = errors.New("unavailable")
+
+type failingTransport struct {
+	transport.Interface
+}
+
+func (failingTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	return nil, errUnavailable
+}
+
+func (failingTransport) GetSessionId() string {
+	return ""
+}
@@ -0,0 +1,366 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Observer creates spans and records metrics for MCP requests.
+type Observer struct {
+	tracer Tracer
+
+	serverDuration Histogram
+	serverInFlight UpDownCounter
+	clientDuration Histogram
+	clientInFlight UpDownCounter
+}
+
+// Option configures an Observer.
+type Option func(*observerConfig)
+
+type observerConfig struct {
+	tracer Tracer
+	meter  Meter
+}
+
+// WithTracer sets the tracer spans are started with. Without it, no spans are
+// recorded.
+func WithTracer(tracer Tracer) Option {
+	return func(c *observerConfig) {
+		c.tracer = tracer
+	}
+}
+
+// WithMeter sets the meter metrics are recorded with. Without it, no metrics
+// are recorded.
+func WithMeter(meter Meter) Option {
+	return func(c *observerConfig) {
+		c.meter = meter
+	}
+}
+
+// New creates an Observer.
+func New(opts ...Option) *Observer {
+	config := observerConfig{tracer: noop{}, meter: noop{}}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return &Observer{
+		tracer: config.tracer,
+		serverDuration: config.meter.Float64Histogram(MetricServerRequestDuration,
+			"Time taken to handle MCP requests", "s"),
+		serverInFlight: config.meter.Int64UpDownCounter(MetricServerRequestsInFlight,
+			"MCP requests being handled", "{request}"),
+		clientDuration: config.meter.Float64Histogram(MetricClientRequestDuration,
+			"Time taken to receive responses to MCP requests", "s"),
+		clientInFlight: config.meter.Int64UpDownCounter(MetricClientRequestsInFlight,
+			"MCP requests waiting for a response", "{request}"),
+	}
+}
+
+// ServerOption instruments an MCPServer. Every request the server handles
+// gets a server span, whose parent is the traceparent in the request's _meta
+// if there is one. The span is the current span of the context passed to
+// handlers. Every request the server sends to a client, such as a sampling
+// request, gets a client span and carries the span's traceparent in its
+// _meta.
+func (o *Observer) ServerOption() server.ServerOption {
+	return func(s *server.MCPServer) {
+		server.WithMessageHandlerMiddleware(o.serverMiddleware)(s)
+		server.WithClientRequestMiddleware(o.clientRequestMiddleware)(s)
+	}
+}
+
+func (o *Observer) serverMiddleware(next server.MessageHandlerFunc) server.MessageHandlerFunc {
+	return func(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage {
+		var request struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		// Notifications and responses are not traced.
+		if json.Unmarshal(message, &request) != nil || request.Method == "" ||
+			len(request.ID) == 0 || string(request.ID) == "null" {
+			return next(ctx, message)
+		}
+
+		params := parseParams(request.Params)
+		if parent, err := ParseTraceparent(params.traceparent()); err == nil {
+			ctx = ContextWithSpanContext(ctx, parent)
+		}
+
+		attributes := []Attribute{String(AttributeMethod, request.Method)}
+		if request.Method == string(mcp.MethodToolsCall) && params.Name != "" {
+			attributes = append(attributes, String(AttributeToolName, params.Name))
+		}
+
+		ctx, span := o.start(ctx, request.Method, params.Name, SpanKindServer)
+		defer span.End()
+		span.SetAttributes(attributes...)
+		span.SetAttributes(String(AttributeRequestID, strings.Trim(string(request.ID), `"`)))
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			span.SetAttributes(String(AttributeSessionID, session.SessionID()))
+		}
+
+		methodAttribute := attributes[:1]
+		o.serverInFlight.Add(ctx, 1, methodAttribute...)
+		defer o.serverInFlight.Add(ctx, -1, methodAttribute...)
+		start := time.Now()
+		response := next(ctx, message)
+
+		if response, ok := response.(mcp.JSONRPCError); ok {
+			attributes = append(attributes, Int(AttributeErrorCode, response.Error.Code))
+			span.SetAttributes(attributes[len(attributes)-1])
+			span.SetStatus(StatusError, response.Error.Message)
+		}
+		o.serverDuration.Record(ctx, time.Since(start).Seconds(), attributes...)
+		return response
+	}
+}
+
+// start starts a span named after the method and, if set, its target.
+func (o *Observer) start(ctx context.Context, method, target string, kind SpanKind) (context.Context, Span) {
+	name := method
+	if target != "" {
+		name += " " + target
+	}
+	ctx, span := o.tracer.Start(ctx, name, kind)
+	if sc := span.SpanContext(); sc.IsValid() {
+		ctx = ContextWithSpanContext(ctx, sc)
+	}
+	return ctx, span
+}
+
+func (o *Observer) clientRequestMiddleware(next server.ClientRequestFunc) server.ClientRequestFunc {
+	return func(ctx context.Context, request mcp.JSONRPCRequest) (json.RawMessage, error) {
+		ctx, span := o.start(ctx, request.Method, "", SpanKindClient)
+		defer span.End()
+		span.SetAttributes(
+			String(AttributeMethod, request.Method),
+			String(AttributeRequestID, fmt.Sprint(request.ID.Value())),
+		)
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			span.SetAttributes(String(AttributeSessionID, session.SessionID()))
+		}
+		// The current span is the new span, or the caller's if the tracer
+		// does not record spans, so the trace is continued either way.
+		if sc, ok := SpanContextFromContext(ctx); ok {
+			if raw, err := json.Marshal(request.Params); err == nil {
+				request.Params = injectParams(raw, sc)
+			}
+		}
+
+		result, err := next(ctx, request)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(StatusError, err.Error())
+		}
+		return result, err
+	}
+}
+
+// requestParams holds the parameters the instrumentation looks at.
+type requestParams struct {
+	Name string         `json:"name"`
+	Meta map[string]any `json:"_meta"`
+}
+
+func parseParams(data []byte) requestParams {
+	var params requestParams
+	_ = json.Unmarshal(data, &params)
+	return params
+}
+
+func (p requestParams) traceparent() string {
+	traceparent, _ := p.Meta[TraceparentMetaKey].(string)
+	return traceparent
+}
+
+// Transport instruments a client transport. Every request sent through it
+// gets a client span and carries the span's traceparent in its _meta. Every
+// request received from the server, such as a sampling request, gets a server
+// span, whose parent is the traceparent in the request's _meta.
+//
+// The returned transport implements transport.BidirectionalInterface and
+// transport.HTTPConnection only if base does.
+func (o *Observer) Transport(base transport.Interface) transport.Interface {
+	t := &instrumentedTransport{Interface: base, observer: o}
+	_, bidirectional := base.(transport.BidirectionalInterface)
+	_, httpConnection := base.(transport.HTTPConnection)
+	switch {
+	case bidirectional && httpConnection:
+		return instrumentedBidirectionalHTTPTransport{t}
+	case bidirectional:
+		return instrumentedBidirectionalTransport{t}
+	case httpConnection:
+		return instrumentedHTTPTransport{t}
+	}
+	return t
+}
+
+type instrumentedTransport struct {
+	transport.Interface
+	observer *Observer
+}
+
+// instrumentedBidirectionalTransport instruments a transport that receives
+// requests from the server.
+type instrumentedBidirectionalTransport struct {
+	*instrumentedTransport
+}
+
+func (t instrumentedBidirectionalTransport) SetRequestHandler(handler transport.RequestHandler) {
+	t.setRequestHandler(handler)
+}
+
+// instrumentedHTTPTransport instruments a transport that runs over HTTP.
+type instrumentedHTTPTransport struct {
+	*instrumentedTransport
+}
+
+func (t instrumentedHTTPTransport) SetProtocolVersion(version string) {
+	t.setProtocolVersion(version)
+}
+
+// instrumentedBidirectionalHTTPTransport instruments a transport that runs
+// over HTTP and receives requests from the server.
+type instrumentedBidirectionalHTTPTransport struct {
+	*instrumentedTransport
+}
+
+func (t instrumentedBidirectionalHTTPTransport) SetRequestHandler(handler transport.RequestHandler) {
+	t.setRequestHandler(handler)
+}
+
+func (t instrumentedBidirectionalHTTPTransport) SetProtocolVersion(version string) {
+	t.setProtocolVersion(version)
+}
+
+var (
+	_ transport.BidirectionalInterface = instrumentedBidirectionalTransport{}
+	_ transport.HTTPConnection         = instrumentedHTTPTransport{}
+	_ transport.BidirectionalInterface = instrumentedBidirectionalHTTPTransport{}
+	_ transport.HTTPConnection         = instrumentedBidirectionalHTTPTransport{}
+)
+
+func (t *instrumentedTransport) SendRequest(
+	ctx context.Context,
+	request transport.JSONRPCRequest,
+) (*transport.JSONRPCResponse, error) {
+	o := t.observer
+	raw, err := json.Marshal(request.Params)
+	if err != nil {
+		return t.Interface.SendRequest(ctx, request)
+	}
+	params := parseParams(raw)
+
+	attributes := []Attribute{String(AttributeMethod, request.Method)}
+	if request.Method == string(mcp.MethodToolsCall) && params.Name != "" {
+		attributes = append(attributes, String(AttributeToolName, params.Name))
+	}
+
+	ctx, span := o.start(ctx, request.Method, params.Name, SpanKindClient)
+	defer span.End()
+	span.SetAttributes(attributes...)
+	span.SetAttributes(String(AttributeRequestID, fmt.Sprint(request.ID.Value())))
+	if sessionID := t.GetSessionId(); sessionID != "" {
+		span.SetAttributes(String(AttributeSessionID, sessionID))
+	}
+	if sc := span.SpanContext(); sc.IsValid() {
+		request.Params = injectParams(raw, sc)
+	}
+
+	methodAttribute := attributes[:1]
+	o.clientInFlight.Add(ctx, 1, methodAttribute...)
+	defer o.clientInFlight.Add(ctx, -1, methodAttribute...)
+	start := time.Now()
+	response, err := t.Interface.SendRequest(ctx, request)
+
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(StatusError, err.Error())
+	case response.Error != nil:
+		attributes = append(attributes, Int(AttributeErrorCode, response.Error.Code))
+		span.SetAttributes(attributes[len(attributes)-1])
+		span.SetStatus(StatusError, response.Error.Message)
+	}
+	o.clientDuration.Record(ctx, time.Since(start).Seconds(), attributes...)
+	return response, err
+}
+
+// injectParams returns the marshaled params with traceparent set in _meta.
+// Params that are not an object are returned unchanged.
+func injectParams(raw json.RawMessage, sc SpanContext) any {
+	var params map[string]any
+	if string(raw) == "null" {
+		params = map[string]any{}
+	} else if json.Unmarshal(raw, &params) != nil {
+		return raw
+	}
+	meta, _ := params["_meta"].(map[string]any)
+	if meta == nil {
+		meta = map[string]any{}
+	}
+	meta[TraceparentMetaKey] = sc.Traceparent()
+	params["_meta"] = meta
+	return params
+}
+
+// setRequestHandler wraps handler so that requests from the server are
+// traced. The underlying transport must implement
+// transport.BidirectionalInterface.
+func (t *instrumentedTransport) setRequestHandler(handler transport.RequestHandler) {
+	bidirectional := t.Interface.(transport.BidirectionalInterface)
+	o := t.observer
+	bidirectional.SetRequestHandler(func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+		raw, _ := json.Marshal(request.Params)
+		params := parseParams(raw)
+		if parent, err := ParseTraceparent(params.traceparent()); err == nil {
+			ctx = ContextWithSpanContext(ctx, parent)
+		}
+
+		ctx, span := o.start(ctx, request.Method, "", SpanKindServer)
+		defer span.End()
+		span.SetAttributes(
+			String(AttributeMethod, request.Method),
+			String(AttributeRequestID, fmt.Sprint(request.ID.Value())),
+		)
+
+		response, err := handler(ctx, request)
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(StatusError, err.Error())
+		case response != nil && response.Error != nil:
+			span.SetAttributes(Int(AttributeErrorCode, response.Error.Code))
+			span.SetStatus(StatusError, response.Error.Message)
+		}
+		return response, err
+	})
+}
+
+// setProtocolVersion forwards the negotiated protocol version to the
+// underlying transport, which must implement transport.HTTPConnection.
+func (t *instrumentedTransport) setProtocolVersion(version string) {
+	t.Interface.(transport.HTTPConnection).SetProtocolVersion(version)
+}
+
+// SetConnectionLostHandler forwards the handler to transports that detect
+// lost connections.
+func (t *instrumentedTransport) SetConnectionLostHandler(handler func(error)) {
+	type connectionLostSetter interface {
+		SetConnectionLostHandler(func(error))
+	}
+	if setter, ok := t.Interface.(connectionLostSetter); ok {
+		setter.SetConnectionLostHandler(handler)
+	}
+}
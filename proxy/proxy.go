@@ -0,0 +1,537 @@
+// Package proxy implements an MCP server that re-exposes the tools, prompts,
+// resources and resource templates of one or more upstream MCP servers.
+//
+// The proxy is a regular *server.MCPServer, so it can be served over any
+// transport and combined with the usual server options, hooks, tool filters
+// and middlewares, e.g. to bridge a stdio-only server onto streamable HTTP or
+// to put authentication, filtering and auditing in front of a third-party
+// server:
+//
+//	p := proxy.New("gateway", "1.0.0")
+//
+//	upstream := client.NewClient(stdioTransport, p.ClientOptions()...)
+//	// Start and initialize upstream ...
+//
+//	if err := p.AddUpstream(ctx, "files", upstream); err != nil {
+//		log.Fatal(err)
+//	}
+//	server.NewStreamableHTTPServer(p.Server()).Start(":8080")
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/mark3labs/mcp-go/util"
+)
+
+// ErrNoDownstreamSession is returned to an upstream server that sends a
+// sampling, elicitation or roots request that cannot be traced back to the
+// downstream session of a forwarded request.
+var ErrNoDownstreamSession = errors.New("no downstream session to relay the request to")
+
+// CorrelationMetaKey is the _meta key of the token the proxy adds to the tool
+// calls it forwards. An upstream server that copies it into the _meta of the
+// sampling, elicitation and roots requests it sends while handling the call
+// has them relayed to the downstream session of the call, even over
+// transports that do not relate them to the call by themselves.
+const CorrelationMetaKey = "mark3labs.github.io/proxy-correlation"
+
+// Proxy mirrors upstream MCP servers onto a single MCPServer.
+//
+// Tools, prompts and resources are merged with a client.Group and named
+// according to its collision policy. Resource templates keep their names and
+// URI templates; when several upstreams expose the same URI template, the
+// upstream added first wins.
+//
+// Upstream lists are mirrored when an upstream is added and again whenever it
+// sends a list_changed notification. Mirroring only adds and removes the
+// items that came from upstreams, so items added to the server directly are
+// kept, unless an upstream item has the same name or URI and replaces them.
+//
+// Downstream subscriptions to a resource are forwarded to the upstream serving
+// it when the first downstream session subscribes, and cancelled when the
+// last one unsubscribes or goes away. Resource updated notifications of the
+// upstreams are sent to the subscribed downstream sessions only.
+type Proxy struct {
+	server *server.MCPServer
+	group  *client.Group
+	logger util.Logger
+
+	serverOptions []server.ServerOption
+	groupOptions  []client.GroupOption
+
+	// syncMu serializes mirroring. It is held from listing the upstreams to
+	// setting the mirrored items, so a sync that listed before a concurrent
+	// one cannot overwrite its newer lists.
+	syncMu sync.Mutex
+	// tools, prompts, resources and templateURIs hold the names, URIs and URI
+	// templates of the mirrored items. They are guarded by syncMu.
+	tools        map[string]struct{}
+	prompts      map[string]struct{}
+	resources    map[string]struct{}
+	templateURIs map[string]struct{}
+
+	sessionsMu sync.Mutex
+	// sessions maps the correlation tokens of the forwarded requests that
+	// have not completed yet to their downstream sessions.
+	sessions map[string]server.ClientSession
+
+	templatesMu sync.RWMutex
+	// templates holds the mirrored resource templates and their upstreams.
+	templates []templateUpstream
+
+	// subscriptionsMu guards the subscribers map only; it is not held while
+	// subscriptions are forwarded upstream.
+	subscriptionsMu sync.Mutex
+	// subscribers holds the downstream sessions subscribed to each resource
+	// URI.
+	subscribers map[string]*subscription
+}
+
+// templateUpstream is a resource template of an upstream.
+type templateUpstream struct {
+	template mcp.ResourceTemplate
+	client   *client.Client
+}
+
+// Option configures a Proxy.
+type Option func(*Proxy)
+
+// WithServerOptions passes options to the underlying MCPServer. They are
+// applied after the proxy's defaults, which enable tool, prompt and resource
+// capabilities with list_changed notifications and resource subscriptions.
+// The proxy adds its own hooks to the Hooks passed with server.WithHooks.
+func WithServerOptions(opts ...server.ServerOption) Option {
+	return func(p *Proxy) {
+		p.serverOptions = append(p.serverOptions, opts...)
+	}
+}
+
+// WithGroupOptions configures the client.Group that merges the upstreams,
+// e.g. its namespace separator and collision policy.
+func WithGroupOptions(opts ...client.GroupOption) Option {
+	return func(p *Proxy) {
+		p.groupOptions = append(p.groupOptions, opts...)
+	}
+}
+
+// WithLogger sets the logger used to report errors while mirroring upstreams
+// in the background. The default is util.DefaultLogger.
+func WithLogger(logger util.Logger) Option {
+	return func(p *Proxy) {
+		p.logger = logger
+	}
+}
+
+// New creates a proxy server with the given name and version and no upstreams.
+func New(name, version string, opts ...Option) *Proxy {
+	p := &Proxy{
+		logger: util.DefaultLogger(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	serverOptions := append([]server.ServerOption{
+		server.WithToolCapabilities(true),
+		server.WithPromptCapabilities(true),
+		server.WithResourceCapabilities(true, true),
+		server.WithHooks(&server.Hooks{}),
+	}, p.serverOptions...)
+	p.server = server.NewMCPServer(name, version, serverOptions...)
+	p.addSubscriptionHooks(p.server.GetHooks())
+
+	p.group = client.NewGroup(p.groupOptions...)
+	p.group.OnNotification(p.handleUpstreamNotification)
+
+	return p
+}
+
+// Server returns the MCPServer exposing the mirrored upstreams.
+func (p *Proxy) Server() *server.MCPServer {
+	return p.server
+}
+
+// Group returns the group of upstream clients.
+func (p *Proxy) Group() *client.Group {
+	return p.group
+}
+
+// ClientOptions returns the options that relay sampling, elicitation and
+// roots requests from an upstream server to the downstream client. Pass them
+// to client.NewClient when creating an upstream client.
+//
+// Relayed requests go to the downstream session of the forwarded request
+// that triggered them. It is found from the context of the relayed request,
+// which the in-process transport and the streamable HTTP transport carry
+// over from the forwarded request, or else from the CorrelationMetaKey entry
+// of its _meta. Requests that cannot be traced back this way are answered
+// with ErrNoDownstreamSession.
+func (p *Proxy) ClientOptions() []client.ClientOption {
+	r := &relay{proxy: p}
+	return []client.ClientOption{
+		client.WithSamplingHandler(r),
+		client.WithElicitationHandler(r),
+		client.WithRootsHandler(r),
+	}
+}
+
+// AddUpstream adds an initialized upstream client under the given name and
+// mirrors its tools, prompts, resources and resource templates.
+func (p *Proxy) AddUpstream(ctx context.Context, name string, c *client.Client) error {
+	if err := p.group.Add(name, c); err != nil {
+		return err
+	}
+	return p.Sync(ctx)
+}
+
+// RemoveUpstream removes an upstream, closes its client and stops exposing its items.
+func (p *Proxy) RemoveUpstream(ctx context.Context, name string) error {
+	if err := p.group.Remove(name); err != nil {
+		return err
+	}
+	return p.Sync(ctx)
+}
+
+// Sync mirrors the current lists of all upstreams onto the proxy server.
+func (p *Proxy) Sync(ctx context.Context) error {
+	return errors.Join(
+		p.syncTools(ctx),
+		p.syncPrompts(ctx),
+		p.syncResources(ctx),
+		p.syncResourceTemplates(ctx),
+	)
+}
+
+// Close closes all upstream clients.
+func (p *Proxy) Close() error {
+	return p.group.Close()
+}
+
+func (p *Proxy) syncTools(ctx context.Context) error {
+	p.syncMu.Lock()
+	defer p.syncMu.Unlock()
+
+	tools, err := p.group.ListTools(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list upstream tools: %w", err)
+	}
+
+	serverTools := make([]server.ServerTool, 0, len(tools))
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		serverTools = append(serverTools, server.ServerTool{Tool: tool, Handler: p.callTool})
+		names = append(names, tool.Name)
+	}
+
+	if stale := replaceMirrored(&p.tools, names); len(stale) > 0 {
+		p.server.DeleteTools(stale...)
+	}
+	if len(serverTools) > 0 {
+		p.server.AddTools(serverTools...)
+	}
+	return nil
+}
+
+func (p *Proxy) syncPrompts(ctx context.Context) error {
+	p.syncMu.Lock()
+	defer p.syncMu.Unlock()
+
+	prompts, err := p.group.ListPrompts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list upstream prompts: %w", err)
+	}
+
+	serverPrompts := make([]server.ServerPrompt, 0, len(prompts))
+	names := make([]string, 0, len(prompts))
+	for _, prompt := range prompts {
+		serverPrompts = append(serverPrompts, server.ServerPrompt{Prompt: prompt, Handler: p.getPrompt})
+		names = append(names, prompt.Name)
+	}
+
+	if stale := replaceMirrored(&p.prompts, names); len(stale) > 0 {
+		p.server.DeletePrompts(stale...)
+	}
+	if len(serverPrompts) > 0 {
+		p.server.AddPrompts(serverPrompts...)
+	}
+	return nil
+}
+
+func (p *Proxy) syncResources(ctx context.Context) error {
+	p.syncMu.Lock()
+	defer p.syncMu.Unlock()
+
+	resources, err := p.group.ListResources(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list upstream resources: %w", err)
+	}
+
+	serverResources := make([]server.ServerResource, 0, len(resources))
+	uris := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		serverResources = append(serverResources, server.ServerResource{Resource: resource, Handler: p.readResource})
+		uris = append(uris, resource.URI)
+	}
+
+	if stale := replaceMirrored(&p.resources, uris); len(stale) > 0 {
+		p.server.DeleteResources(stale...)
+	}
+	if len(serverResources) > 0 {
+		p.server.AddResources(serverResources...)
+	}
+	return nil
+}
+
+// syncResourceTemplates mirrors the resource templates of the upstreams. An
+// upstream that fails to list its templates is logged and keeps the
+// templates mirrored from it before.
+func (p *Proxy) syncResourceTemplates(ctx context.Context) error {
+	p.syncMu.Lock()
+	defer p.syncMu.Unlock()
+
+	p.templatesMu.RLock()
+	previous := p.templates
+	p.templatesMu.RUnlock()
+
+	var (
+		templates []server.ServerResourceTemplate
+		upstreams []templateUpstream
+		uris      []string
+		seen      = make(map[string]struct{})
+	)
+	for _, name := range p.group.Servers() {
+		c, ok := p.group.Client(name)
+		if !ok || c.GetServerCapabilities().Resources == nil {
+			continue
+		}
+		var listed []mcp.ResourceTemplate
+		result, err := c.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
+		if err != nil {
+			p.logger.Errorf("proxy: failed to list resource templates of %s: %v", name, err)
+			for _, upstream := range previous {
+				if upstream.client == c {
+					listed = append(listed, upstream.template)
+				}
+			}
+		} else {
+			listed = result.ResourceTemplates
+		}
+		for _, template := range listed {
+			raw := template.URITemplate.Raw()
+			if _, ok := seen[raw]; ok {
+				continue
+			}
+			seen[raw] = struct{}{}
+			templates = append(templates, server.ServerResourceTemplate{
+				Template: template,
+				Handler:  p.readTemplateResource(c),
+			})
+			upstreams = append(upstreams, templateUpstream{template: template, client: c})
+			uris = append(uris, raw)
+		}
+	}
+
+	p.templatesMu.Lock()
+	p.templates = upstreams
+	p.templatesMu.Unlock()
+	if stale := replaceMirrored(&p.templateURIs, uris); len(stale) > 0 {
+		p.server.DeleteResourceTemplates(stale...)
+	}
+	if len(templates) > 0 {
+		p.server.AddResourceTemplates(templates...)
+	}
+	return nil
+}
+
+// replaceMirrored records keys as the mirrored items of a list and returns
+// the previously mirrored keys that are no longer present.
+func replaceMirrored(mirrored *map[string]struct{}, keys []string) []string {
+	current := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		current[key] = struct{}{}
+	}
+	var stale []string
+	for key := range *mirrored {
+		if _, ok := current[key]; !ok {
+			stale = append(stale, key)
+		}
+	}
+	*mirrored = current
+	return stale
+}
+
+func (p *Proxy) callTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx, token, done := p.track(ctx)
+	defer done()
+	if token != "" {
+		request.Params.Meta = withCorrelation(request.Params.Meta, token)
+	}
+	return p.group.CallTool(ctx, request)
+}
+
+func (p *Proxy) getPrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	ctx, _, done := p.track(ctx)
+	defer done()
+	return p.group.GetPrompt(ctx, request)
+}
+
+func (p *Proxy) readResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	ctx, _, done := p.track(ctx)
+	defer done()
+	result, err := p.group.ReadResource(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return result.Contents, nil
+}
+
+// readTemplateResource forwards reads of URIs matching an upstream template to that upstream.
+func (p *Proxy) readTemplateResource(c *client.Client) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		ctx, _, done := p.track(ctx)
+		defer done()
+		result, err := c.ReadResource(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		return result.Contents, nil
+	}
+}
+
+func (p *Proxy) handleUpstreamNotification(upstream string, notification mcp.JSONRPCNotification) {
+	var sync func(context.Context) error
+	switch notification.Method {
+	case mcp.MethodNotificationToolsListChanged:
+		sync = p.syncTools
+	case mcp.MethodNotificationPromptsListChanged:
+		sync = p.syncPrompts
+	case mcp.MethodNotificationResourcesListChanged:
+		sync = func(ctx context.Context) error {
+			return errors.Join(p.syncResources(ctx), p.syncResourceTemplates(ctx))
+		}
+	case mcp.MethodNotificationResourceUpdated:
+		if uri, ok := notification.Params.AdditionalFields["uri"].(string); ok {
+			p.server.NotifyResourceUpdated(uri)
+		}
+		return
+	default:
+		return
+	}
+
+	// Notifications are delivered on the upstream transport's reader, which
+	// must stay free to receive the list responses.
+	go func() {
+		if err := sync(context.Background()); err != nil {
+			p.logger.Errorf("proxy: failed to mirror %s after %s: %v", upstream, notification.Method, err)
+		}
+	}()
+}
+
+// downstreamSessionKey is the context key of the downstream session of a
+// forwarded request.
+type downstreamSessionKey struct{}
+
+// track records the downstream session of a forwarded request until the
+// returned function is called. It returns the context to forward the request
+// with and the correlation token of the request, which is empty if ctx has
+// no session.
+func (p *Proxy) track(ctx context.Context) (context.Context, string, func()) {
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return ctx, "", func() {}
+	}
+
+	token := uuid.New().String()
+	p.sessionsMu.Lock()
+	if p.sessions == nil {
+		p.sessions = make(map[string]server.ClientSession)
+	}
+	p.sessions[token] = session
+	p.sessionsMu.Unlock()
+
+	return context.WithValue(ctx, downstreamSessionKey{}, session), token, func() {
+		p.sessionsMu.Lock()
+		defer p.sessionsMu.Unlock()
+		delete(p.sessions, token)
+	}
+}
+
+// withCorrelation returns a copy of meta with the correlation token added.
+func withCorrelation(meta *mcp.Meta, token string) *mcp.Meta {
+	correlated := &mcp.Meta{}
+	if meta != nil {
+		correlated.ProgressToken = meta.ProgressToken
+		correlated.AdditionalFields = maps.Clone(meta.AdditionalFields)
+	}
+	if correlated.AdditionalFields == nil {
+		correlated.AdditionalFields = make(map[string]any)
+	}
+	correlated.AdditionalFields[CorrelationMetaKey] = token
+	return correlated
+}
+
+// downstreamSession returns the session a request relayed with ctx and meta
+// is sent to, or nil if it is not known.
+func (p *Proxy) downstreamSession(ctx context.Context, meta *mcp.Meta) server.ClientSession {
+	if session, ok := ctx.Value(downstreamSessionKey{}).(server.ClientSession); ok {
+		return session
+	}
+	if meta == nil {
+		return nil
+	}
+	token, ok := meta.AdditionalFields[CorrelationMetaKey].(string)
+	if !ok {
+		return nil
+	}
+	p.sessionsMu.Lock()
+	defer p.sessionsMu.Unlock()
+	return p.sessions[token]
+}
+
+// relay implements the client-side handlers of upstream clients by
+// forwarding requests to the downstream session.
+type relay struct {
+	proxy *Proxy
+}
+
+func (r *relay) context(ctx context.Context, meta *mcp.Meta) (context.Context, error) {
+	session := r.proxy.downstreamSession(ctx, meta)
+	if session == nil {
+		return nil, ErrNoDownstreamSession
+	}
+	return r.proxy.server.WithContext(ctx, session), nil
+}
+
+func (r *relay) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	ctx, err := r.context(ctx, request.Meta)
+	if err != nil {
+		return nil, err
+	}
+	return r.proxy.server.RequestSampling(ctx, request)
+}
+
+func (r *relay) Elicit(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	ctx, err := r.context(ctx, request.Params.Meta)
+	if err != nil {
+		return nil, err
+	}
+	return r.proxy.server.RequestElicitation(ctx, request)
+}
+
+func (r *relay) ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
+	ctx, err := r.context(ctx, request.Params.Meta)
+	if err != nil {
+		return nil, err
+	}
+	return r.proxy.server.RequestRoots(ctx, request)
+}
@@ -0,0 +1,428 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type samplingFunc func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+
+func (f samplingFunc) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	return f(ctx, request)
+}
+
+func newUpstream(t *testing.T, p *Proxy, s *server.MCPServer) *client.Client {
+	t.Helper()
+	tr := transport.NewInProcessTransportWithOptions(s, transport.WithSamplingHandler(&relay{proxy: p}))
+	c := client.NewClient(tr)
+	require.NoError(t, c.Start(context.Background()))
+	_, err := c.Initialize(context.Background(), mcp.InitializeRequest{})
+	require.NoError(t, err)
+	return c
+}
+
+func newDownstream(t *testing.T, p *Proxy, sampling samplingFunc) *client.Client {
+	t.Helper()
+	c, err := client.NewInProcessClientWithSamplingHandler(p.Server(), sampling)
+	require.NoError(t, err)
+	require.NoError(t, c.Start(context.Background()))
+	_, err = c.Initialize(context.Background(), mcp.InitializeRequest{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func newFilesServer(opts ...server.ServerOption) *server.MCPServer {
+	s := server.NewMCPServer("files", "1.0.0", append([]server.ServerOption{server.WithResourceCapabilities(false, true)}, opts...)...)
+	s.AddTool(mcp.NewTool("echo", mcp.WithString("text")), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("echo: " + request.GetString("text", "")), nil
+	})
+	s.AddPrompt(mcp.NewPrompt("summarize"), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return mcp.NewGetPromptResult("summary", []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent("summarize")),
+		}), nil
+	})
+	s.AddResource(mcp.NewResource("file:///readme", "readme"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "hello"}}, nil
+	})
+	s.AddResourceTemplate(mcp.NewResourceTemplate("file:///docs/{name}", "docs"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "doc"}}, nil
+	})
+	return s
+}
+
+func TestProxy_MirrorsUpstream(t *testing.T) {
+	p := New("gateway", "1.0.0")
+	defer p.Close()
+	require.NoError(t, p.AddUpstream(context.Background(), "files", newUpstream(t, p, newFilesServer())))
+
+	downstream := newDownstream(t, p, nil)
+	ctx := context.Background()
+
+	tools, err := downstream.ListTools(ctx, mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	assert.Equal(t, "files__echo", tools.Tools[0].Name)
+
+	var call mcp.CallToolRequest
+	call.Params.Name = "files__echo"
+	call.Params.Arguments = map[string]any{"text": "hi"}
+	result, err := downstream.CallTool(ctx, call)
+	require.NoError(t, err)
+	assert.Equal(t, "echo: hi", result.Content[0].(mcp.TextContent).Text)
+
+	var getPrompt mcp.GetPromptRequest
+	getPrompt.Params.Name = "files__summarize"
+	prompt, err := downstream.GetPrompt(ctx, getPrompt)
+	require.NoError(t, err)
+	assert.Equal(t, "summary", prompt.Description)
+
+	var read mcp.ReadResourceRequest
+	read.Params.URI = "file:///readme"
+	contents, err := downstream.ReadResource(ctx, read)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", contents.Contents[0].(mcp.TextResourceContents).Text)
+
+	templates, err := downstream.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
+	require.NoError(t, err)
+	require.Len(t, templates.ResourceTemplates, 1)
+
+	read.Params.URI = "file:///docs/intro"
+	contents, err = downstream.ReadResource(ctx, read)
+	require.NoError(t, err)
+	assert.Equal(t, "doc", contents.Contents[0].(mcp.TextResourceContents).Text)
+}
+
+func TestProxy_RelaysSampling(t *testing.T) {
+	p := New("gateway", "1.0.0")
+	defer p.Close()
+
+	upstream := server.NewMCPServer("llm", "1.0.0")
+	upstream.EnableSampling()
+	upstream.AddTool(mcp.NewTool("ask"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := server.ServerFromContext(ctx).RequestSampling(ctx, mcp.CreateMessageRequest{
+			CreateMessageParams: mcp.CreateMessageParams{
+				Messages:  []mcp.SamplingMessage{{Role: mcp.RoleUser, Content: mcp.NewTextContent("question")}},
+				MaxTokens: 10,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(result.Content.(mcp.TextContent).Text), nil
+	})
+	require.NoError(t, p.AddUpstream(context.Background(), "llm", newUpstream(t, p, upstream)))
+
+	downstream := newDownstream(t, p, func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		return &mcp.CreateMessageResult{
+			SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent("answer")},
+			Model:           "test",
+		}, nil
+	})
+
+	var call mcp.CallToolRequest
+	call.Params.Name = "llm__ask"
+	result, err := downstream.CallTool(context.Background(), call)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, "answer", result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestProxy_RelaysToOriginatingSession(t *testing.T) {
+	p := New("gateway", "1.0.0")
+	defer p.Close()
+
+	// Both calls are in flight before either samples, so the sampling
+	// requests cannot be told apart by timing.
+	var entered sync.WaitGroup
+	entered.Add(2)
+	upstream := server.NewMCPServer("llm", "1.0.0")
+	upstream.EnableSampling()
+	upstream.AddTool(mcp.NewTool("ask"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		entered.Done()
+		entered.Wait()
+		result, err := server.ServerFromContext(ctx).RequestSampling(ctx, mcp.CreateMessageRequest{
+			CreateMessageParams: mcp.CreateMessageParams{
+				Messages:  []mcp.SamplingMessage{{Role: mcp.RoleUser, Content: mcp.NewTextContent("who are you?")}},
+				MaxTokens: 10,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(result.Content.(mcp.TextContent).Text), nil
+	})
+	require.NoError(t, p.AddUpstream(context.Background(), "llm", newUpstream(t, p, upstream)))
+
+	answer := func(text string) samplingFunc {
+		return func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			return &mcp.CreateMessageResult{
+				SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent(text)},
+				Model:           "test",
+			}, nil
+		}
+	}
+	downstreams := map[string]*client.Client{
+		"alice": newDownstream(t, p, answer("alice")),
+		"bob":   newDownstream(t, p, answer("bob")),
+	}
+
+	var wg sync.WaitGroup
+	for name, downstream := range downstreams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var call mcp.CallToolRequest
+			call.Params.Name = "llm__ask"
+			result, err := downstream.CallTool(context.Background(), call)
+			if assert.NoError(t, err) && assert.False(t, result.IsError) {
+				assert.Equal(t, name, result.Content[0].(mcp.TextContent).Text)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestProxy_RelayCorrelation(t *testing.T) {
+	p := New("gateway", "1.0.0")
+	session := server.NewInProcessSession("downstream", nil)
+	ctx, token, done := p.track(p.Server().WithContext(context.Background(), session))
+	require.NotEmpty(t, token)
+	assert.Equal(t, session, p.downstreamSession(ctx, nil))
+
+	// Without the context, the session is found from the token in _meta.
+	meta := withCorrelation(&mcp.Meta{AdditionalFields: map[string]any{"other": 1}}, token)
+	assert.Equal(t, 1, meta.AdditionalFields["other"])
+	assert.Equal(t, session, p.downstreamSession(context.Background(), meta))
+	assert.Nil(t, p.downstreamSession(context.Background(), nil))
+
+	done()
+	assert.Nil(t, p.downstreamSession(context.Background(), meta))
+	_, err := (&relay{proxy: p}).CreateMessage(context.Background(), mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{Meta: meta},
+	})
+	assert.ErrorIs(t, err, ErrNoDownstreamSession)
+}
+
+func TestProxy_RelayWithoutDownstream(t *testing.T) {
+	p := New("gateway", "1.0.0")
+	_, err := (&relay{proxy: p}).CreateMessage(context.Background(), mcp.CreateMessageRequest{})
+	assert.ErrorIs(t, err, ErrNoDownstreamSession)
+}
+
+func TestProxy_ListChanged(t *testing.T) {
+	p := New("gateway", "1.0.0", WithGroupOptions(client.WithCollisionPolicy(client.FirstWins)))
+	defer p.Close()
+	upstream := newFilesServer()
+	require.NoError(t, p.AddUpstream(context.Background(), "files", newUpstream(t, p, upstream)))
+	downstream := newDownstream(t, p, nil)
+
+	upstream.AddTool(mcp.NewTool("grep"), nil)
+	// The in-process transport does not deliver server notifications, so the
+	// upstream's list_changed is simulated on both the catalog and the proxy.
+	c, _ := p.Group().Client("files")
+	c.Catalog().Invalidate()
+	p.handleUpstreamNotification("files", mcp.JSONRPCNotification{
+		Notification: mcp.Notification{Method: mcp.MethodNotificationToolsListChanged},
+	})
+
+	assert.Eventually(t, func() bool {
+		tools, err := downstream.ListTools(context.Background(), mcp.ListToolsRequest{})
+		return err == nil && len(tools.Tools) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestProxy_KeepsLocalItems(t *testing.T) {
+	p := New("gateway", "1.0.0")
+	defer p.Close()
+	upstream := newFilesServer()
+	require.NoError(t, p.AddUpstream(context.Background(), "files", newUpstream(t, p, upstream)))
+
+	local := p.Server()
+	local.AddTool(mcp.NewTool("status"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	local.AddPrompt(mcp.NewPrompt("greet"), nil)
+	local.AddResource(mcp.NewResource("local:///config", "config"), nil)
+	local.AddResourceTemplate(mcp.NewResourceTemplate("local:///logs/{day}", "logs"), nil)
+
+	// Items removed upstream are removed from the proxy, the local ones stay.
+	upstream.DeleteTools("echo")
+	upstream.DeletePrompts("summarize")
+	upstream.DeleteResources("file:///readme")
+	c, _ := p.Group().Client("files")
+	c.Catalog().Invalidate()
+	require.NoError(t, p.Sync(context.Background()))
+
+	downstream := newDownstream(t, p, nil)
+	ctx := context.Background()
+	tools, err := downstream.ListTools(ctx, mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	assert.Equal(t, "status", tools.Tools[0].Name)
+
+	prompts, err := downstream.ListPrompts(ctx, mcp.ListPromptsRequest{})
+	require.NoError(t, err)
+	require.Len(t, prompts.Prompts, 1)
+	assert.Equal(t, "greet", prompts.Prompts[0].Name)
+
+	resources, err := downstream.ListResources(ctx, mcp.ListResourcesRequest{})
+	require.NoError(t, err)
+	require.Len(t, resources.Resources, 1)
+	assert.Equal(t, "local:///config", resources.Resources[0].URI)
+
+	templates, err := downstream.ListResourceTemplates(ctx, mcp.ListResourceTemplatesRequest{})
+	require.NoError(t, err)
+	assert.Len(t, templates.ResourceTemplates, 2)
+}
+
+func TestProxy_ResourceTemplatesSkipFailingUpstream(t *testing.T) {
+	p := New("gateway", "1.0.0")
+	defer p.Close()
+
+	var failing atomic.Bool
+	hooks := &server.Hooks{}
+	hooks.AddOnRequestInitialization(func(ctx context.Context, id any, message any) error {
+		if raw, ok := message.(json.RawMessage); ok && failing.Load() && strings.Contains(string(raw), "resources/templates/list") {
+			return errors.New("templates unavailable")
+		}
+		return nil
+	})
+	require.NoError(t, p.AddUpstream(context.Background(), "files", newUpstream(t, p, newFilesServer(server.WithHooks(hooks)))))
+
+	other := server.NewMCPServer("other", "1.0.0", server.WithResourceCapabilities(false, true))
+	other.AddResourceTemplate(mcp.NewResourceTemplate("other:///{name}", "other"), nil)
+	failing.Store(true)
+	// The failing upstream keeps its templates and does not prevent the
+	// templates of the other upstream from being mirrored.
+	require.NoError(t, p.AddUpstream(context.Background(), "other", newUpstream(t, p, other)))
+
+	downstream := newDownstream(t, p, nil)
+	templates, err := downstream.ListResourceTemplates(context.Background(), mcp.ListResourceTemplatesRequest{})
+	require.NoError(t, err)
+	var uris []string
+	for _, template := range templates.ResourceTemplates {
+		uris = append(uris, template.URITemplate.Raw())
+	}
+	assert.ElementsMatch(t, []string{"file:///docs/{name}", "other:///{name}"}, uris)
+}
+
+func TestProxy_ResourceSubscriptions(t *testing.T) {
+	p := New("gateway", "1.0.0")
+	defer p.Close()
+
+	upstreamHooks := &server.Hooks{}
+	subscribed := make(chan string, 10)
+	upstreamHooks.AddAfterSubscribe(func(ctx context.Context, id any, message *mcp.SubscribeRequest, result *mcp.EmptyResult) {
+		subscribed <- "subscribe " + message.Params.URI
+	})
+	upstreamHooks.AddAfterUnsubscribe(func(ctx context.Context, id any, message *mcp.UnsubscribeRequest, result *mcp.EmptyResult) {
+		subscribed <- "unsubscribe " + message.Params.URI
+	})
+	upstream := newFilesServer(server.WithResourceCapabilities(true, true), server.WithHooks(upstreamHooks))
+	require.NoError(t, p.AddUpstream(context.Background(), "files", newUpstream(t, p, upstream)))
+
+	alice := newDownstream(t, p, nil)
+	bob := newDownstream(t, p, nil)
+	updates := map[*client.Client]chan string{alice: make(chan string, 10), bob: make(chan string, 10)}
+	for downstream, ch := range updates {
+		downstream.OnNotification(func(notification mcp.JSONRPCNotification) {
+			if notification.Method == mcp.MethodNotificationResourceUpdated {
+				ch <- notification.Params.AdditionalFields["uri"].(string)
+			}
+		})
+	}
+
+	ctx := context.Background()
+	for _, uri := range []string{"file:///readme", "file:///docs/intro"} {
+		require.NoError(t, alice.Subscribe(ctx, mcp.SubscribeRequest{Params: mcp.SubscribeParams{URI: uri}}))
+		assert.Equal(t, "subscribe "+uri, <-subscribed)
+	}
+	// A second subscriber does not subscribe upstream again.
+	require.NoError(t, bob.Subscribe(ctx, mcp.SubscribeRequest{Params: mcp.SubscribeParams{URI: "file:///readme"}}))
+
+	upstream.NotifyResourceUpdated("file:///docs/intro")
+	select {
+	case uri := <-updates[alice]:
+		assert.Equal(t, "file:///docs/intro", uri)
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscriber was not notified")
+	}
+	select {
+	case uri := <-updates[bob]:
+		t.Fatalf("session not subscribed to %s was notified", uri)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The upstream subscription ends with the last downstream one.
+	require.NoError(t, bob.Unsubscribe(ctx, mcp.UnsubscribeRequest{Params: mcp.UnsubscribeParams{URI: "file:///readme"}}))
+	require.NoError(t, alice.Close())
+	var unsubscribed []string
+	for range 2 {
+		select {
+		case event := <-subscribed:
+			unsubscribed = append(unsubscribed, event)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("upstream subscriptions not cancelled, got %v", unsubscribed)
+		}
+	}
+	assert.ElementsMatch(t, []string{"unsubscribe file:///readme", "unsubscribe file:///docs/intro"}, unsubscribed)
+}
+
+func TestProxy_SubscribeDoesNotBlockOtherURIs(t *testing.T) {
+	p := New("gateway", "1.0.0")
+	defer p.Close()
+
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	releaseOnce := sync.OnceFunc(func() { close(release) })
+	defer releaseOnce()
+	upstreamHooks := &server.Hooks{}
+	upstreamHooks.AddBeforeSubscribe(func(ctx context.Context, id any, message *mcp.SubscribeRequest) {
+		if message.Params.URI == "file:///readme" {
+			close(blocked)
+			<-release
+		}
+	})
+	upstream := newFilesServer(server.WithResourceCapabilities(true, true), server.WithHooks(upstreamHooks))
+	require.NoError(t, p.AddUpstream(context.Background(), "files", newUpstream(t, p, upstream)))
+
+	alice := newDownstream(t, p, nil)
+	bob := newDownstream(t, p, nil)
+	ctx := context.Background()
+
+	slow := make(chan error, 1)
+	go func() {
+		slow <- alice.Subscribe(ctx, mcp.SubscribeRequest{Params: mcp.SubscribeParams{URI: "file:///readme"}})
+	}()
+	<-blocked
+
+	// A subscription waiting for its upstream does not hold up another URI.
+	fast := make(chan error, 1)
+	go func() {
+		fast <- bob.Subscribe(ctx, mcp.SubscribeRequest{Params: mcp.SubscribeParams{URI: "file:///docs/intro"}})
+	}()
+	select {
+	case err := <-fast:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("subscription blocked by another upstream subscription")
+	}
+
+	releaseOnce()
+	require.NoError(t, <-slow)
+}
@@ -0,0 +1,156 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// addSubscriptionHooks keeps the upstream subscriptions in step with the
+// subscriptions of the downstream sessions.
+func (p *Proxy) addSubscriptionHooks(hooks *server.Hooks) {
+	hooks.AddAfterSubscribe(func(ctx context.Context, id any, message *mcp.SubscribeRequest, result *mcp.EmptyResult) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			p.subscribe(ctx, session.SessionID(), message.Params.URI)
+		}
+	})
+	hooks.AddAfterUnsubscribe(func(ctx context.Context, id any, message *mcp.UnsubscribeRequest, result *mcp.EmptyResult) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			p.unsubscribe(ctx, session.SessionID(), message.Params.URI)
+		}
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		p.unsubscribeSession(ctx, session.SessionID())
+	})
+}
+
+// subscription holds the downstream sessions subscribed to a resource URI.
+// Its mutex is held while the subscription is forwarded upstream, so that
+// subscriptions to other URIs do not wait for the upstream.
+type subscription struct {
+	mu       sync.Mutex
+	sessions map[string]struct{}
+	// removed is set once the subscription is no longer in Proxy.subscribers.
+	removed bool
+}
+
+// subscribe records the subscription of a downstream session to uri, and
+// subscribes to uri upstream if it is the first one. Failures to subscribe
+// upstream are logged, the downstream subscription is kept.
+func (p *Proxy) subscribe(ctx context.Context, sessionID, uri string) {
+	for {
+		p.subscriptionsMu.Lock()
+		if p.subscribers == nil {
+			p.subscribers = make(map[string]*subscription)
+		}
+		sub, ok := p.subscribers[uri]
+		if !ok {
+			sub = &subscription{sessions: make(map[string]struct{})}
+			p.subscribers[uri] = sub
+		}
+		p.subscriptionsMu.Unlock()
+
+		sub.mu.Lock()
+		if sub.removed {
+			// The last subscriber left while we were waiting, start over.
+			sub.mu.Unlock()
+			continue
+		}
+		if len(sub.sessions) == 0 {
+			if err := p.forwardSubscription(ctx, uri, true); err != nil {
+				p.logger.Errorf("proxy: failed to subscribe to %s upstream: %v", uri, err)
+			}
+		}
+		sub.sessions[sessionID] = struct{}{}
+		sub.mu.Unlock()
+		return
+	}
+}
+
+// unsubscribe removes the subscription of a downstream session to uri, and
+// unsubscribes from uri upstream if it was the last one.
+func (p *Proxy) unsubscribe(ctx context.Context, sessionID, uri string) {
+	p.subscriptionsMu.Lock()
+	sub, ok := p.subscribers[uri]
+	p.subscriptionsMu.Unlock()
+	if !ok {
+		return
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if _, ok := sub.sessions[sessionID]; !ok {
+		return
+	}
+	delete(sub.sessions, sessionID)
+	if len(sub.sessions) > 0 {
+		return
+	}
+	if err := p.forwardSubscription(ctx, uri, false); err != nil {
+		p.logger.Errorf("proxy: failed to unsubscribe from %s upstream: %v", uri, err)
+	}
+	p.subscriptionsMu.Lock()
+	delete(p.subscribers, uri)
+	p.subscriptionsMu.Unlock()
+	sub.removed = true
+}
+
+// unsubscribeSession removes the subscriptions of a downstream session that
+// went away.
+func (p *Proxy) unsubscribeSession(ctx context.Context, sessionID string) {
+	p.subscriptionsMu.Lock()
+	subs := make(map[string]*subscription, len(p.subscribers))
+	maps.Copy(subs, p.subscribers)
+	p.subscriptionsMu.Unlock()
+
+	for uri, sub := range subs {
+		sub.mu.Lock()
+		_, ok := sub.sessions[sessionID]
+		sub.mu.Unlock()
+		if ok {
+			p.unsubscribe(ctx, sessionID, uri)
+		}
+	}
+}
+
+// forwardSubscription subscribes to or unsubscribes from uri on the upstream
+// serving it: the one listing the resource, or else the first one with a
+// resource template matching uri.
+func (p *Proxy) forwardSubscription(ctx context.Context, uri string, subscribe bool) error {
+	var err error
+	if subscribe {
+		err = p.group.Subscribe(ctx, mcp.SubscribeRequest{Params: mcp.SubscribeParams{URI: uri}})
+	} else {
+		err = p.group.Unsubscribe(ctx, mcp.UnsubscribeRequest{Params: mcp.UnsubscribeParams{URI: uri}})
+	}
+	if !errors.Is(err, client.ErrGroupNoRoute) {
+		return err
+	}
+
+	c := p.templateUpstream(uri)
+	if c == nil {
+		return err
+	}
+	if subscribe {
+		return c.Subscribe(ctx, mcp.SubscribeRequest{Params: mcp.SubscribeParams{URI: uri}})
+	}
+	return c.Unsubscribe(ctx, mcp.UnsubscribeRequest{Params: mcp.UnsubscribeParams{URI: uri}})
+}
+
+// templateUpstream returns the upstream of the first mirrored resource
+// template matching uri, or nil.
+func (p *Proxy) templateUpstream(uri string) *client.Client {
+	p.templatesMu.RLock()
+	defer p.templatesMu.RUnlock()
+	for _, upstream := range p.templates {
+		if upstream.template.URITemplate != nil && upstream.template.URITemplate.Regexp().MatchString(uri) {
+			return upstream.client
+		}
+	}
+	return nil
+}
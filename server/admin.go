@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// AdminSessionInfo describes a client session in the admin endpoints.
+type AdminSessionInfo struct {
+	ID                string              `json:"id"`
+	Transport         string              `json:"transport"`
+	Initialized       bool                `json:"initialized"`
+	ClientInfo        *mcp.Implementation `json:"clientInfo,omitempty"`
+	ProtocolVersion   string              `json:"protocolVersion,omitempty"`
+	LogLevel          mcp.LoggingLevel    `json:"logLevel,omitempty"`
+	LastActivity      *time.Time          `json:"lastActivity,omitempty"`
+	IdleSeconds       *float64            `json:"idleSeconds,omitempty"`
+	Tools             []string            `json:"tools,omitempty"`
+	Resources         []string            `json:"resources,omitempty"`
+	ResourceTemplates []string            `json:"resourceTemplates,omitempty"`
+	// NotificationQueue is set when the server uses notification queues
+	// and the session has been sent notifications.
+	NotificationQueue *NotificationQueueStats `json:"notificationQueue,omitempty"`
+}
+
+// adminStatus is the body of the liveness and readiness endpoints.
+type adminStatus struct {
+	Status string `json:"status"`
+}
+
+// adminHandler serves the admin endpoints of a transport.
+type adminHandler struct {
+	server *MCPServer
+	// ready reports whether the transport accepts new requests.
+	ready func() bool
+	// sessions returns the sessions of the transport.
+	sessions func() []ClientSession
+	// terminate ends the session with the given ID, reporting whether it existed.
+	terminate func(ctx context.Context, sessionID string) bool
+}
+
+// newAdminHandler returns the endpoints served by the transports' AdminHandler.
+func newAdminHandler(h *adminHandler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, adminStatus{Status: "ok"})
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !h.ready() {
+			writeAdminJSON(w, http.StatusServiceUnavailable, adminStatus{Status: "shutting down"})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, adminStatus{Status: "ready"})
+	})
+	mux.HandleFunc("GET /sessions", func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		infos := []AdminSessionInfo{}
+		for _, session := range h.sessions() {
+			infos = append(infos, h.sessionInfo(session, now))
+		}
+		slices.SortFunc(infos, func(a, b AdminSessionInfo) int {
+			return strings.Compare(a.ID, b.ID)
+		})
+		writeAdminJSON(w, http.StatusOK, infos)
+	})
+	mux.HandleFunc("GET /sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		for _, session := range h.sessions() {
+			if session.SessionID() == id {
+				writeAdminJSON(w, http.StatusOK, h.sessionInfo(session, time.Now()))
+				return
+			}
+		}
+		http.Error(w, "Session not found", http.StatusNotFound)
+	})
+	mux.HandleFunc("DELETE /sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if !h.terminate(r.Context(), r.PathValue("id")) {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// sessionWithLastActivity is implemented by sessions that track when the
+// client last sent a request.
+type sessionWithLastActivity interface {
+	lastActivity() time.Time
+}
+
+func (h *adminHandler) sessionInfo(session ClientSession, now time.Time) AdminSessionInfo {
+	info := AdminSessionInfo{
+		ID:          session.SessionID(),
+		Transport:   sessionTransport(session),
+		Initialized: session.Initialized(),
+	}
+	if s, ok := session.(SessionWithClientInfo); ok {
+		if clientInfo := s.GetClientInfo(); clientInfo.Name != "" {
+			info.ClientInfo = &clientInfo
+		}
+	}
+	if s, ok := session.(SessionWithProtocolVersion); ok {
+		info.ProtocolVersion = s.GetProtocolVersion()
+	}
+	if s, ok := session.(SessionWithLogging); ok {
+		info.LogLevel = s.GetLogLevel()
+	}
+	if s, ok := session.(sessionWithLastActivity); ok {
+		lastActivity := s.lastActivity()
+		idle := now.Sub(lastActivity).Seconds()
+		info.LastActivity = &lastActivity
+		info.IdleSeconds = &idle
+	}
+	if s, ok := session.(SessionWithTools); ok {
+		info.Tools = sortedKeys(s.GetSessionTools())
+	}
+	if s, ok := session.(SessionWithResources); ok {
+		info.Resources = sortedKeys(s.GetSessionResources())
+	}
+	if s, ok := session.(SessionWithResourceTemplates); ok {
+		info.ResourceTemplates = sortedKeys(s.GetSessionResourceTemplates())
+	}
+	if stats, ok := h.server.NotificationQueueStats(info.ID); ok {
+		info.NotificationQueue = &stats
+	}
+	return info
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// AdminHandler returns an http.Handler with health, readiness and session
+// introspection endpoints:
+//
+//	GET    /healthz         liveness, always 200
+//	GET    /readyz          readiness, 503 once Shutdown has been called
+//	GET    /sessions        the active sessions
+//	GET    /sessions/{id}   a single session
+//	DELETE /sessions/{id}   terminates a session and removes its state
+//
+// The handler exposes client details and can end sessions, so it is not
+// mounted by Start. Serve it on a separate, protected listener:
+//
+//	go http.ListenAndServe("127.0.0.1:9090", httpServer.AdminHandler())
+func (s *StreamableHTTPServer) AdminHandler() http.Handler {
+	return newAdminHandler(&adminHandler{
+		server: s.server,
+		ready: func() bool {
+			return !s.shuttingDown.Load()
+		},
+		sessions: func() []ClientSession {
+			var sessions []ClientSession
+			s.activeSessions.Range(func(_, value any) bool {
+				if session, ok := value.(*streamableHttpSession); ok {
+					sessions = append(sessions, session)
+				}
+				return true
+			})
+			return sessions
+		},
+		terminate: s.terminateSession,
+	})
+}
+
+// AdminHandler returns an http.Handler with health, readiness and session
+// introspection endpoints:
+//
+//	GET    /healthz         liveness, always 200
+//	GET    /readyz          readiness, 503 once Shutdown has been called
+//	GET    /sessions        the active sessions
+//	GET    /sessions/{id}   a single session
+//	DELETE /sessions/{id}   terminates a session and closes its SSE stream
+//
+// The handler exposes client details and can end sessions, so it is not
+// served by ServeHTTP. Serve it on a separate, protected listener.
+func (s *SSEServer) AdminHandler() http.Handler {
+	return newAdminHandler(&adminHandler{
+		server: s.server,
+		ready: func() bool {
+			return !s.shuttingDown.Load()
+		},
+		sessions: func() []ClientSession {
+			var sessions []ClientSession
+			s.sessions.Range(func(_, value any) bool {
+				if session, ok := value.(*sseSession); ok {
+					sessions = append(sessions, session)
+				}
+				return true
+			})
+			return sessions
+		},
+		terminate: s.terminateSession,
+	})
+}
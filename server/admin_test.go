@@ -0,0 +1,160 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func adminRequest(t *testing.T, method, url string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func adminSessions(t *testing.T, url string) []AdminSessionInfo {
+	t.Helper()
+	resp := adminRequest(t, http.MethodGet, url+"/sessions")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var sessions []AdminSessionInfo
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&sessions))
+	return sessions
+}
+
+func TestStreamableHTTPServer_AdminHandler(t *testing.T) {
+	mcpServer := NewMCPServer("admin-test", "1.0.0", WithToolCapabilities(true), WithLogging())
+	httpServer := NewStreamableHTTPServer(mcpServer, WithStateful(true))
+	server := httptest.NewServer(httpServer)
+	defer server.Close()
+	admin := httptest.NewServer(httpServer.AdminHandler())
+	defer admin.Close()
+
+	assert.Empty(t, adminSessions(t, admin.URL))
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"admin-client","version":"1.0.0"}}}`
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(initialize))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	sessionID := resp.Header.Get(HeaderKeySessionID)
+	require.NotEmpty(t, sessionID)
+
+	require.NoError(t, mcpServer.AddSessionTool(sessionID, mcp.NewTool("private"),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("private"), nil
+		}))
+
+	streamReq, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	streamReq.Header.Set(HeaderKeySessionID, sessionID)
+	stream, err := http.DefaultClient.Do(streamReq)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+
+	t.Run("health", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, adminRequest(t, http.MethodGet, admin.URL+"/healthz").StatusCode)
+		assert.Equal(t, http.StatusOK, adminRequest(t, http.MethodGet, admin.URL+"/readyz").StatusCode)
+	})
+
+	t.Run("sessions", func(t *testing.T) {
+		sessions := adminSessions(t, admin.URL)
+		require.Len(t, sessions, 1)
+		session := sessions[0]
+		assert.Equal(t, sessionID, session.ID)
+		assert.Equal(t, "streamable_http", session.Transport)
+		require.NotNil(t, session.ClientInfo)
+		assert.Equal(t, "admin-client", session.ClientInfo.Name)
+		assert.Equal(t, "2025-03-26", session.ProtocolVersion)
+		assert.Equal(t, []string{"private"}, session.Tools)
+		require.NotNil(t, session.IdleSeconds)
+		assert.GreaterOrEqual(t, *session.IdleSeconds, 0.0)
+
+		resp := adminRequest(t, http.MethodGet, admin.URL+"/sessions/"+sessionID)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, http.StatusNotFound, adminRequest(t, http.MethodGet, admin.URL+"/sessions/unknown").StatusCode)
+	})
+
+	t.Run("terminate", func(t *testing.T) {
+		resp := adminRequest(t, http.MethodDelete, admin.URL+"/sessions/"+sessionID)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		// The listening stream is closed.
+		_, err := io.ReadAll(stream.Body)
+		require.NoError(t, err)
+
+		assert.Empty(t, adminSessions(t, admin.URL))
+		_, ok := mcpServer.sessions.Load(sessionID)
+		assert.False(t, ok)
+		assert.Empty(t, httpServer.sessionTools.get(sessionID))
+
+		ping, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"ping"}`))
+		require.NoError(t, err)
+		ping.Header.Set("Content-Type", "application/json")
+		ping.Header.Set(HeaderKeySessionID, sessionID)
+		pingResp, err := http.DefaultClient.Do(ping)
+		require.NoError(t, err)
+		pingResp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, pingResp.StatusCode)
+
+		assert.Equal(t, http.StatusNotFound, adminRequest(t, http.MethodDelete, admin.URL+"/sessions/"+sessionID).StatusCode)
+	})
+
+	t.Run("readiness after shutdown", func(t *testing.T) {
+		require.NoError(t, httpServer.Shutdown(context.Background()))
+		resp := adminRequest(t, http.MethodGet, admin.URL+"/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, http.StatusOK, adminRequest(t, http.MethodGet, admin.URL+"/healthz").StatusCode)
+	})
+}
+
+func TestSSEServer_AdminHandler(t *testing.T) {
+	mcpServer := NewMCPServer("admin-test", "1.0.0")
+	sseServer := NewSSEServer(mcpServer)
+	server := httptest.NewServer(sseServer)
+	defer server.Close()
+	admin := httptest.NewServer(sseServer.AdminHandler())
+	defer admin.Close()
+
+	stream, err := http.Get(server.URL + "/sse")
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	reader := bufio.NewReader(stream.Body)
+	_, err = reader.ReadString('\n') // event: endpoint
+	require.NoError(t, err)
+
+	sessions := adminSessions(t, admin.URL)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "sse", sessions[0].Transport)
+	assert.False(t, sessions[0].Initialized)
+
+	resp := adminRequest(t, http.MethodDelete, admin.URL+"/sessions/"+sessions[0].ID)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, reader)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("SSE stream was not closed")
+	}
+	assert.Empty(t, adminSessions(t, admin.URL))
+}
@@ -0,0 +1,277 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var (
+	// ErrRequestsNotSupported is returned when the session cannot send requests to its client
+	ErrRequestsNotSupported = errors.New("session does not support server to client requests")
+	// ErrSessionClosed is returned for requests to a client whose session was closed
+	ErrSessionClosed = errors.New("session closed")
+)
+
+// WithClientRequestTimeout sets how long requests sent to clients, such as
+// sampling, elicitation and roots requests, wait for the response when the
+// context has no earlier deadline. By default they wait until the context is
+// done.
+func WithClientRequestTimeout(timeout time.Duration) ServerOption {
+	return func(s *MCPServer) {
+		s.clientRequestTimeout = timeout
+	}
+}
+
+// ClientRequestFunc sends a request to the client of a session and waits for
+// its result.
+type ClientRequestFunc func(ctx context.Context, request mcp.JSONRPCRequest) (json.RawMessage, error)
+
+// ClientRequestMiddleware is a middleware function that wraps the sending of
+// requests to clients, such as sampling, elicitation and roots requests. It
+// may change the context and the params of a request, but not its ID.
+type ClientRequestMiddleware func(ClientRequestFunc) ClientRequestFunc
+
+// WithClientRequestMiddleware adds a middleware around the requests the
+// server sends to clients. Sampling, elicitation and roots requests to
+// in-process sessions call the client's handlers directly and do not go
+// through it.
+func WithClientRequestMiddleware(middleware ClientRequestMiddleware) ServerOption {
+	return func(s *MCPServer) {
+		s.clientRequestMiddlewares = append(s.clientRequestMiddlewares, middleware)
+	}
+}
+
+// SendRequest sends a request with the given method and params to the client
+// of the session in ctx and returns the raw result. Error responses of the
+// client are returned as errors, see mcp.JSONRPCErrorDetails.AsError. The
+// session must implement SessionWithRequests.
+//
+// This can be used for client methods the server has no helper for, such as
+// experimental ones.
+func (s *MCPServer) SendRequest(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrNoActiveSession
+	}
+	requestSession, ok := session.(SessionWithRequests)
+	if !ok {
+		return nil, ErrRequestsNotSupported
+	}
+	return requestSession.SendRequest(ctx, method, params)
+}
+
+// sendClientRequest sends a request through session and decodes the result.
+func sendClientRequest[R any](ctx context.Context, session SessionWithRequests, method mcp.MCPMethod, params any) (*R, error) {
+	raw, err := session.SendRequest(ctx, string(method), params)
+	if err != nil {
+		return nil, err
+	}
+	var result R
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s response: %w", method, err)
+	}
+	return &result, nil
+}
+
+// requestSampling sends a sampling request through session and parses the
+// content of the result.
+func requestSampling(ctx context.Context, session SessionWithRequests, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	result, err := sendClientRequest[mcp.CreateMessageResult](ctx, session, mcp.MethodSamplingCreateMessage, request.CreateMessageParams)
+	if err != nil {
+		return nil, err
+	}
+	// Content is unmarshaled as map[string]any, convert it to the proper types
+	content, err := mcp.ParseSamplingContent(result.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sampling response content: %w", err)
+	}
+	result.Content = content
+	return result, nil
+}
+
+// clientResponse is the response of a client to a request sent by the server.
+type clientResponse struct {
+	result json.RawMessage
+	err    error
+}
+
+// clientRequests sends requests to the client of a session and correlates
+// the responses the client sends back. Sessions embed it and provide the
+// function that writes a request to their transport.
+type clientRequests struct {
+	nextID  atomic.Int64
+	mu      sync.Mutex
+	pending map[int64]chan clientResponse
+	closed  bool
+}
+
+// newRequestID returns an ID that no other request to the client uses.
+func (r *clientRequests) newRequestID() int64 {
+	return r.nextID.Add(1)
+}
+
+// add registers the request with the given ID. It returns the channel the
+// response is delivered on, and a function to call once the response is no
+// longer awaited.
+func (r *clientRequests) add(id int64) (<-chan clientResponse, func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil, nil, ErrSessionClosed
+	}
+	if r.pending == nil {
+		r.pending = make(map[int64]chan clientResponse)
+	}
+	responseChan := make(chan clientResponse, 1)
+	r.pending[id] = responseChan
+	return responseChan, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.pending, id)
+	}, nil
+}
+
+// send writes a request with the given method and params using write and
+// waits for the response. The timeout and the middlewares set with
+// WithClientRequestTimeout and WithClientRequestMiddleware apply. If ctx is
+// done first, the client is notified through the notification channel of
+// session that the request was cancelled.
+func (r *clientRequests) send(
+	ctx context.Context,
+	session ClientSession,
+	method string,
+	params any,
+	write func(ctx context.Context, request mcp.JSONRPCRequest) error,
+) (json.RawMessage, error) {
+	server := ServerFromContext(ctx)
+	if server != nil && server.clientRequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, server.clientRequestTimeout)
+		defer cancel()
+	}
+
+	id := r.newRequestID()
+	responseChan, remove, err := r.add(id)
+	if err != nil {
+		return nil, err
+	}
+	defer remove()
+
+	request := mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(id),
+		Params:  params,
+		Request: mcp.Request{
+			Method: method,
+		},
+	}
+	var call ClientRequestFunc = func(ctx context.Context, request mcp.JSONRPCRequest) (json.RawMessage, error) {
+		if err := write(ctx, request); err != nil {
+			return nil, err
+		}
+
+		select {
+		case response := <-responseChan:
+			return response.result, response.err
+		case <-ctx.Done():
+			notifyRequestCancelled(server, session, id, ctx.Err())
+			return nil, ctx.Err()
+		}
+	}
+	if server != nil {
+		// Apply middlewares in reverse order
+		for i := len(server.clientRequestMiddlewares) - 1; i >= 0; i-- {
+			call = server.clientRequestMiddlewares[i](call)
+		}
+	}
+	return call(ctx, request)
+}
+
+// notifyRequestCancelled tells the client that the server no longer waits
+// for the response to the request with the given ID. The notification goes
+// through the session's notification queue if server has one.
+func notifyRequestCancelled(server *MCPServer, session ClientSession, id int64, reason error) {
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: "notifications/cancelled",
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{
+					"requestId": id,
+					"reason":    reason.Error(),
+				},
+			},
+		},
+	}
+	if server != nil {
+		// The caller is returning, so it does not wait for room in a full
+		// queue.
+		_ = server.deliverNotification(context.Background(), session, notification, false)
+		return
+	}
+	select {
+	case session.NotificationChannel() <- notification:
+	default:
+	}
+}
+
+// deliver routes message to the request waiting for it. It reports whether
+// message was a response to a pending request.
+func (r *clientRequests) deliver(message json.RawMessage) bool {
+	var response struct {
+		ID     json.Number              `json:"id"`
+		Method string                   `json:"method"`
+		Result json.RawMessage          `json:"result"`
+		Error  *mcp.JSONRPCErrorDetails `json:"error"`
+	}
+	if json.Unmarshal(message, &response) != nil || response.Method != "" {
+		return false
+	}
+	if response.Result == nil && response.Error == nil {
+		return false
+	}
+	id, err := response.ID.Int64()
+	if err != nil {
+		return false
+	}
+
+	delivered := clientResponse{result: response.Result}
+	if response.Error != nil {
+		delivered.err = response.Error.AsError()
+	}
+	return r.resolve(id, delivered)
+}
+
+// resolve delivers response to the request with the given ID. It reports
+// whether the request was still pending.
+func (r *clientRequests) resolve(id int64, response clientResponse) bool {
+	r.mu.Lock()
+	responseChan, ok := r.pending[id]
+	delete(r.pending, id)
+	r.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	responseChan <- response
+	return true
+}
+
+// close fails the pending requests with ErrSessionClosed and rejects new
+// ones.
+func (r *clientRequests) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	for id, responseChan := range r.pending {
+		responseChan <- clientResponse{err: ErrSessionClosed}
+		delete(r.pending, id)
+	}
+}
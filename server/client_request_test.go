@@ -0,0 +1,191 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestClientRequests_Deliver(t *testing.T) {
+	var requests clientRequests
+
+	id := requests.newRequestID()
+	responses, remove, err := requests.add(id)
+	require.NoError(t, err)
+	defer remove()
+
+	// Requests, notifications and unknown IDs are not responses to deliver.
+	assert.False(t, requests.deliver(json.RawMessage(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"ping"}`, id))))
+	assert.False(t, requests.deliver(json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)))
+	assert.False(t, requests.deliver(json.RawMessage(`{"jsonrpc":"2.0","id":999,"result":{}}`)))
+
+	require.True(t, requests.deliver(json.RawMessage(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"unknown method"}}`, id))))
+	response := <-responses
+	assert.ErrorIs(t, response.err, mcp.ErrMethodNotFound)
+
+	// A response is delivered once.
+	assert.False(t, requests.deliver(json.RawMessage(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{}}`, id))))
+}
+
+func TestClientRequests_Close(t *testing.T) {
+	var requests clientRequests
+	responses, remove, err := requests.add(requests.newRequestID())
+	require.NoError(t, err)
+	defer remove()
+
+	requests.close()
+	assert.ErrorIs(t, (<-responses).err, ErrSessionClosed)
+	_, _, err = requests.add(requests.newRequestID())
+	assert.ErrorIs(t, err, ErrSessionClosed)
+}
+
+func TestMCPServer_SendRequest(t *testing.T) {
+	mcpServer := NewMCPServer("test", "1.0.0",
+		WithClientRequestTimeout(100*time.Millisecond),
+		WithClientRequestMiddleware(func(next ClientRequestFunc) ClientRequestFunc {
+			return func(ctx context.Context, request mcp.JSONRPCRequest) (json.RawMessage, error) {
+				if request.Method == "x/wrapped" {
+					request.Params = map[string]any{"wrapped": request.Params}
+				}
+				return next(ctx, request)
+			}
+		}),
+	)
+	stdioServer := NewStdioServer(mcpServer)
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	defer stdinWriter.Close()
+	listenCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = stdioServer.Listen(listenCtx, stdinReader, stdoutWriter)
+	}()
+	lines := bufio.NewScanner(stdoutReader)
+	nextMessage := func() map[string]any {
+		t.Helper()
+		require.True(t, lines.Scan(), "no message written: %v", lines.Err())
+		var message map[string]any
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &message))
+		return message
+	}
+
+	require.Eventually(t, func() bool {
+		_, ok := mcpServer.sessions.Load(stdioServer.session.SessionID())
+		return ok
+	}, time.Second, 5*time.Millisecond)
+	ctx := context.WithValue(mcpServer.WithContext(context.Background(), stdioServer.session), serverKey{}, mcpServer)
+
+	_, err := mcpServer.SendRequest(context.Background(), "x/echo", nil)
+	assert.ErrorIs(t, err, ErrNoActiveSession)
+
+	t.Run("result", func(t *testing.T) {
+		results := make(chan json.RawMessage, 1)
+		go func() {
+			result, err := mcpServer.SendRequest(ctx, "x/echo", map[string]any{"value": 42})
+			assert.NoError(t, err)
+			results <- result
+		}()
+
+		request := nextMessage()
+		assert.Equal(t, "x/echo", request["method"])
+		assert.Equal(t, map[string]any{"value": float64(42)}, request["params"])
+		response := fmt.Sprintf(`{"jsonrpc":"2.0","id":%v,"result":{"echo":42}}`, request["id"])
+		_, err := io.WriteString(stdinWriter, response+"\n")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"echo":42}`, string(<-results))
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		results := make(chan json.RawMessage, 1)
+		go func() {
+			result, err := mcpServer.SendRequest(ctx, "x/wrapped", map[string]any{"value": 1})
+			assert.NoError(t, err)
+			results <- result
+		}()
+
+		request := nextMessage()
+		assert.Equal(t, map[string]any{"wrapped": map[string]any{"value": float64(1)}}, request["params"])
+		response := fmt.Sprintf(`{"jsonrpc":"2.0","id":%v,"result":{}}`, request["id"])
+		_, err := io.WriteString(stdinWriter, response+"\n")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{}`, string(<-results))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		errs := make(chan error, 1)
+		go func() {
+			_, err := mcpServer.SendRequest(ctx, "x/slow", nil)
+			errs <- err
+		}()
+
+		request := nextMessage()
+		assert.Equal(t, "x/slow", request["method"])
+		select {
+		case err := <-errs:
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		case <-time.After(time.Second):
+			t.Fatal("request did not time out")
+		}
+
+		// The client is told that the response is no longer awaited.
+		cancelled := nextMessage()
+		assert.Equal(t, "notifications/cancelled", cancelled["method"])
+		params := cancelled["params"].(map[string]any)
+		assert.Equal(t, request["id"], params["requestId"])
+		assert.True(t, strings.Contains(params["reason"].(string), "deadline"))
+	})
+}
+
+// requestTestSession is a session that sends requests its client never
+// answers, and whose notification channel is not read until the test does.
+type requestTestSession struct {
+	notifications chan mcp.JSONRPCNotification
+	requests      clientRequests
+}
+
+func (s *requestTestSession) Initialize()       {}
+func (s *requestTestSession) Initialized() bool { return true }
+func (s *requestTestSession) SessionID() string { return "request-test" }
+func (s *requestTestSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *requestTestSession) SendRequest(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	return s.requests.send(ctx, s, method, params, func(context.Context, mcp.JSONRPCRequest) error {
+		return nil
+	})
+}
+
+func TestMCPServer_SendRequest_CancellationIsQueued(t *testing.T) {
+	mcpServer := NewMCPServer("test", "1.0.0",
+		WithClientRequestTimeout(10*time.Millisecond),
+		WithNotificationQueue(4),
+	)
+	session := &requestTestSession{notifications: make(chan mcp.JSONRPCNotification)}
+	require.NoError(t, mcpServer.RegisterSession(context.Background(), session))
+	defer mcpServer.UnregisterSession(context.Background(), session.SessionID())
+	ctx := context.WithValue(mcpServer.WithContext(context.Background(), session), serverKey{}, mcpServer)
+
+	_, err := mcpServer.SendRequest(ctx, "x/slow", nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The notification channel was not ready, so the cancellation waited in
+	// the queue instead of being dropped.
+	select {
+	case notification := <-session.notifications:
+		assert.Equal(t, "notifications/cancelled", notification.Method)
+	case <-time.After(time.Second):
+		t.Fatal("cancellation was dropped")
+	}
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CustomRequest is a request with a method registered with AddRequestHandler.
+type CustomRequest struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Header http.Header     `json:"-"`
+}
+
+// RequestHandlerFunc handles requests with a method registered with
+// AddRequestHandler. The returned result is sent as the result of the
+// response, and an empty result is sent if it is nil.
+//
+// Errors wrapping mcp.ErrInvalidParams, mcp.ErrInvalidRequest,
+// mcp.ErrMethodNotFound or mcp.ErrResourceNotFound are sent with the
+// matching JSON-RPC error code, other errors as internal errors.
+type RequestHandlerFunc func(ctx context.Context, request CustomRequest) (any, error)
+
+// TypedRequestHandlerFunc handles requests with a method registered with
+// AddRequestHandler, with the params decoded into P.
+type TypedRequestHandlerFunc[P any, R any] func(ctx context.Context, request CustomRequest, params P) (R, error)
+
+// NewTypedRequestHandler creates a RequestHandlerFunc that decodes the params
+// of the request into P. Requests whose params cannot be decoded are answered
+// with an invalid params error.
+func NewTypedRequestHandler[P any, R any](handler TypedRequestHandlerFunc[P, R]) RequestHandlerFunc {
+	return func(ctx context.Context, request CustomRequest) (any, error) {
+		var params P
+		if len(request.Params) > 0 {
+			if err := json.Unmarshal(request.Params, &params); err != nil {
+				return nil, fmt.Errorf("%w: %v", mcp.ErrInvalidParams, err)
+			}
+		}
+		return handler(ctx, request, params)
+	}
+}
+
+// WithExperimentalCapability advertises the experimental capability name
+// with the given configuration during initialization.
+func WithExperimentalCapability(name string, config any) ServerOption {
+	return func(s *MCPServer) {
+		if s.capabilities.experimental == nil {
+			s.capabilities.experimental = make(map[string]any)
+		}
+		s.capabilities.experimental[name] = config
+	}
+}
+
+// AddRequestHandler registers handler for requests with the given method,
+// such as experimental or vendor specific methods. The method is advertised
+// as an experimental capability unless one with its name was configured with
+// WithExperimentalCapability. Methods of the protocol are always handled by
+// the server and cannot be overridden: AddRequestHandler panics if method is
+// one of them.
+//
+// Custom requests go through the message handler middlewares and call the
+// OnBeforeAny, OnSuccess and OnError hooks like the built-in methods.
+func (s *MCPServer) AddRequestHandler(method string, handler RequestHandlerFunc) {
+	if isProtocolMethod(mcp.MCPMethod(method)) {
+		panic(fmt.Sprintf("method '%s' is a protocol method and cannot be overridden", method))
+	}
+
+	s.requestHandlersMu.Lock()
+	s.requestHandlers[method] = handler
+	s.requestHandlersMu.Unlock()
+
+	s.capabilitiesMu.Lock()
+	defer s.capabilitiesMu.Unlock()
+	if s.capabilities.experimental == nil {
+		s.capabilities.experimental = make(map[string]any)
+	}
+	if _, ok := s.capabilities.experimental[method]; !ok {
+		s.capabilities.experimental[method] = map[string]any{}
+	}
+}
+
+// clientRequestMethods are the request methods the protocol defines for
+// requests from the server to the client.
+var clientRequestMethods = []mcp.MCPMethod{
+	mcp.MethodSamplingCreateMessage,
+	mcp.MethodElicitationCreate,
+	mcp.MethodListRoots,
+}
+
+// isProtocolMethod reports whether method is a request method defined by the
+// protocol, which cannot be registered with AddRequestHandler. The methods
+// handled by the server come from the generated requestMethods, so they stay
+// in step with handleMessage.
+func isProtocolMethod(method mcp.MCPMethod) bool {
+	if _, ok := requestMethods[method]; ok {
+		return true
+	}
+	return slices.Contains(clientRequestMethods, method)
+}
+
+// requestHandler returns the handler registered for method, if any.
+func (s *MCPServer) requestHandler(method mcp.MCPMethod) (RequestHandlerFunc, bool) {
+	s.requestHandlersMu.RLock()
+	defer s.requestHandlersMu.RUnlock()
+	handler, ok := s.requestHandlers[string(method)]
+	return handler, ok
+}
+
+// experimentalCapabilities returns a copy of the experimental capabilities
+// to advertise, or nil if there are none.
+func (s *MCPServer) experimentalCapabilities() map[string]any {
+	s.capabilitiesMu.RLock()
+	defer s.capabilitiesMu.RUnlock()
+	if len(s.capabilities.experimental) == 0 {
+		return nil
+	}
+	experimental := make(map[string]any, len(s.capabilities.experimental))
+	for name, config := range s.capabilities.experimental {
+		experimental[name] = config
+	}
+	return experimental
+}
+
+// handleCustomRequest handles a request with a method registered with
+// AddRequestHandler.
+func (s *MCPServer) handleCustomRequest(
+	ctx context.Context,
+	id any,
+	method mcp.MCPMethod,
+	message json.RawMessage,
+	headers http.Header,
+	handler RequestHandlerFunc,
+) mcp.JSONRPCMessage {
+	var request CustomRequest
+	if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
+		err := &requestError{
+			id:   id,
+			code: mcp.INVALID_REQUEST,
+			err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: method},
+		}
+		s.hooks.onError(ctx, id, method, &request, err)
+		return err.ToJSONRPCError()
+	}
+	request.Header = headers
+
+	s.hooks.beforeAny(ctx, id, method, &request)
+	result, err := handler(ctx, request)
+	if err != nil {
+		err := &requestError{
+			id:   id,
+			code: customRequestErrorCode(err),
+			err:  err,
+		}
+		s.hooks.onError(ctx, id, method, &request, err)
+		return err.ToJSONRPCError()
+	}
+	if result == nil {
+		result = mcp.EmptyResult{}
+	}
+	s.hooks.onSuccess(ctx, id, method, &request, result)
+	return createResponse(id, result)
+}
+
+// customRequestErrorCode returns the JSON-RPC error code for an error
+// returned by a RequestHandlerFunc.
+func customRequestErrorCode(err error) int {
+	switch {
+	case errors.Is(err, mcp.ErrInvalidParams):
+		return mcp.INVALID_PARAMS
+	case errors.Is(err, mcp.ErrInvalidRequest):
+		return mcp.INVALID_REQUEST
+	case errors.Is(err, mcp.ErrMethodNotFound):
+		return mcp.METHOD_NOT_FOUND
+	case errors.Is(err, mcp.ErrResourceNotFound), errors.Is(err, ErrResourceNotFound):
+		return mcp.RESOURCE_NOT_FOUND
+	default:
+		return mcp.INTERNAL_ERROR
+	}
+}
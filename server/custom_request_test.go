@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPServer_AddRequestHandler(t *testing.T) {
+	var (
+		middlewareMethods []string
+		beforeMethods     []mcp.MCPMethod
+		successMethods    []mcp.MCPMethod
+		errorCodes        []int
+	)
+	hooks := &Hooks{}
+	hooks.AddBeforeAny(func(ctx context.Context, id any, method mcp.MCPMethod, message any) {
+		beforeMethods = append(beforeMethods, method)
+	})
+	hooks.AddOnSuccess(func(ctx context.Context, id any, method mcp.MCPMethod, message any, result any) {
+		successMethods = append(successMethods, method)
+	})
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		var reqErr *requestError
+		require.ErrorAs(t, err, &reqErr)
+		errorCodes = append(errorCodes, reqErr.code)
+	})
+	server := NewMCPServer("test", "1.0.0",
+		WithHooks(hooks),
+		WithExperimentalCapability("acme/search", map[string]any{"version": 2}),
+		WithMessageHandlerMiddleware(func(next MessageHandlerFunc) MessageHandlerFunc {
+			return func(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage {
+				var request struct {
+					Method string `json:"method"`
+				}
+				_ = json.Unmarshal(message, &request)
+				middlewareMethods = append(middlewareMethods, request.Method)
+				return next(ctx, message)
+			}
+		}),
+	)
+
+	type searchParams struct {
+		Query string `json:"query"`
+	}
+	type searchResult struct {
+		Hits []string `json:"hits"`
+	}
+	server.AddRequestHandler("acme/search", NewTypedRequestHandler(
+		func(ctx context.Context, request CustomRequest, params searchParams) (searchResult, error) {
+			if params.Query == "" {
+				return searchResult{}, fmt.Errorf("%w: query is required", mcp.ErrInvalidParams)
+			}
+			return searchResult{Hits: []string{params.Query}}, nil
+		}))
+	server.AddRequestHandler("acme/reset", func(ctx context.Context, request CustomRequest) (any, error) {
+		return nil, nil
+	})
+	server.AddRequestHandler("acme/fail", func(ctx context.Context, request CustomRequest) (any, error) {
+		return nil, errors.New("boom")
+	})
+
+	send := func(method, params string) string {
+		t.Helper()
+		message := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":%q`, method)
+		if params != "" {
+			message += `,"params":` + params
+		}
+		response, err := json.Marshal(server.HandleMessage(context.Background(), []byte(message+"}")))
+		require.NoError(t, err)
+		return string(response)
+	}
+
+	t.Run("capabilities", func(t *testing.T) {
+		response := server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`))
+		result := response.(mcp.JSONRPCResponse).Result.(mcp.InitializeResult)
+		// The configured capability is kept, registered methods are added.
+		assert.Equal(t, map[string]any{
+			"acme/search": map[string]any{"version": 2},
+			"acme/reset":  map[string]any{},
+			"acme/fail":   map[string]any{},
+		}, result.Capabilities.Experimental)
+	})
+
+	t.Run("result", func(t *testing.T) {
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{"hits":["mcp"]}}`, send("acme/search", `{"query":"mcp"}`))
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{}}`, send("acme/reset", ""))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		errorCodes = nil
+		assert.Contains(t, send("acme/search", `{"query":""}`), fmt.Sprintf(`"code":%d`, mcp.INVALID_PARAMS))
+		assert.Contains(t, send("acme/search", `{"query":1}`), fmt.Sprintf(`"code":%d`, mcp.INVALID_PARAMS))
+		assert.Contains(t, send("acme/fail", ""), fmt.Sprintf(`"code":%d`, mcp.INTERNAL_ERROR))
+		assert.Contains(t, send("acme/unknown", ""), fmt.Sprintf(`"code":%d`, mcp.METHOD_NOT_FOUND))
+		assert.Equal(t, []int{mcp.INVALID_PARAMS, mcp.INVALID_PARAMS, mcp.INTERNAL_ERROR}, errorCodes)
+	})
+
+	t.Run("hooks and middleware", func(t *testing.T) {
+		middlewareMethods, beforeMethods, successMethods = nil, nil, nil
+		send("acme/reset", "")
+		assert.Equal(t, []string{"acme/reset"}, middlewareMethods)
+		assert.Equal(t, []mcp.MCPMethod{"acme/reset"}, beforeMethods)
+		assert.Equal(t, []mcp.MCPMethod{"acme/reset"}, successMethods)
+	})
+
+	t.Run("built-in methods cannot be overridden", func(t *testing.T) {
+		for _, method := range []mcp.MCPMethod{mcp.MethodPing, mcp.MethodToolsList, mcp.MethodSamplingCreateMessage} {
+			assert.Panics(t, func() {
+				server.AddRequestHandler(string(method), func(ctx context.Context, request CustomRequest) (any, error) {
+					return map[string]any{"custom": true}, nil
+				})
+			}, method)
+		}
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{}}`, send(string(mcp.MethodPing), ""))
+		response := server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`))
+		result := response.(mcp.JSONRPCResponse).Result.(mcp.InitializeResult)
+		assert.NotContains(t, result.Capabilities.Experimental, string(mcp.MethodPing))
+	})
+}
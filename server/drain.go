@@ -0,0 +1,221 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// drainAllowedMethods are the requests still handled while the server
+// drains: they do not start new work, and let clients follow the tasks that
+// are finishing.
+var drainAllowedMethods = map[mcp.MCPMethod]bool{
+	mcp.MethodPing:        true,
+	mcp.MethodTasksGet:    true,
+	mcp.MethodTasksList:   true,
+	mcp.MethodTasksResult: true,
+	mcp.MethodTasksCancel: true,
+}
+
+// requestTracker counts the requests being handled and cancels them when a
+// drain runs out of time.
+type requestTracker struct {
+	mu       sync.Mutex
+	active   int
+	draining bool
+	// idle is closed when active drops to zero during a drain.
+	idle chan struct{}
+
+	stopCtx context.Context
+	stop    context.CancelFunc
+}
+
+func newRequestTracker() *requestTracker {
+	stopCtx, stop := context.WithCancel(context.Background())
+	return &requestTracker{stopCtx: stopCtx, stop: stop}
+}
+
+// begin registers a request. It returns a context that is cancelled when
+// the drain times out, and a function to call when the request is done. It
+// reports false once the server drains.
+func (t *requestTracker) begin(ctx context.Context) (context.Context, func(), bool) {
+	t.mu.Lock()
+	if t.draining {
+		t.mu.Unlock()
+		return ctx, func() {}, false
+	}
+	t.active++
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	request := &trackedRequest{
+		cancel:     cancel,
+		stopCancel: context.AfterFunc(t.stopCtx, cancel),
+	}
+	request.refs.Store(1)
+	ctx = context.WithValue(ctx, trackedRequestKey{}, request)
+	return ctx, func() {
+		request.release()
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.active--
+		if t.active == 0 && t.idle != nil {
+			close(t.idle)
+			t.idle = nil
+		}
+	}, true
+}
+
+// trackedRequest is the context of a request being handled. The context is
+// cancelled once the request and the work that outlives it are done.
+type trackedRequest struct {
+	refs       atomic.Int32
+	cancel     context.CancelFunc
+	stopCancel func() bool
+}
+
+func (r *trackedRequest) release() {
+	if r.refs.Add(-1) == 0 {
+		r.stopCancel()
+		r.cancel()
+	}
+}
+
+type trackedRequestKey struct{}
+
+// retainRequestContext keeps the request context in ctx from being
+// cancelled when the request is done, until the returned function is
+// called. It is used by work that outlives its request, such as tasks.
+func retainRequestContext(ctx context.Context) func() {
+	request, ok := ctx.Value(trackedRequestKey{}).(*trackedRequest)
+	if !ok {
+		return func() {}
+	}
+	request.refs.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(request.release)
+	}
+}
+
+// drain stops new requests and returns a channel that is closed once the
+// requests being handled are done.
+func (t *requestTracker) drain() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.draining = true
+	if t.active == 0 {
+		idle := make(chan struct{})
+		close(idle)
+		return idle
+	}
+	if t.idle == nil {
+		t.idle = make(chan struct{})
+	}
+	return t.idle
+}
+
+func (t *requestTracker) isDraining() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.draining
+}
+
+// IsShuttingDown reports whether Shutdown has been called.
+func (s *MCPServer) IsShuttingDown() bool {
+	return s.requests.isDraining()
+}
+
+// Shutdown drains the server. It stops accepting new requests, which are
+// answered with an ErrServerShuttingDown error, and waits for the requests
+// being handled and the running tasks to finish. Ping and the tasks/*
+// requests are still served, so that clients can follow their tasks.
+// Responses to requests the server sent, such as sampling, and
+// notifications are still processed.
+//
+// If ctx is done first, the contexts of the remaining requests are
+// cancelled, the running tasks are cancelled and marked failed, and the
+// error of ctx is returned. Task hooks are called for those tasks, so they
+// can be used to persist task state.
+//
+// The transports' Shutdown methods call Shutdown before closing their
+// streams, so it only needs to be called directly when the MCPServer is
+// served some other way. Shutdown cannot be undone.
+func (s *MCPServer) Shutdown(ctx context.Context) error {
+	if s.liveness != nil {
+		s.liveness.close()
+	}
+	idle := s.requests.drain()
+	for {
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			s.abortDrain()
+			return ctx.Err()
+		}
+
+		running := s.runningTasks()
+		if len(running) == 0 {
+			return nil
+		}
+		for _, entry := range running {
+			select {
+			case <-entry.done:
+			case <-ctx.Done():
+				s.abortDrain()
+				return ctx.Err()
+			}
+		}
+		// Tasks may have been started by the requests that just finished.
+		idle = s.requests.drain()
+	}
+}
+
+// abortDrain cancels the requests still being handled and fails the
+// running tasks.
+func (s *MCPServer) abortDrain() {
+	s.requests.stop()
+	for _, entry := range s.runningTasks() {
+		s.tasksMu.RLock()
+		cancel := entry.cancelFunc
+		s.tasksMu.RUnlock()
+		if cancel != nil {
+			cancel()
+		}
+		s.completeTask(entry, nil, ErrServerShuttingDown)
+	}
+}
+
+// runningTasks returns the tasks that have not reached a terminal status.
+func (s *MCPServer) runningTasks() []*taskEntry {
+	s.tasksMu.RLock()
+	defer s.tasksMu.RUnlock()
+	var running []*taskEntry
+	for _, entry := range s.tasks {
+		if !entry.completed {
+			running = append(running, entry)
+		}
+	}
+	return running
+}
+
+// rejectWhileDraining returns the response to message while the server
+// drains, or nil if the message is still handled.
+func rejectWhileDraining(message json.RawMessage) mcp.JSONRPCMessage {
+	var baseMessage struct {
+		ID     any           `json:"id,omitempty"`
+		Method mcp.MCPMethod `json:"method"`
+	}
+	if json.Unmarshal(message, &baseMessage) != nil || baseMessage.ID == nil || baseMessage.Method == "" {
+		// Notifications, responses and unparsable messages are handled as
+		// usual.
+		return nil
+	}
+	if drainAllowedMethods[baseMessage.Method] {
+		return nil
+	}
+	return createErrorResponse(baseMessage.ID, mcp.INTERNAL_ERROR, ErrServerShuttingDown.Error())
+}
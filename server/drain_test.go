@@ -0,0 +1,271 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// blockingToolServer returns a server with a "block" tool that waits until
+// release is closed or its context is cancelled, reporting which happened.
+func blockingToolServer(started chan<- struct{}, release <-chan struct{}, cancelled chan<- struct{}) *MCPServer {
+	s := NewMCPServer("drain-test", "1.0.0",
+		WithToolCapabilities(false),
+		WithTaskCapabilities(true, true, true),
+	)
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		started <- struct{}{}
+		select {
+		case <-release:
+			return mcp.NewToolResultText("done"), nil
+		case <-ctx.Done():
+			cancelled <- struct{}{}
+			return nil, ctx.Err()
+		}
+	}
+	s.AddTool(mcp.NewTool("block", mcp.WithTaskSupport(mcp.TaskSupportOptional)), handler)
+	return s
+}
+
+func responseError(t *testing.T, response mcp.JSONRPCMessage) *mcp.JSONRPCError {
+	t.Helper()
+	errResponse, ok := response.(mcp.JSONRPCError)
+	if !ok {
+		return nil
+	}
+	return &errResponse
+}
+
+func TestMCPServer_Shutdown_WaitsForInflightRequests(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	cancelled := make(chan struct{}, 1)
+	s := blockingToolServer(started, release, cancelled)
+	ctx := context.Background()
+
+	responses := make(chan mcp.JSONRPCMessage, 1)
+	go func() {
+		responses <- s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block"}}`))
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- s.Shutdown(context.Background())
+	}()
+	require.Eventually(t, s.IsShuttingDown, time.Second, 5*time.Millisecond)
+
+	// New requests are rejected, ping and notifications are still handled.
+	rejected := responseError(t, s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)))
+	require.NotNil(t, rejected)
+	assert.Equal(t, ErrServerShuttingDown.Error(), rejected.Error.Message)
+	assert.Nil(t, responseError(t, s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":3,"method":"ping"}`))))
+	assert.Nil(t, s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)))
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned before the request finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-shutdown)
+	response := <-responses
+	assert.Nil(t, responseError(t, response))
+}
+
+func TestMCPServer_Shutdown_CancelsAtDeadline(t *testing.T) {
+	started := make(chan struct{}, 2)
+	cancelled := make(chan struct{}, 2)
+	s := blockingToolServer(started, make(chan struct{}), cancelled)
+	ctx := context.Background()
+
+	responses := make(chan mcp.JSONRPCMessage, 1)
+	go func() {
+		responses <- s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block"}}`))
+	}()
+	<-started
+
+	taskResponse := s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"block","task":{}}}`))
+	createTask, ok := taskResponse.(mcp.JSONRPCResponse)
+	require.True(t, ok, "%+v", taskResponse)
+	taskID := createTask.Result.(*mcp.CreateTaskResult).Task.TaskId
+	<-started
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Shutdown(shutdownCtx), context.DeadlineExceeded)
+
+	for range 2 {
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("handler was not cancelled")
+		}
+	}
+	<-responses
+
+	task, _, err := s.getTask(ctx, taskID)
+	require.NoError(t, err)
+	assert.Equal(t, mcp.TaskStatusFailed, task.Status)
+	assert.Equal(t, ErrServerShuttingDown.Error(), task.StatusMessage)
+}
+
+func TestMCPServer_Shutdown_WaitsForTasks(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	cancelled := make(chan struct{}, 1)
+	s := blockingToolServer(started, release, cancelled)
+	ctx := context.Background()
+
+	taskResponse := s.HandleMessage(ctx, json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block","task":{}}}`))
+	taskID := taskResponse.(mcp.JSONRPCResponse).Result.(*mcp.CreateTaskResult).Task.TaskId
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- s.Shutdown(context.Background())
+	}()
+
+	// The task survives the request that created it, and can be followed
+	// while the server drains.
+	require.Eventually(t, s.IsShuttingDown, time.Second, 5*time.Millisecond)
+	get := `{"jsonrpc":"2.0","id":2,"method":"tasks/get","params":{"taskId":"` + taskID + `"}}`
+	assert.Nil(t, responseError(t, s.HandleMessage(ctx, json.RawMessage(get))))
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned before the task finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-shutdown)
+	task, _, err := s.getTask(ctx, taskID)
+	require.NoError(t, err)
+	assert.Equal(t, mcp.TaskStatusCompleted, task.Status)
+}
+
+func TestStreamableHTTPServer_ShutdownDrains(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	cancelled := make(chan struct{}, 1)
+	mcpServer := blockingToolServer(started, release, cancelled)
+	httpServer := NewStreamableHTTPServer(mcpServer, WithStateful(true))
+	server := httptest.NewServer(httpServer)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(HeaderKeySessionID)
+	require.NotEmpty(t, sessionID)
+
+	post := func(body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(HeaderKeySessionID, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	streamReq, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	streamReq.Header.Set(HeaderKeySessionID, sessionID)
+	stream, err := http.DefaultClient.Do(streamReq)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+
+	inflight := make(chan string, 1)
+	go func() {
+		resp := post(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"block"}}`)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		inflight <- string(body)
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- httpServer.Shutdown(context.Background())
+	}()
+	require.Eventually(t, mcpServer.IsShuttingDown, time.Second, 5*time.Millisecond)
+
+	resp = post(`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Contains(t, string(body), ErrServerShuttingDown.Error())
+
+	close(release)
+	assert.Contains(t, <-inflight, `"done"`)
+	require.NoError(t, <-shutdown)
+
+	// The listening stream is closed.
+	_, err = io.ReadAll(stream.Body)
+	require.NoError(t, err)
+}
+
+func TestStdioServer_Shutdown(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	cancelled := make(chan struct{}, 1)
+	stdioServer := NewStdioServer(blockingToolServer(started, release, cancelled))
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	defer stdinWriter.Close()
+	output := make(chan string, 10)
+	go func() {
+		decoder := json.NewDecoder(stdoutReader)
+		for {
+			var message json.RawMessage
+			if decoder.Decode(&message) != nil {
+				return
+			}
+			output <- string(message)
+		}
+	}()
+
+	listen := make(chan error, 1)
+	go func() {
+		listen <- stdioServer.Listen(context.Background(), stdinReader, stdoutWriter)
+	}()
+
+	_, err := io.WriteString(stdinWriter, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block"}}`+"\n")
+	require.NoError(t, err)
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- stdioServer.Shutdown(context.Background())
+	}()
+	require.Eventually(t, stdioServer.server.IsShuttingDown, time.Second, 5*time.Millisecond)
+
+	_, err = io.WriteString(stdinWriter, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`+"\n")
+	require.NoError(t, err)
+	assert.Contains(t, <-output, ErrServerShuttingDown.Error())
+
+	close(release)
+	assert.Contains(t, <-output, `"done"`)
+	require.NoError(t, <-shutdown)
+
+	select {
+	case err := <-listen:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Listen did not return after Shutdown")
+	}
+}
@@ -2,7 +2,9 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -12,12 +14,23 @@ var (
 	ErrNoActiveSession = errors.New("no active session")
 	// ErrElicitationNotSupported is returned when the session does not support elicitation
 	ErrElicitationNotSupported = errors.New("session does not support elicitation")
+	// ErrURLElicitationNotSupported is returned when the client negotiated a protocol version without URL mode elicitation
+	ErrURLElicitationNotSupported = errors.New("client protocol version does not support URL mode elicitation")
+	// ErrElicitationDeclined is returned by Elicit when the user explicitly declined the request
+	ErrElicitationDeclined = errors.New("elicitation declined by user")
+	// ErrElicitationCancelled is returned by Elicit when the user dismissed the request without choosing
+	ErrElicitationCancelled = errors.New("elicitation cancelled by user")
 )
 
 // RequestElicitation sends an elicitation request to the client.
 // The client must have declared elicitation capability during initialization.
 // The session must implement SessionWithElicitation to support this operation.
 func (s *MCPServer) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	return requestElicitation(ctx, request)
+}
+
+// requestElicitation sends an elicitation request through the session stored in ctx.
+func requestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
 	session := ClientSessionFromContext(ctx)
 	if session == nil {
 		return nil, ErrNoActiveSession
@@ -28,12 +41,28 @@ func (s *MCPServer) RequestElicitation(ctx context.Context, request mcp.Elicitat
 		if err := request.Params.Validate(); err != nil {
 			return nil, err
 		}
+		if err := checkElicitationMode(session, request.Params.Mode); err != nil {
+			return nil, err
+		}
 		return elicitationSession.RequestElicitation(ctx, request)
 	}
 
 	return nil, ErrElicitationNotSupported
 }
 
+// checkElicitationMode returns an error if the client of session negotiated a
+// protocol version that predates the elicitation mode. Sessions that do not
+// track the protocol version are not checked.
+func checkElicitationMode(session ClientSession, mode string) error {
+	if mode != mcp.ElicitationModeURL {
+		return nil
+	}
+	if _, ok := session.(SessionWithProtocolVersion); ok && !protocolVersionAtLeast(sessionProtocolVersion(session), protocolVersionTasks) {
+		return ErrURLElicitationNotSupported
+	}
+	return nil
+}
+
 // RequestURLElicitation sends a URL mode elicitation request to the client.
 // This is used when the server needs the user to perform an out-of-band interaction.
 func (s *MCPServer) RequestURLElicitation(
@@ -57,6 +86,9 @@ func (s *MCPServer) RequestURLElicitation(
 	if err := params.Validate(); err != nil {
 		return nil, err
 	}
+	if err := checkElicitationMode(session, params.Mode); err != nil {
+		return nil, err
+	}
 
 	request := mcp.ElicitationRequest{
 		Request: mcp.Request{
@@ -85,3 +117,64 @@ func (s *MCPServer) SendElicitationComplete(
 	jsonRPCNotif := mcp.NewElicitationCompleteNotification(elicitationID)
 	return s.sendNotificationCore(ctx, session, jsonRPCNotif)
 }
+
+// Elicit sends a form mode elicitation request whose requestedSchema is
+// generated from the struct type T (see mcp.NewElicitationSchema) and decodes
+// the accepted content into a T.
+//
+// The content returned by the client is validated against the generated schema
+// before decoding. If the user declines or cancels, ErrElicitationDeclined or
+// ErrElicitationCancelled is returned. If T cannot be expressed as a flat
+// elicitation schema, Elicit returns an error when it is called, without
+// sending a request; the type is not checked at compile time.
+func Elicit[T any](ctx context.Context, message string) (*T, error) {
+	schema, err := mcp.NewElicitationSchema[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	request := mcp.ElicitationRequest{
+		Request: mcp.Request{
+			Method: string(mcp.MethodElicitationCreate),
+		},
+		Params: mcp.ElicitationParams{
+			Message:         message,
+			RequestedSchema: schema,
+		},
+	}
+
+	result, err := requestElicitation(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("empty elicitation result")
+	}
+
+	switch result.Action {
+	case mcp.ElicitationResponseActionAccept:
+	case mcp.ElicitationResponseActionDecline:
+		return nil, ErrElicitationDeclined
+	case mcp.ElicitationResponseActionCancel:
+		return nil, ErrElicitationCancelled
+	default:
+		return nil, fmt.Errorf("unknown elicitation response action: %q", result.Action)
+	}
+
+	if err := schema.Validate(result.Content); err != nil {
+		return nil, fmt.Errorf("elicitation content does not match requested schema: %w", err)
+	}
+
+	var value T
+	if result.Content == nil {
+		return &value, nil
+	}
+	data, err := json.Marshal(result.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal elicitation content: %w", err)
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode elicitation content: %w", err)
+	}
+	return &value, nil
+}
@@ -309,3 +309,103 @@ func TestSendElicitationComplete_NoPriorRequest(t *testing.T) {
 		t.Fatal("Expected notification was not received")
 	}
 }
+
+type elicitProjectInfo struct {
+	Name      string `json:"name" jsonschema:"title=Project name"`
+	Framework string `json:"framework,omitempty" jsonschema:"enum=react,enum=vue"`
+	Port      int    `json:"port,omitempty"`
+}
+
+func TestElicit(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *mcp.ElicitationResult
+		wantErr error
+		want    *elicitProjectInfo
+	}{
+		{
+			name: "accepted",
+			result: &mcp.ElicitationResult{
+				ElicitationResponse: mcp.ElicitationResponse{
+					Action:  mcp.ElicitationResponseActionAccept,
+					Content: map[string]any{"name": "my-project", "framework": "react", "port": float64(8080)},
+				},
+			},
+			want: &elicitProjectInfo{Name: "my-project", Framework: "react", Port: 8080},
+		},
+		{
+			name: "declined",
+			result: &mcp.ElicitationResult{
+				ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionDecline},
+			},
+			wantErr: ErrElicitationDeclined,
+		},
+		{
+			name: "cancelled",
+			result: &mcp.ElicitationResult{
+				ElicitationResponse: mcp.ElicitationResponse{Action: mcp.ElicitationResponseActionCancel},
+			},
+			wantErr: ErrElicitationCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewMCPServer("test", "1.0.0", WithElicitation())
+			mockSession := &mockElicitationSession{sessionID: "test-session", result: tt.result}
+			ctx := server.WithContext(context.Background(), mockSession)
+
+			got, err := Elicit[elicitProjectInfo](ctx, "Tell me about your project")
+
+			// The generated schema is sent with the request
+			schema, ok := mockSession.lastRequest.Params.RequestedSchema.(*mcp.ElicitationSchema)
+			require.True(t, ok, "expected generated schema, got %T", mockSession.lastRequest.Params.RequestedSchema)
+			assert.Equal(t, []string{"name"}, schema.Required)
+			assert.Equal(t, "Project name", schema.Properties["name"].Title)
+			assert.Equal(t, "Tell me about your project", mockSession.lastRequest.Params.Message)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestElicit_InvalidContent(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", WithElicitation())
+	mockSession := &mockElicitationSession{
+		sessionID: "test-session",
+		result: &mcp.ElicitationResult{
+			ElicitationResponse: mcp.ElicitationResponse{
+				Action:  mcp.ElicitationResponseActionAccept,
+				Content: map[string]any{"name": "my-project", "framework": "angular"},
+			},
+		},
+	}
+	ctx := server.WithContext(context.Background(), mockSession)
+
+	got, err := Elicit[elicitProjectInfo](ctx, "Tell me about your project")
+	require.Error(t, err)
+	assert.Nil(t, got)
+}
+
+func TestElicit_RejectsNestedTypes(t *testing.T) {
+	type nested struct {
+		Owner struct {
+			Name string `json:"name"`
+		} `json:"owner"`
+	}
+
+	server := NewMCPServer("test", "1.0.0", WithElicitation())
+	mockSession := &mockElicitationSession{sessionID: "test-session"}
+	ctx := server.WithContext(context.Background(), mockSession)
+
+	_, err := Elicit[nested](ctx, "Who owns this?")
+	require.Error(t, err)
+	// No request is sent for types that cannot be expressed as an elicitation schema
+	assert.Empty(t, mockSession.lastRequest.Params.Message)
+}
@@ -12,6 +12,13 @@ var (
 	ErrPromptNotFound   = errors.New("prompt not found")
 	ErrToolNotFound     = errors.New("tool not found")
 
+	// ErrServerShuttingDown is returned for requests received after Shutdown
+	ErrServerShuttingDown = errors.New("server is shutting down")
+
+	// Pagination-related errors
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
+	ErrStaleCursor   = errors.New("pagination cursor is stale: the list changed, restart from the first page")
+
 	// Session-related errors
 	ErrSessionNotFound                        = errors.New("session not found")
 	ErrSessionExists                          = errors.New("session already exists")
@@ -20,6 +27,8 @@ var (
 	ErrSessionDoesNotSupportResources         = errors.New("session does not support per-session resources")
 	ErrSessionDoesNotSupportResourceTemplates = errors.New("session does not support resource templates")
 	ErrSessionDoesNotSupportLogging           = errors.New("session does not support setting logging level")
+	ErrSessionDoesNotSupportPing              = errors.New("session does not support ping")
+	ErrSessionTerminated                      = errors.New("session terminated")
 
 	// Notification-related errors
 	ErrNotificationNotInitialized = errors.New("notification channel not initialized")
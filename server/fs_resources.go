@@ -0,0 +1,426 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultFileSystemMaxFileSize is the size of the largest file read by a
+// FileSystemProvider unless WithFileSystemMaxFileSize is given.
+const defaultFileSystemMaxFileSize = 10 << 20
+
+// ErrFileTooLarge is returned when reading a file larger than the maximum
+// size of a FileSystemProvider.
+var ErrFileTooLarge = errors.New("file is too large")
+
+// FileSystemProvider serves the files of a directory tree as resources.
+//
+// Each regular file is registered as a resource with a file:// URI. Its name
+// is the base name of the root followed by the path of the file below it,
+// such as docs/guides/intro.md. A template below the URI of the root, such
+// as file:///srv/docs/{+path}, serves files created after the last scan. The
+// MIME type is derived from the file extension, or sniffed from the content
+// when the extension is unknown. Text files are read as
+// mcp.TextResourceContents and other files as mcp.BlobResourceContents.
+//
+// Large trees are paginated like other resources, see WithPaginationLimit.
+type FileSystemProvider struct {
+	root         string
+	rootURI      string
+	include      []string
+	exclude      []string
+	pollInterval time.Duration
+	maxFileSize  int64
+
+	server *MCPServer
+	// files holds the state of the listed files by resource URI. It is only
+	// used by Register and the watch goroutine.
+	files    map[string]fileState
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// fileState is the state of a file used to detect changes.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// FileSystemProviderOption configures a FileSystemProvider.
+type FileSystemProviderOption func(*FileSystemProvider)
+
+// WithFileSystemInclude serves only the files matching one of patterns.
+// Patterns use the path.Match syntax and are matched against the slash
+// separated path relative to the root. Patterns without a slash are also
+// matched against the file name, so "*.md" matches Markdown files in every
+// directory.
+func WithFileSystemInclude(patterns ...string) FileSystemProviderOption {
+	return func(p *FileSystemProvider) {
+		p.include = append(p.include, patterns...)
+	}
+}
+
+// WithFileSystemExclude does not serve the files and directories matching
+// one of patterns, which are matched like the patterns of
+// WithFileSystemInclude. Exclusions take precedence over inclusions.
+func WithFileSystemExclude(patterns ...string) FileSystemProviderOption {
+	return func(p *FileSystemProvider) {
+		p.exclude = append(p.exclude, patterns...)
+	}
+}
+
+// WithFileSystemWatch scans the tree for changes every interval once the
+// provider is registered. Added and removed files update the resources of
+// the server, which sends notifications/resources/list_changed if the
+// listChanged resource capability is enabled. Subscribers of a changed or
+// removed file are sent notifications/resources/updated, see
+// WithResourceCapabilities.
+func WithFileSystemWatch(interval time.Duration) FileSystemProviderOption {
+	return func(p *FileSystemProvider) {
+		p.pollInterval = interval
+	}
+}
+
+// WithFileSystemMaxFileSize sets the size in bytes of the largest file the
+// provider reads, 10 MiB by default. Reading a larger file fails with
+// ErrFileTooLarge. A size of zero or less removes the limit.
+func WithFileSystemMaxFileSize(size int64) FileSystemProviderOption {
+	return func(p *FileSystemProvider) {
+		p.maxFileSize = size
+	}
+}
+
+// NewFileSystemProvider creates a provider serving the directory tree at
+// root. It returns an error if root is not a directory or a pattern is
+// malformed.
+func NewFileSystemProvider(root string, opts ...FileSystemProviderOption) (*FileSystemProvider, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	p := &FileSystemProvider{
+		root:        resolved,
+		rootURI:     fileURI(resolved),
+		maxFileSize: defaultFileSystemMaxFileSize,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	for _, pattern := range append(append([]string(nil), p.include...), p.exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return p, nil
+}
+
+// Register adds the files and the template of the root to s, and starts
+// watching the tree if WithFileSystemWatch was given. It must be called
+// once.
+func (p *FileSystemProvider) Register(s *MCPServer) error {
+	files, err := p.scan()
+	if err != nil {
+		return err
+	}
+	p.server = s
+	p.files = files
+
+	resources := make([]ServerResource, 0, len(files))
+	for uri := range files {
+		resources = append(resources, p.resource(uri))
+	}
+	s.AddResources(resources...)
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(p.template(), filepath.Base(p.root),
+			mcp.WithTemplateDescription(fmt.Sprintf("Files in %s", p.root)),
+		),
+		p.read,
+	)
+
+	if p.pollInterval > 0 {
+		go p.watch()
+	} else {
+		close(p.done)
+	}
+	return nil
+}
+
+// Close stops watching the tree.
+func (p *FileSystemProvider) Close() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	if p.server != nil {
+		<-p.done
+	}
+}
+
+func (p *FileSystemProvider) watch() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.update()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// update scans the tree and applies the changes since the last scan.
+func (p *FileSystemProvider) update() {
+	files, err := p.scan()
+	if err != nil {
+		return
+	}
+
+	var added []ServerResource
+	var removed, changed []string
+	for uri, state := range files {
+		previous, ok := p.files[uri]
+		switch {
+		case !ok:
+			added = append(added, p.resource(uri))
+		case previous != state:
+			changed = append(changed, uri)
+		}
+	}
+	for uri := range p.files {
+		if _, ok := files[uri]; !ok {
+			removed = append(removed, uri)
+		}
+	}
+	p.files = files
+
+	if len(removed) > 0 {
+		p.server.DeleteResources(removed...)
+	}
+	if len(added) > 0 {
+		p.server.AddResources(added...)
+	}
+	for _, uri := range append(changed, removed...) {
+		p.server.NotifyResourceUpdated(uri)
+	}
+}
+
+// scan returns the state of the files to serve by resource URI.
+func (p *FileSystemProvider) scan() (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	err := filepath.WalkDir(p.root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Files removed during the scan are skipped.
+			if errors.Is(err, fs.ErrNotExist) && filePath != p.root {
+				return nil
+			}
+			return err
+		}
+		if filePath == p.root {
+			return nil
+		}
+		rel := p.relPath(filePath)
+		if matchesAny(p.exclude, rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || (len(p.include) > 0 && !matchesAny(p.include, rel)) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files[fileURI(filePath)] = fileState{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	return files, err
+}
+
+// template returns the URI template of the files below the root. Each
+// provider has its own template, so providers of different roots can be
+// registered with the same server.
+func (p *FileSystemProvider) template() string {
+	return strings.TrimSuffix(p.rootURI, "/") + "/{+path}"
+}
+
+// resource returns the resource of the file with the given URI.
+func (p *FileSystemProvider) resource(uri string) ServerResource {
+	filePath, _ := localPath(uri)
+	// The base name of the root tells apart the files of providers of
+	// different roots.
+	name := path.Join(filepath.Base(p.root), p.relPath(filePath))
+	var opts []mcp.ResourceOption
+	if mimeType := mime.TypeByExtension(filepath.Ext(filePath)); mimeType != "" {
+		opts = append(opts, mcp.WithMIMEType(mimeType))
+	}
+	return ServerResource{
+		Resource: mcp.NewResource(uri, name, opts...),
+		Handler:  p.read,
+	}
+}
+
+// read returns the contents of the file requested by request. The file must
+// lie inside the root, after resolving symlinks, and match the patterns.
+func (p *FileSystemProvider) read(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	uri := request.Params.URI
+	filePath, err := PathInRoots(uri, []mcp.Root{{URI: p.rootURI}})
+	if err != nil {
+		return nil, err
+	}
+	rel := p.relPath(filePath)
+	if matchesAny(p.exclude, rel) || (len(p.include) > 0 && !matchesAny(p.include, rel)) {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		if matchesAny(p.exclude, dir) {
+			return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+		}
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+		}
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%w: %s is not a file", ErrResourceNotFound, uri)
+	}
+	if p.maxFileSize > 0 && info.Size() > p.maxFileSize {
+		return nil, fmt.Errorf("%w: %s has %d bytes, the maximum is %d", ErrFileTooLarge, uri, info.Size(), p.maxFileSize)
+	}
+	data, err := p.readFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if p.maxFileSize > 0 && int64(len(data)) > p.maxFileSize {
+		// The file grew after it was stat'ed.
+		return nil, fmt.Errorf("%w: %s is larger than the maximum of %d bytes", ErrFileTooLarge, uri, p.maxFileSize)
+	}
+
+	mimeType := mime.TypeByExtension(filepath.Ext(filePath))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	if isTextContent(mimeType, data) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: mimeType,
+			Text:     string(data),
+		}}, nil
+	}
+	return []mcp.ResourceContents{mcp.BlobResourceContents{
+		URI:      uri,
+		MIMEType: mimeType,
+		Blob:     base64.StdEncoding.EncodeToString(data),
+	}}, nil
+}
+
+// readFile reads the file at filePath, up to one byte more than the maximum
+// file size.
+func (p *FileSystemProvider) readFile(filePath string) ([]byte, error) {
+	if p.maxFileSize <= 0 {
+		return os.ReadFile(filePath)
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, p.maxFileSize+1))
+}
+
+// relPath returns the slash separated path of filePath relative to the root.
+func (p *FileSystemProvider) relPath(filePath string) string {
+	rel, err := filepath.Rel(p.root, filePath)
+	if err != nil {
+		return filepath.ToSlash(filePath)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// fileURI returns the file:// URI of an absolute local path.
+func fileURI(filePath string) string {
+	slashPath := filepath.ToSlash(filePath)
+	if !strings.HasPrefix(slashPath, "/") {
+		// C:/dir on windows
+		slashPath = "/" + slashPath
+	}
+	return (&url.URL{Scheme: "file", Path: slashPath}).String()
+}
+
+// matchesAny reports whether the slash separated relative path rel matches
+// one of patterns, see WithFileSystemInclude.
+func matchesAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTextContent reports whether a file with the given MIME type and data
+// is served as text.
+func isTextContent(mimeType string, data []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		mediaType = mimeType
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return utf8.Valid(data)
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript",
+		"application/x-sh", "application/yaml", "application/toml":
+		return utf8.Valid(data)
+	case "application/octet-stream":
+		// Unknown extensions with text content
+		return len(data) > 0 && utf8.Valid(data) && !bytes.ContainsRune(data, 0)
+	}
+	return false
+}
@@ -0,0 +1,273 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func writeTestFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+}
+
+func readTestResource(t *testing.T, server *MCPServer, uri string) (*mcp.ReadResourceResult, *mcp.JSONRPCError) {
+	t.Helper()
+	message, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "resources/read",
+		"params":  map[string]any{"uri": uri},
+	})
+	require.NoError(t, err)
+	switch response := server.HandleMessage(context.Background(), message).(type) {
+	case mcp.JSONRPCResponse:
+		result := response.Result.(mcp.ReadResourceResult)
+		return &result, nil
+	case mcp.JSONRPCError:
+		return nil, &response
+	default:
+		t.Fatalf("unexpected response %T", response)
+		return nil, nil
+	}
+}
+
+// resourceNames returns the names of the resources of server without the
+// base name of root they start with.
+func resourceNames(t *testing.T, server *MCPServer, root string) []string {
+	t.Helper()
+	server.resourcesMu.RLock()
+	defer server.resourcesMu.RUnlock()
+	prefix := filepath.Base(root) + "/"
+	var names []string
+	for _, entry := range server.resources {
+		name, ok := strings.CutPrefix(entry.resource.Name, prefix)
+		assert.True(t, ok, "name %q does not start with %q", entry.resource.Name, prefix)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestFileSystemProvider(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "readme.md"), []byte("# Hello"))
+	writeTestFile(t, filepath.Join(root, "docs", "notes.txt"), []byte("notes"))
+	writeTestFile(t, filepath.Join(root, "docs", "image.png"), []byte{0x89, 'P', 'N', 'G', 0, 1, 2})
+	writeTestFile(t, filepath.Join(root, "docs", "data"), []byte{0, 1, 2, 0xff})
+	writeTestFile(t, filepath.Join(root, "node_modules", "lib.js"), []byte("lib"))
+	writeTestFile(t, filepath.Join(root, "docs", "debug.log"), []byte("log"))
+	writeTestFile(t, filepath.Join(filepath.Dir(root), "secret.txt"), []byte("secret"))
+
+	provider, err := NewFileSystemProvider(root, WithFileSystemExclude("node_modules", "*.log"))
+	require.NoError(t, err)
+	server := NewMCPServer("test", "1.0.0")
+	require.NoError(t, provider.Register(server))
+	defer provider.Close()
+
+	assert.Equal(t, []string{"docs/data", "docs/image.png", "docs/notes.txt", "readme.md"}, resourceNames(t, server, root))
+
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	require.NoError(t, err)
+	uri := func(rel string) string {
+		return fileURI(filepath.Join(resolvedRoot, filepath.FromSlash(rel)))
+	}
+
+	t.Run("text", func(t *testing.T) {
+		result, rpcErr := readTestResource(t, server, uri("docs/notes.txt"))
+		require.Nil(t, rpcErr)
+		require.Len(t, result.Contents, 1)
+		text, ok := result.Contents[0].(mcp.TextResourceContents)
+		require.True(t, ok)
+		assert.Equal(t, "notes", text.Text)
+		assert.Equal(t, "text/plain; charset=utf-8", text.MIMEType)
+	})
+
+	t.Run("blob", func(t *testing.T) {
+		result, rpcErr := readTestResource(t, server, uri("docs/image.png"))
+		require.Nil(t, rpcErr)
+		blob, ok := result.Contents[0].(mcp.BlobResourceContents)
+		require.True(t, ok)
+		assert.Equal(t, "image/png", blob.MIMEType)
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0x89, 'P', 'N', 'G', 0, 1, 2}), blob.Blob)
+
+		// Files without a known extension are sniffed.
+		result, rpcErr = readTestResource(t, server, uri("docs/data"))
+		require.Nil(t, rpcErr)
+		blob, ok = result.Contents[0].(mcp.BlobResourceContents)
+		require.True(t, ok)
+		assert.Equal(t, "application/octet-stream", blob.MIMEType)
+	})
+
+	t.Run("template", func(t *testing.T) {
+		writeTestFile(t, filepath.Join(root, "new.txt"), []byte("new"))
+		result, rpcErr := readTestResource(t, server, uri("new.txt"))
+		require.Nil(t, rpcErr)
+		assert.Equal(t, "new", result.Contents[0].(mcp.TextResourceContents).Text)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		for _, rejected := range []string{
+			uri("../secret.txt"),
+			fileURI(filepath.Join(filepath.Dir(resolvedRoot), "secret.txt")),
+			uri("node_modules/lib.js"),
+			uri("docs/debug.log"),
+			uri("missing.txt"),
+		} {
+			_, rpcErr := readTestResource(t, server, rejected)
+			assert.NotNil(t, rpcErr, rejected)
+		}
+	})
+}
+
+func TestFileSystemProvider_Include(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "readme.md"), []byte("# Hello"))
+	writeTestFile(t, filepath.Join(root, "docs", "guide.md"), []byte("# Guide"))
+	writeTestFile(t, filepath.Join(root, "main.go"), []byte("package main"))
+
+	provider, err := NewFileSystemProvider(root, WithFileSystemInclude("*.md"))
+	require.NoError(t, err)
+	server := NewMCPServer("test", "1.0.0")
+	require.NoError(t, provider.Register(server))
+	defer provider.Close()
+	assert.Equal(t, []string{"docs/guide.md", "readme.md"}, resourceNames(t, server, root))
+
+	_, err = NewFileSystemProvider(root, WithFileSystemInclude("["))
+	assert.Error(t, err)
+	_, err = NewFileSystemProvider(filepath.Join(root, "readme.md"))
+	assert.Error(t, err)
+}
+
+func TestFileSystemProvider_Watch(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "a.txt"), []byte("a"))
+	writeTestFile(t, filepath.Join(root, "b.txt"), []byte("b"))
+
+	server := NewMCPServer("test", "1.0.0", WithResourceCapabilities(true, true))
+	session := fakeSession{
+		sessionID:           "watcher",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 100),
+		initialized:         true,
+	}
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+
+	provider, err := NewFileSystemProvider(root, WithFileSystemWatch(10*time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, provider.Register(server))
+	defer provider.Close()
+
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	require.NoError(t, err)
+	uriA := fileURI(filepath.Join(resolvedRoot, "a.txt"))
+	server.subscriptions.add(session.SessionID(), uriA)
+
+	// Drain the notifications of the registration.
+	for len(session.notificationChannel) > 0 {
+		<-session.notificationChannel
+	}
+	waitFor := func(method, uri string) {
+		t.Helper()
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case notification := <-session.notificationChannel:
+				if notification.Method == method && (uri == "" || notification.Params.AdditionalFields["uri"] == uri) {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("no %s notification", method)
+			}
+		}
+	}
+
+	writeTestFile(t, filepath.Join(root, "a.txt"), []byte("changed"))
+	waitFor(mcp.MethodNotificationResourceUpdated, uriA)
+
+	writeTestFile(t, filepath.Join(root, "c.txt"), []byte("c"))
+	waitFor(mcp.MethodNotificationResourcesListChanged, "")
+	assert.Contains(t, resourceNames(t, server, root), "c.txt")
+
+	require.NoError(t, os.Remove(filepath.Join(root, "b.txt")))
+	waitFor(mcp.MethodNotificationResourcesListChanged, "")
+	assert.NotContains(t, resourceNames(t, server, root), "b.txt")
+
+	provider.Close()
+}
+
+func TestFileSystemProvider_Roots(t *testing.T) {
+	base := t.TempDir()
+	rootA := filepath.Join(base, "a")
+	rootB := filepath.Join(base, "b")
+	writeTestFile(t, filepath.Join(rootA, "readme.md"), []byte("a"))
+	writeTestFile(t, filepath.Join(rootB, "readme.md"), []byte("b"))
+
+	server := NewMCPServer("test", "1.0.0")
+	for _, root := range []string{rootA, rootB} {
+		provider, err := NewFileSystemProvider(root)
+		require.NoError(t, err)
+		require.NoError(t, provider.Register(server))
+		defer provider.Close()
+	}
+
+	resolvedBase, err := filepath.EvalSymlinks(base)
+	require.NoError(t, err)
+	server.resourcesMu.RLock()
+	templates := sortedKeys(server.resourceTemplates)
+	var names []string
+	for _, entry := range server.resources {
+		names = append(names, entry.resource.Name)
+	}
+	server.resourcesMu.RUnlock()
+	// Files with the same path in different roots have different names.
+	assert.ElementsMatch(t, []string{"a/readme.md", "b/readme.md"}, names)
+	assert.Equal(t, []string{
+		fileURI(filepath.Join(resolvedBase, "a")) + "/{+path}",
+		fileURI(filepath.Join(resolvedBase, "b")) + "/{+path}",
+	}, templates)
+
+	// Files created after registration are served by the template of their root.
+	writeTestFile(t, filepath.Join(rootA, "new-a.txt"), []byte("new a"))
+	writeTestFile(t, filepath.Join(rootB, "new-b.txt"), []byte("new b"))
+	result, rpcErr := readTestResource(t, server, fileURI(filepath.Join(resolvedBase, "a", "new-a.txt")))
+	require.Nil(t, rpcErr)
+	assert.Equal(t, "new a", result.Contents[0].(mcp.TextResourceContents).Text)
+	result, rpcErr = readTestResource(t, server, fileURI(filepath.Join(resolvedBase, "b", "new-b.txt")))
+	require.Nil(t, rpcErr)
+	assert.Equal(t, "new b", result.Contents[0].(mcp.TextResourceContents).Text)
+}
+
+func TestFileSystemProvider_MaxFileSize(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "small.txt"), []byte("small"))
+	writeTestFile(t, filepath.Join(root, "large.txt"), []byte("large file"))
+
+	provider, err := NewFileSystemProvider(root, WithFileSystemMaxFileSize(5))
+	require.NoError(t, err)
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	require.NoError(t, err)
+
+	read := func(name string) error {
+		_, err := provider.read(context.Background(), mcp.ReadResourceRequest{
+			Params: mcp.ReadResourceParams{URI: fileURI(filepath.Join(resolvedRoot, name))},
+		})
+		return err
+	}
+	assert.NoError(t, read("small.txt"))
+	assert.ErrorIs(t, read("large.txt"), ErrFileTooLarge)
+
+	provider.maxFileSize = 0
+	assert.NoError(t, read("large.txt"))
+}
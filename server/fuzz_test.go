@@ -0,0 +1,133 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// messageSeeds are JSON-RPC messages taken from the server tests.
+var messageSeeds = []string{
+	`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"test","version":"1.0.0"},"capabilities":{}}}`,
+	`{"jsonrpc":"2.0","id":"abc","method":"ping"}`,
+	`{"jsonrpc":"2.0","id":2,"method":"tools/list","params":{"cursor":"dG9vbC0x"}}`,
+	`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+	`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"slow","arguments":{},"task":{"ttl":1000}}}`,
+	`{"jsonrpc":"2.0","id":5,"method":"prompts/get","params":{"name":"greeting","arguments":{"name":"x"}}}`,
+	`{"jsonrpc":"2.0","id":6,"method":"resources/read","params":{"uri":"test://static"}}`,
+	`{"jsonrpc":"2.0","id":7,"method":"resources/templates/list"}`,
+	`{"jsonrpc":"2.0","id":8,"method":"logging/setLevel","params":{"level":"debug"}}`,
+	`{"jsonrpc":"2.0","id":9,"method":"completion/complete","params":{"ref":{"type":"ref/prompt","name":"greeting"},"argument":{"name":"name","value":"x"}}}`,
+	`{"jsonrpc":"2.0","id":10,"method":"tasks/get","params":{"taskId":"unknown"}}`,
+	`{"jsonrpc":"2.0","id":11,"method":"tasks/list"}`,
+	`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+	`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`,
+	`{"jsonrpc":"2.0","id":12,"result":{}}`,
+	`{"jsonrpc":"1.0","id":13,"method":"ping"}`,
+	`{"jsonrpc":"2.0","id":14,"method":"unknown/method"}`,
+	`{"jsonrpc":"2.0","id":15,"method":"tools/call","params":"not an object"}`,
+	`[{"jsonrpc":"2.0","id":16,"method":"ping"}]`,
+	`{"jsonrpc":"2.0","id":null,"method":"ping"}`,
+	`{invalid`,
+}
+
+// newFuzzServer returns a server with every capability and a few features
+// to route requests to.
+func newFuzzServer() *MCPServer {
+	s := NewMCPServer("fuzz", "1.0.0",
+		WithToolCapabilities(true),
+		WithPromptCapabilities(true),
+		WithResourceCapabilities(true, true),
+		WithTaskCapabilities(true, true, true),
+		WithLogging(),
+		WithCompletions(),
+		WithPaginationLimit(1),
+	)
+	s.AddTool(mcp.NewTool("echo", mcp.WithString("text")),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText(request.GetString("text", "")), nil
+		})
+	s.AddTaskTool(mcp.NewTool("slow", mcp.WithTaskSupport(mcp.TaskSupportOptional)),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CreateTaskResult, error) {
+			return &mcp.CreateTaskResult{}, nil
+		})
+	s.AddPrompt(mcp.NewPrompt("greeting", mcp.WithArgument("name")),
+		func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return mcp.NewGetPromptResult("greeting", nil), nil
+		})
+	s.AddResource(mcp.NewResource("test://static", "static"),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "static"}}, nil
+		})
+	s.AddResourceTemplate(mcp.NewResourceTemplate("test://items/{id}", "items"),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "item"}}, nil
+		})
+	return s
+}
+
+func FuzzHandleMessage(f *testing.F) {
+	for _, seed := range messageSeeds {
+		f.Add([]byte(seed))
+	}
+	s := newFuzzServer()
+	f.Fuzz(func(t *testing.T, message []byte) {
+		response := s.HandleMessage(context.Background(), message)
+		if response == nil {
+			return
+		}
+
+		data, err := json.Marshal(response)
+		if err != nil {
+			t.Fatalf("marshaling response: %v", err)
+		}
+
+		// Decode the message the way HandleMessage does. If that fails, the
+		// response is a parse error without an ID.
+		var request struct {
+			JSONRPC string        `json:"jsonrpc"`
+			Method  mcp.MCPMethod `json:"method"`
+			ID      any           `json:"id,omitempty"`
+			Result  any           `json:"result,omitempty"`
+		}
+		if json.Unmarshal(message, &request) != nil {
+			request.ID = nil
+		}
+		var got struct {
+			ID any `json:"id"`
+		}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("unmarshaling response %s: %v", data, err)
+		}
+		if !reflect.DeepEqual(got.ID, request.ID) {
+			t.Fatalf("response %s does not carry request ID %v", data, request.ID)
+		}
+	})
+}
+
+func FuzzStreamableHTTPPost(f *testing.F) {
+	for _, seed := range messageSeeds {
+		f.Add([]byte(seed))
+	}
+	handler := NewStreamableHTTPServer(newFuzzServer(), WithStateLess(true))
+	f.Fuzz(func(t *testing.T, body []byte) {
+		request := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Accept", "application/json, text/event-stream")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code >= http.StatusInternalServerError {
+			t.Fatalf("got status %d for %q: %s", recorder.Code, body, recorder.Body)
+		}
+		if recorder.Header().Get("Content-Type") == "application/json" && !json.Valid(recorder.Body.Bytes()) {
+			t.Fatalf("invalid JSON response for %q: %s", body, recorder.Body)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -25,6 +26,11 @@ type RootsHandler interface {
 	ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error)
 }
 
+// InProcessRequestHandler answers a request the server sends to an
+// in-process client, such as a ping or a custom method, with the raw result.
+// Error responses of the client are returned as errors.
+type InProcessRequestHandler func(ctx context.Context, request mcp.JSONRPCRequest) (json.RawMessage, error)
+
 type InProcessSession struct {
 	sessionID          string
 	notifications      chan mcp.JSONRPCNotification
@@ -32,9 +38,12 @@ type InProcessSession struct {
 	loggingLevel       atomic.Value
 	clientInfo         atomic.Value
 	clientCapabilities atomic.Value
+	protocolVersion    atomic.Value
 	samplingHandler    SamplingHandler
 	elicitationHandler ElicitationHandler
 	rootsHandler       RootsHandler
+	requestHandler     InProcessRequestHandler
+	requests           clientRequests
 	mu                 sync.RWMutex
 }
 
@@ -64,6 +73,12 @@ func (s *InProcessSession) NotificationChannel() chan<- mcp.JSONRPCNotification
 	return s.notifications
 }
 
+// Notifications returns the channel that notifications sent to the session
+// are delivered on.
+func (s *InProcessSession) Notifications() <-chan mcp.JSONRPCNotification {
+	return s.notifications
+}
+
 func (s *InProcessSession) Initialize() {
 	s.loggingLevel.Store(mcp.LoggingLevelError)
 	s.initialized.Store(true)
@@ -99,6 +114,17 @@ func (s *InProcessSession) SetClientCapabilities(clientCapabilities mcp.ClientCa
 	s.clientCapabilities.Store(clientCapabilities)
 }
 
+func (s *InProcessSession) GetProtocolVersion() string {
+	if value, ok := s.protocolVersion.Load().(string); ok {
+		return value
+	}
+	return ""
+}
+
+func (s *InProcessSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
 func (s *InProcessSession) SetLogLevel(level mcp.LoggingLevel) {
 	s.loggingLevel.Store(level)
 }
@@ -149,6 +175,41 @@ func (s *InProcessSession) ListRoots(ctx context.Context, request mcp.ListRootsR
 	return handler.ListRoots(ctx, request)
 }
 
+// SetRequestHandler sets the handler that answers the requests sent with
+// SendRequest.
+func (s *InProcessSession) SetRequestHandler(handler InProcessRequestHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requestHandler = handler
+}
+
+// SendRequest sends a request to the client through the handler set with
+// SetRequestHandler and returns the raw result. It returns
+// ErrRequestsNotSupported if no handler is set.
+func (s *InProcessSession) SendRequest(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	s.mu.RLock()
+	handler := s.requestHandler
+	s.mu.RUnlock()
+
+	if handler == nil {
+		return nil, ErrRequestsNotSupported
+	}
+
+	return s.requests.send(ctx, s, method, params, func(ctx context.Context, request mcp.JSONRPCRequest) error {
+		id, ok := request.ID.Value().(int64)
+		if !ok {
+			return fmt.Errorf("unexpected request ID %v", request.ID)
+		}
+		// The handler runs in the background so that the timeout and the
+		// cancellation of ctx apply while it answers.
+		go func() {
+			result, err := handler(ctx, request)
+			s.requests.resolve(id, clientResponse{result: result, err: err})
+		}()
+		return nil
+	})
+}
+
 // GenerateInProcessSessionID generates a unique session ID for inprocess clients
 func GenerateInProcessSessionID() string {
 	return fmt.Sprintf("inprocess-%d", time.Now().UnixNano())
@@ -156,10 +217,12 @@ func GenerateInProcessSessionID() string {
 
 // Ensure interface compliance
 var (
-	_ ClientSession          = (*InProcessSession)(nil)
-	_ SessionWithLogging     = (*InProcessSession)(nil)
-	_ SessionWithClientInfo  = (*InProcessSession)(nil)
-	_ SessionWithSampling    = (*InProcessSession)(nil)
-	_ SessionWithElicitation = (*InProcessSession)(nil)
-	_ SessionWithRoots       = (*InProcessSession)(nil)
+	_ ClientSession              = (*InProcessSession)(nil)
+	_ SessionWithLogging         = (*InProcessSession)(nil)
+	_ SessionWithClientInfo      = (*InProcessSession)(nil)
+	_ SessionWithProtocolVersion = (*InProcessSession)(nil)
+	_ SessionWithSampling        = (*InProcessSession)(nil)
+	_ SessionWithElicitation     = (*InProcessSession)(nil)
+	_ SessionWithRoots           = (*InProcessSession)(nil)
+	_ SessionWithRequests        = (*InProcessSession)(nil)
 )
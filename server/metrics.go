@@ -0,0 +1,337 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultMetricsPath is the path the HTTP transports serve metrics on when
+// they are configured with WithSSEMetrics or WithStreamableHTTPMetrics.
+const DefaultMetricsPath = "/metrics"
+
+// Metrics collects request, session, notification and task metrics of an
+// MCPServer and serves them in the Prometheus text exposition format. It has
+// no dependencies beyond the standard library.
+//
+// Metrics is an http.Handler, so it can be mounted next to the MCP endpoint:
+//
+//	metrics := server.NewMetrics()
+//	mcpServer := server.NewMCPServer("example", "1.0.0", server.WithMetrics(metrics))
+//	httpServer := server.NewStreamableHTTPServer(mcpServer, server.WithStreamableHTTPMetrics(metrics))
+//	mux := http.NewServeMux()
+//	mux.Handle("/mcp", httpServer)
+//	mux.Handle("/metrics", metrics)
+type Metrics struct {
+	server *MCPServer
+
+	mu                   sync.Mutex
+	requests             map[string]uint64
+	errors               map[requestErrorKey]uint64
+	notificationsDropped uint64
+	queuedNotifications  map[queuedNotificationKey]uint64
+	tasks                map[string]uint64
+	streams              []streamCounter
+}
+
+type requestErrorKey struct {
+	method string
+	code   int
+}
+
+// queuedNotificationKey identifies the notifications counted by
+// mcp_notification_queue_*_total.
+type queuedNotificationKey struct {
+	transport string
+	method    string
+	event     string
+}
+
+// streamCounter reports the number of open SSE streams of a transport.
+type streamCounter struct {
+	transport string
+	count     func() int
+}
+
+// NewMetrics creates Metrics. Pass them to NewMCPServer with WithMetrics to
+// collect the metrics of the server.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests:            make(map[string]uint64),
+		errors:              make(map[requestErrorKey]uint64),
+		queuedNotifications: make(map[queuedNotificationKey]uint64),
+		tasks:               make(map[string]uint64),
+	}
+}
+
+// WithMetrics collects the metrics of the server in metrics. The hooks and
+// task hooks metrics collect from are registered by NewMCPServer once all
+// options are applied, so they are kept when WithHooks or WithTaskHooks is
+// given after WithMetrics. Metrics collect from a single server.
+func WithMetrics(metrics *Metrics) ServerOption {
+	return func(s *MCPServer) {
+		s.metrics = metrics
+	}
+}
+
+// register attaches m to mcpServer and adds the hooks and task hooks it
+// collects from.
+func (m *Metrics) register(mcpServer *MCPServer) {
+	m.mu.Lock()
+	m.server = mcpServer
+	m.mu.Unlock()
+
+	if mcpServer.hooks == nil {
+		mcpServer.hooks = &Hooks{}
+	}
+	mcpServer.hooks.AddOnSuccess(func(ctx context.Context, id any, method mcp.MCPMethod, message any, result any) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.requests[string(method)]++
+	})
+	mcpServer.hooks.AddOnError(m.onError)
+
+	if mcpServer.taskHooks == nil {
+		mcpServer.taskHooks = &TaskHooks{}
+	}
+	countTask := func(event string) func(context.Context, TaskMetrics) {
+		return func(ctx context.Context, metrics TaskMetrics) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.tasks[event]++
+		}
+	}
+	mcpServer.taskHooks.AddOnTaskCreated(countTask("created"))
+	mcpServer.taskHooks.AddOnTaskCompleted(countTask("completed"))
+	mcpServer.taskHooks.AddOnTaskFailed(countTask("failed"))
+	mcpServer.taskHooks.AddOnTaskCancelled(countTask("cancelled"))
+}
+
+func (m *Metrics) onError(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Failed notifications are reported to the error hooks with the method
+	// "notification". They are not requests: only dropped notifications are
+	// counted.
+	if method == "notification" {
+		if errors.Is(err, ErrNotificationChannelBlocked) {
+			m.notificationsDropped++
+		}
+		return
+	}
+
+	m.requests[string(method)]++
+	key := requestErrorKey{method: string(method), code: mcp.INTERNAL_ERROR}
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		key.code = reqErr.code
+	}
+	m.errors[key]++
+}
+
+// countQueuedNotification counts a notification of a session's notification
+// queue that was delivered, dropped or coalesced.
+func (m *Metrics) countQueuedNotification(transport, method, event string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queuedNotifications[queuedNotificationKey{transport: transport, method: method, event: event}]++
+}
+
+// addStreams registers the open SSE streams of a transport.
+func (m *Metrics) addStreams(transport string, count func() int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.streams = append(m.streams, streamCounter{transport: transport, count: count})
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = m.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format to w.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var b metricsWriter
+	m.mu.Lock()
+	mcpServer := m.server
+	m.mu.Unlock()
+	if mcpServer == nil {
+		// Not passed to a server with WithMetrics
+		return 0, nil
+	}
+
+	sessions := make(map[string]float64)
+	queueDepth := make(map[string]float64)
+	queueCapacity := make(map[string]float64)
+	mcpServer.sessions.Range(func(_, value any) bool {
+		if session, ok := value.(ClientSession); ok {
+			transport := sessionTransport(session)
+			sessions[transport]++
+			if channel := session.NotificationChannel(); channel != nil {
+				queueDepth[transport] += float64(len(channel))
+				queueCapacity[transport] += float64(cap(channel))
+			}
+			if stats, ok := mcpServer.NotificationQueueStats(session.SessionID()); ok {
+				queueDepth[transport] += float64(stats.Pending)
+				queueCapacity[transport] += float64(stats.Capacity)
+			}
+		}
+		return true
+	})
+	b.family("mcp_sessions_active", "gauge", "Registered client sessions.")
+	b.byLabel("mcp_sessions_active", "transport", sessions)
+	b.family("mcp_notification_queue_depth", "gauge", "Notifications waiting in session notification channels and queues.")
+	b.byLabel("mcp_notification_queue_depth", "transport", queueDepth)
+	b.family("mcp_notification_queue_capacity", "gauge", "Capacity of session notification channels and queues.")
+	b.byLabel("mcp_notification_queue_capacity", "transport", queueCapacity)
+
+	mcpServer.tasksMu.RLock()
+	activeTasks := mcpServer.activeTasks
+	mcpServer.tasksMu.RUnlock()
+
+	m.mu.Lock()
+	streams := make(map[string]float64)
+	for _, counter := range m.streams {
+		streams[counter.transport] += float64(counter.count())
+	}
+	requests := make(map[string]float64, len(m.requests))
+	for method, count := range m.requests {
+		requests[method] = float64(count)
+	}
+	errorKeys := make([]requestErrorKey, 0, len(m.errors))
+	for key := range m.errors {
+		errorKeys = append(errorKeys, key)
+	}
+	slices.SortFunc(errorKeys, func(a, b requestErrorKey) int {
+		if c := strings.Compare(a.method, b.method); c != 0 {
+			return c
+		}
+		return a.code - b.code
+	})
+	errorCounts := make([]uint64, len(errorKeys))
+	for i, key := range errorKeys {
+		errorCounts[i] = m.errors[key]
+	}
+	queuedKeys := make([]queuedNotificationKey, 0, len(m.queuedNotifications))
+	for key := range m.queuedNotifications {
+		queuedKeys = append(queuedKeys, key)
+	}
+	slices.SortFunc(queuedKeys, func(a, b queuedNotificationKey) int {
+		if c := strings.Compare(a.transport, b.transport); c != 0 {
+			return c
+		}
+		return strings.Compare(a.method, b.method)
+	})
+	queuedCounts := make([]uint64, len(queuedKeys))
+	for i, key := range queuedKeys {
+		queuedCounts[i] = m.queuedNotifications[key]
+	}
+	tasks := make(map[string]float64, len(m.tasks))
+	for event, count := range m.tasks {
+		tasks[event] = float64(count)
+	}
+	dropped := m.notificationsDropped
+	m.mu.Unlock()
+
+	b.family("mcp_sse_streams_open", "gauge", "Open server-sent event streams.")
+	b.byLabel("mcp_sse_streams_open", "transport", streams)
+	b.family("mcp_notifications_dropped_total", "counter", "Notifications dropped because a session's notification channel or queue was full.")
+	b.sample("mcp_notifications_dropped_total", "", float64(dropped))
+	if mcpServer.notificationQueues != nil {
+		// Per-session figures are reported by NotificationQueueStats and the
+		// admin endpoints, as session labels would grow without bound.
+		for _, event := range []struct{ name, help string }{
+			{"delivered", "Notifications passed from session notification queues to the notification channels, by transport and method."},
+			{"dropped", "Notifications dropped by the delivery policies of session notification queues, by transport and method."},
+			{"coalesced", "Notifications coalesced or deduplicated in session notification queues, by transport and method."},
+		} {
+			name := "mcp_notification_queue_" + event.name + "_total"
+			b.family(name, "counter", event.help)
+			for i, key := range queuedKeys {
+				if key.event == event.name {
+					labels := fmt.Sprintf(`transport="%s",method="%s"`, escapeLabelValue(key.transport), escapeLabelValue(key.method))
+					b.sample(name, labels, float64(queuedCounts[i]))
+				}
+			}
+		}
+	}
+	b.family("mcp_requests_total", "counter", "Requests handled, by method.")
+	b.byLabel("mcp_requests_total", "method", requests)
+	b.family("mcp_request_errors_total", "counter", "Requests that returned an error, by method and JSON-RPC error code.")
+	for i, key := range errorKeys {
+		labels := fmt.Sprintf(`method="%s",code="%d"`, escapeLabelValue(key.method), key.code)
+		b.sample("mcp_request_errors_total", labels, float64(errorCounts[i]))
+	}
+	b.family("mcp_tasks_active", "gauge", "Tasks that have not reached a terminal status.")
+	b.sample("mcp_tasks_active", "", float64(activeTasks))
+	b.family("mcp_tasks_total", "counter", "Task lifecycle events, by event.")
+	b.byLabel("mcp_tasks_total", "event", tasks)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// sessionTransport returns the name of the transport session belongs to.
+func sessionTransport(session ClientSession) string {
+	switch session.(type) {
+	case *sseSession:
+		return "sse"
+	case *streamableHttpSession:
+		return "streamable_http"
+	case *stdioSession:
+		return "stdio"
+	case *InProcessSession:
+		return "inprocess"
+	default:
+		return "other"
+	}
+}
+
+// metricsWriter formats metric families in the text exposition format.
+type metricsWriter struct {
+	strings.Builder
+}
+
+func (b *metricsWriter) family(name, metricType, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func (b *metricsWriter) sample(name, labels string, value float64) {
+	b.WriteString(name)
+	if labels != "" {
+		b.WriteString("{" + labels + "}")
+	}
+	b.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64) + "\n")
+}
+
+// byLabel writes one sample per entry of values, sorted by label value.
+func (b *metricsWriter) byLabel(name, label string, values map[string]float64) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		b.sample(name, fmt.Sprintf(`%s="%s"`, label, escapeLabelValue(key)), values[key])
+	}
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func metricsText(t *testing.T, metrics *Metrics) string {
+	t.Helper()
+	var b strings.Builder
+	_, err := metrics.WriteTo(&b)
+	require.NoError(t, err)
+	return b.String()
+}
+
+func TestMetrics_Requests(t *testing.T) {
+	metrics := NewMetrics()
+	mcpServer := NewMCPServer("metrics-test", "1.0.0",
+		WithToolCapabilities(false),
+		WithTaskCapabilities(true, true, true),
+		WithMetrics(metrics),
+		// Hooks given after WithMetrics do not replace the metrics hooks.
+		WithHooks(&Hooks{}),
+	)
+	mcpServer.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("echo"), nil
+	})
+	mcpServer.AddTaskTool(mcp.NewTool("slow", mcp.WithTaskSupport(mcp.TaskSupportOptional)),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CreateTaskResult, error) {
+			return &mcp.CreateTaskResult{}, nil
+		})
+
+	ctx := context.Background()
+	for _, message := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"missing"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"slow","task":{}}}`,
+	} {
+		mcpServer.HandleMessage(ctx, json.RawMessage(message))
+	}
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(metricsText(t, metrics), `mcp_tasks_total{event="completed"} 1`)
+	}, time.Second, 10*time.Millisecond)
+
+	text := metricsText(t, metrics)
+	assert.Contains(t, text, "# TYPE mcp_requests_total counter\n")
+	assert.Contains(t, text, `mcp_requests_total{method="ping"} 1`+"\n")
+	assert.Contains(t, text, `mcp_requests_total{method="tools/call"} 3`+"\n")
+	assert.Contains(t, text, `mcp_request_errors_total{method="tools/call",code="-32602"} 1`+"\n")
+	assert.Contains(t, text, `mcp_tasks_total{event="created"} 1`+"\n")
+	assert.Contains(t, text, "mcp_tasks_active 0\n")
+}
+
+func TestMetrics_Sessions(t *testing.T) {
+	metrics := NewMetrics()
+	mcpServer := NewMCPServer("metrics-test", "1.0.0", WithMetrics(metrics))
+
+	full := make(chan mcp.JSONRPCNotification, 1)
+	full <- mcp.JSONRPCNotification{}
+	session := fakeSession{sessionID: "full", notificationChannel: full, initialized: true}
+	require.NoError(t, mcpServer.RegisterSession(context.Background(), session))
+
+	text := metricsText(t, metrics)
+	assert.Contains(t, text, `mcp_sessions_active{transport="other"} 1`+"\n")
+	assert.Contains(t, text, `mcp_notification_queue_depth{transport="other"} 1`+"\n")
+	assert.Contains(t, text, `mcp_notification_queue_capacity{transport="other"} 1`+"\n")
+	assert.Contains(t, text, "mcp_notifications_dropped_total 0\n")
+
+	mcpServer.SendNotificationToAllClients("notifications/test", nil)
+	require.Eventually(t, func() bool {
+		return strings.Contains(metricsText(t, metrics), "mcp_notifications_dropped_total 1\n")
+	}, time.Second, 10*time.Millisecond)
+	// Notification errors are not counted as failed requests.
+	assert.NotContains(t, metricsText(t, metrics), `method="notification"`)
+}
+
+func TestMetrics_StreamableHTTP(t *testing.T) {
+	metrics := NewMetrics()
+	mcpServer := NewMCPServer("metrics-test", "1.0.0", WithMetrics(metrics))
+	httpServer := NewTestStreamableHTTPServer(mcpServer, WithStreamableHTTPMetrics(metrics))
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(metricsText(t, metrics), `mcp_sse_streams_open{transport="streamable_http"} 1`)
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, metricsText(t, metrics), `mcp_sessions_active{transport="streamable_http"} 1`)
+
+	cancel()
+	require.Eventually(t, func() bool {
+		return strings.Contains(metricsText(t, metrics), `mcp_sse_streams_open{transport="streamable_http"} 0`)
+	}, time.Second, 10*time.Millisecond)
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, DefaultMetricsPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "# TYPE mcp_sse_streams_open gauge\n")
+
+	recorder = httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, DefaultMetricsPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestMetrics_SSE(t *testing.T) {
+	metrics := NewMetrics()
+	mcpServer := NewMCPServer("metrics-test", "1.0.0", WithMetrics(metrics))
+	testServer := NewTestServer(mcpServer, WithSSEMetrics(metrics))
+	defer testServer.Close()
+
+	resp, err := http.Get(testServer.URL + "/sse")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Eventually(t, func() bool {
+		metricsResp, err := http.Get(testServer.URL + DefaultMetricsPath)
+		if err != nil {
+			return false
+		}
+		defer metricsResp.Body.Close()
+		body, _ := io.ReadAll(metricsResp.Body)
+		return metricsResp.StatusCode == http.StatusOK &&
+			strings.Contains(string(body), `mcp_sse_streams_open{transport="sse"} 1`) &&
+			strings.Contains(string(body), `mcp_sessions_active{transport="sse"} 1`)
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,619 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// DefaultNotificationQueueSize is the number of notifications a session's
+	// queue holds when WithNotificationDelivery is used without
+	// WithNotificationQueue.
+	DefaultNotificationQueueSize = 100
+	// DefaultNotificationBlockTimeout is how long NotificationBlock waits for
+	// room in a full queue when NotificationDelivery.Timeout is not set.
+	DefaultNotificationBlockTimeout = 5 * time.Second
+)
+
+// NotificationPolicy decides what happens to a notification sent to a
+// session whose notification queue is full, or that overlaps with a
+// notification still waiting in the queue.
+type NotificationPolicy int
+
+const (
+	// NotificationDropNewest drops the new notification when the queue is
+	// full and returns ErrNotificationChannelBlocked.
+	NotificationDropNewest NotificationPolicy = iota
+	// NotificationDropOldest drops the oldest waiting notification of the
+	// same method to make room for the new one. If there is none, the oldest
+	// waiting notification whose own policy is NotificationDropOldest,
+	// NotificationCoalesce or NotificationDeduplicate is dropped instead, and
+	// if there is none of those either, the new notification is dropped and
+	// ErrNotificationChannelBlocked is returned.
+	NotificationDropOldest
+	// NotificationCoalesce replaces a waiting notification of the same method
+	// and key with the new one, so the client only receives the latest value.
+	// When there is nothing to replace and the queue is full, a waiting
+	// notification is dropped as with NotificationDropOldest.
+	NotificationCoalesce
+	// NotificationDeduplicate drops the new notification if an identical one
+	// is still waiting. When the queue is full, a waiting notification is
+	// dropped as with NotificationDropOldest.
+	NotificationDeduplicate
+	// NotificationBlock waits for room in the queue until the timeout, then
+	// drops the new notification and returns ErrNotificationChannelBlocked.
+	NotificationBlock
+)
+
+// String returns the name of the policy.
+func (p NotificationPolicy) String() string {
+	switch p {
+	case NotificationDropNewest:
+		return "drop_newest"
+	case NotificationDropOldest:
+		return "drop_oldest"
+	case NotificationCoalesce:
+		return "coalesce"
+	case NotificationDeduplicate:
+		return "deduplicate"
+	case NotificationBlock:
+		return "block"
+	default:
+		return fmt.Sprintf("NotificationPolicy(%d)", int(p))
+	}
+}
+
+// NotificationDelivery configures how notifications of a method are queued.
+type NotificationDelivery struct {
+	Policy NotificationPolicy
+	// Key is the parameter that identifies the notifications
+	// NotificationCoalesce replaces, such as "progressToken". Without a key,
+	// every waiting notification of the same method is replaced.
+	Key string
+	// Timeout bounds how long NotificationBlock waits. It defaults to
+	// DefaultNotificationBlockTimeout.
+	Timeout time.Duration
+}
+
+// defaultNotificationDeliveries are the delivery policies of notifications
+// the server sends itself. Other methods use NotificationDropNewest.
+var defaultNotificationDeliveries = map[string]NotificationDelivery{
+	"notifications/progress":                   {Policy: NotificationCoalesce, Key: "progressToken"},
+	"notifications/message":                    {Policy: NotificationDropOldest},
+	mcp.MethodNotificationToolsListChanged:     {Policy: NotificationDeduplicate},
+	mcp.MethodNotificationPromptsListChanged:   {Policy: NotificationDeduplicate},
+	mcp.MethodNotificationResourcesListChanged: {Policy: NotificationDeduplicate},
+	mcp.MethodNotificationResourceUpdated:      {Policy: NotificationBlock},
+	mcp.MethodNotificationTasksStatus:          {Policy: NotificationBlock},
+	"notifications/cancelled":                  {Policy: NotificationBlock},
+}
+
+// WithNotificationQueue gives every session a queue of up to size
+// notifications in front of its notification channel. Notifications wait in
+// the queue while the channel is full, and are dropped, coalesced or held
+// according to the delivery policy of their method when the queue itself
+// fills up:
+//
+//   - notifications/progress is coalesced by progress token
+//   - list_changed notifications are deduplicated
+//   - notifications/message drops the oldest log message
+//   - notifications/resources/updated, notifications/tasks/status and
+//     notifications/cancelled block for up to DefaultNotificationBlockTimeout
+//
+// Other notifications are dropped when the queue is full. Use
+// WithNotificationDelivery to change the policy of a method.
+//
+// Without this option notifications are sent directly to the notification
+// channel and dropped when it is full.
+func WithNotificationQueue(size int) ServerOption {
+	return func(s *MCPServer) {
+		if size <= 0 {
+			return
+		}
+		s.notificationQueueConfig().size = size
+	}
+}
+
+// WithNotificationDelivery sets the delivery policy of notifications with
+// the given method. It enables the notification queue with
+// DefaultNotificationQueueSize unless WithNotificationQueue sets a size.
+func WithNotificationDelivery(method string, delivery NotificationDelivery) ServerOption {
+	return func(s *MCPServer) {
+		s.notificationQueueConfig().deliveries[method] = delivery
+	}
+}
+
+// notificationQueues holds the notification queue configuration and the
+// queues of the sessions.
+type notificationQueues struct {
+	size       int
+	deliveries map[string]NotificationDelivery
+	queues     sync.Map // session ID -> *notificationQueue
+}
+
+func (s *MCPServer) notificationQueueConfig() *notificationQueues {
+	if s.notificationQueues == nil {
+		s.notificationQueues = &notificationQueues{
+			size:       DefaultNotificationQueueSize,
+			deliveries: make(map[string]NotificationDelivery),
+		}
+	}
+	return s.notificationQueues
+}
+
+// delivery returns the delivery policy of method.
+func (q *notificationQueues) delivery(method string) NotificationDelivery {
+	if delivery, ok := q.deliveries[method]; ok {
+		return delivery
+	}
+	return defaultNotificationDeliveries[method]
+}
+
+// NotificationQueueStats describes the notification queue of a session.
+type NotificationQueueStats struct {
+	// Pending is the number of notifications waiting in the queue, including
+	// those waiting for room with the NotificationBlock policy.
+	Pending int `json:"pending"`
+	// Capacity is the size of the queue.
+	Capacity int `json:"capacity"`
+	// Delivered is the number of notifications passed on to the session's
+	// notification channel.
+	Delivered uint64 `json:"delivered"`
+	// Dropped is the number of notifications dropped by the policies.
+	Dropped uint64 `json:"dropped"`
+	// Coalesced is the number of notifications replaced by a newer one or
+	// skipped as duplicates.
+	Coalesced uint64 `json:"coalesced"`
+}
+
+// NotificationQueueStats returns the statistics of the notification queue of
+// the session with the given ID. It reports false when the server has no
+// notification queues, or the session has not been sent notifications yet.
+func (s *MCPServer) NotificationQueueStats(sessionID string) (NotificationQueueStats, bool) {
+	if s.notificationQueues == nil {
+		return NotificationQueueStats{}, false
+	}
+	value, ok := s.notificationQueues.queues.Load(sessionID)
+	if !ok {
+		return NotificationQueueStats{}, false
+	}
+	return value.(*notificationQueue).stats(), true
+}
+
+// notificationQueueFor returns the queue of session, starting it on first
+// use. It returns nil if the server has no queues or session is not
+// registered, in which case notifications are sent to the channel directly.
+func (s *MCPServer) notificationQueueFor(session ClientSession) *notificationQueue {
+	if s.notificationQueues == nil {
+		return nil
+	}
+	sessionID := session.SessionID()
+	if value, ok := s.notificationQueues.queues.Load(sessionID); ok {
+		return value.(*notificationQueue)
+	}
+	if _, ok := s.sessions.Load(sessionID); !ok {
+		return nil
+	}
+
+	queue := newNotificationQueue(s.notificationQueues.size)
+	queue.expired = func(method string) {
+		s.reportBlockedNotification(context.Background(), sessionID, method)
+	}
+	if s.metrics != nil {
+		transport := sessionTransport(session)
+		queue.observe = func(event, method string) {
+			s.metrics.countQueuedNotification(transport, method, event)
+		}
+	}
+	value, loaded := s.notificationQueues.queues.LoadOrStore(sessionID, queue)
+	if loaded {
+		return value.(*notificationQueue)
+	}
+	// The session may have been unregistered before the queue was stored.
+	if _, ok := s.sessions.Load(sessionID); !ok {
+		s.notificationQueues.queues.CompareAndDelete(sessionID, queue)
+		queue.close()
+		return nil
+	}
+	go queue.run(session.NotificationChannel())
+	return queue
+}
+
+// closeNotificationQueue stops the queue of the session with the given ID
+// and discards the notifications waiting in it.
+func (s *MCPServer) closeNotificationQueue(sessionID string) {
+	if s.notificationQueues == nil {
+		return
+	}
+	if value, ok := s.notificationQueues.queues.LoadAndDelete(sessionID); ok {
+		value.(*notificationQueue).close()
+	}
+}
+
+// enqueueNotification adds notification to the queue of session. It reports
+// false if the session has no queue. If wait is false, a notification with
+// the NotificationBlock policy that does not fit in the queue waits for room
+// in the background instead of blocking the caller.
+func (s *MCPServer) enqueueNotification(
+	ctx context.Context,
+	session ClientSession,
+	notification mcp.JSONRPCNotification,
+	wait bool,
+) (bool, error) {
+	queue := s.notificationQueueFor(session)
+	if queue == nil {
+		return false, nil
+	}
+	delivery := s.notificationQueues.delivery(notification.Method)
+	var evicted *mcp.JSONRPCNotification
+	var err error
+	if wait {
+		evicted, err = queue.push(ctx, notification, delivery)
+	} else {
+		evicted, err = queue.offer(notification, delivery)
+	}
+	if evicted != nil {
+		s.reportBlockedNotification(ctx, session.SessionID(), evicted.Method)
+	}
+	if err != nil {
+		s.reportBlockedNotification(ctx, session.SessionID(), notification.Method)
+	}
+	return true, err
+}
+
+// notificationQueue is a bounded queue of notifications in front of a
+// session's notification channel.
+//
+// Notifications with the NotificationBlock policy that do not fit wait in
+// parked, in the order they were sent, until run makes room or their timeout
+// expires. Notifications sent while others are parked are parked behind
+// them, so the session receives notifications in the order they were sent.
+type notificationQueue struct {
+	mu     sync.Mutex
+	items  []mcp.JSONRPCNotification
+	parked []*parkedNotification
+	size   int
+	// policies holds the policy of every method added to the queue, which
+	// decides whether its notifications may be dropped to make room.
+	policies map[string]NotificationPolicy
+	// ready is signalled whenever a notification enters the queue.
+	ready     chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	// expired is called with the method of a notification offered without
+	// waiting that timed out while parked.
+	expired func(method string)
+	// observe is called with the event and method of every notification
+	// counted in the statistics, see Metrics.
+	observe func(event, method string)
+
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+	coalesced atomic.Uint64
+}
+
+// parkedNotification is a notification waiting for room in a full queue.
+type parkedNotification struct {
+	notification mcp.JSONRPCNotification
+	// timer drops the notification when its timeout expires. It is nil for
+	// notifications parked behind others, which do not time out.
+	timer *time.Timer
+	// result receives nil when the notification enters the queue, or the
+	// error it was dropped with.
+	result chan error
+	// waiting reports whether the sender waits for result.
+	waiting bool
+}
+
+func newNotificationQueue(size int) *notificationQueue {
+	return &notificationQueue{
+		size:     size,
+		policies: make(map[string]NotificationPolicy),
+		ready:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+// push adds notification to the queue according to delivery, waiting for
+// room if the policy is NotificationBlock. It returns the waiting
+// notification it dropped to make room, if any, and
+// ErrNotificationChannelBlocked if notification itself was dropped.
+func (q *notificationQueue) push(
+	ctx context.Context,
+	notification mcp.JSONRPCNotification,
+	delivery NotificationDelivery,
+) (*mcp.JSONRPCNotification, error) {
+	parked, evicted, err := q.add(notification, delivery, true)
+	if parked == nil {
+		return evicted, err
+	}
+	select {
+	case err := <-parked.result:
+		return nil, err
+	case <-ctx.Done():
+		if q.unpark(parked) {
+			q.count(&q.dropped, "dropped", parked.notification.Method)
+			return nil, ErrNotificationChannelBlocked
+		}
+		// The notification entered the queue or was dropped meanwhile.
+		return nil, <-parked.result
+	}
+}
+
+// offer adds notification to the queue like push, without waiting: a
+// notification with the NotificationBlock policy that does not fit is parked
+// until there is room or its timeout expires.
+func (q *notificationQueue) offer(
+	notification mcp.JSONRPCNotification,
+	delivery NotificationDelivery,
+) (*mcp.JSONRPCNotification, error) {
+	_, evicted, err := q.add(notification, delivery, false)
+	return evicted, err
+}
+
+// add adds notification to the queue according to delivery. It returns the
+// parked notification if notification must wait for room.
+func (q *notificationQueue) add(
+	notification mcp.JSONRPCNotification,
+	delivery NotificationDelivery,
+	waiting bool,
+) (*parkedNotification, *mcp.JSONRPCNotification, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	select {
+	case <-q.done:
+		return nil, nil, ErrSessionNotFound
+	default:
+	}
+	q.policies[notification.Method] = delivery.Policy
+
+	switch delivery.Policy {
+	case NotificationCoalesce:
+		matches := func(n mcp.JSONRPCNotification) bool {
+			return sameNotificationKey(n, notification, delivery.Key)
+		}
+		if i := q.indexFunc(matches); i >= 0 {
+			q.items[i] = notification
+			q.count(&q.coalesced, "coalesced", notification.Method)
+			return nil, nil, nil
+		}
+		if i := q.parkedIndexFunc(matches); i >= 0 {
+			q.parked[i].notification = notification
+			q.count(&q.coalesced, "coalesced", notification.Method)
+			return nil, nil, nil
+		}
+	case NotificationDeduplicate:
+		matches := func(n mcp.JSONRPCNotification) bool {
+			return n.Method == notification.Method && reflect.DeepEqual(n.Params, notification.Params)
+		}
+		if q.indexFunc(matches) >= 0 || q.parkedIndexFunc(matches) >= 0 {
+			q.count(&q.coalesced, "coalesced", notification.Method)
+			return nil, nil, nil
+		}
+	}
+
+	if len(q.parked) == 0 && len(q.items) < q.size {
+		q.append(notification)
+		return nil, nil, nil
+	}
+
+	switch delivery.Policy {
+	case NotificationDropOldest, NotificationCoalesce, NotificationDeduplicate:
+		i := q.evictionIndex(notification.Method)
+		if i < 0 {
+			q.count(&q.dropped, "dropped", notification.Method)
+			return nil, nil, ErrNotificationChannelBlocked
+		}
+		evicted := q.items[i]
+		q.items = append(q.items[:i], q.items[i+1:]...)
+		q.count(&q.dropped, "dropped", evicted.Method)
+		q.admitParked()
+		if len(q.parked) > 0 {
+			q.parked = append(q.parked, &parkedNotification{notification: notification, result: make(chan error, 1)})
+		} else {
+			q.append(notification)
+		}
+		return nil, &evicted, nil
+	case NotificationBlock:
+		// At most size notifications wait for room.
+		if len(q.parked) >= q.size {
+			q.count(&q.dropped, "dropped", notification.Method)
+			return nil, nil, ErrNotificationChannelBlocked
+		}
+		wait := delivery.Timeout
+		if wait <= 0 {
+			wait = DefaultNotificationBlockTimeout
+		}
+		parked := &parkedNotification{notification: notification, result: make(chan error, 1), waiting: waiting}
+		parked.timer = time.AfterFunc(wait, func() {
+			q.expire(parked)
+		})
+		q.parked = append(q.parked, parked)
+		return parked, nil, nil
+	default:
+		q.count(&q.dropped, "dropped", notification.Method)
+		return nil, nil, ErrNotificationChannelBlocked
+	}
+}
+
+// evictionIndex returns the index of the queued notification to drop to make
+// room for a notification of the given method, or -1 if there is none: the
+// oldest notification of the same method, or else the oldest one whose policy
+// allows dropping it. q.mu must be held.
+func (q *notificationQueue) evictionIndex(method string) int {
+	if i := q.indexFunc(func(n mcp.JSONRPCNotification) bool { return n.Method == method }); i >= 0 {
+		return i
+	}
+	return q.indexFunc(func(n mcp.JSONRPCNotification) bool {
+		switch q.policies[n.Method] {
+		case NotificationDropOldest, NotificationCoalesce, NotificationDeduplicate:
+			return true
+		}
+		return false
+	})
+}
+
+// admitParked moves parked notifications into the queue while there is
+// room. q.mu must be held.
+func (q *notificationQueue) admitParked() {
+	for len(q.parked) > 0 && len(q.items) < q.size {
+		parked := q.parked[0]
+		q.parked = q.parked[1:]
+		if parked.timer != nil {
+			parked.timer.Stop()
+		}
+		q.append(parked.notification)
+		parked.result <- nil
+	}
+}
+
+// unpark removes parked from the parked notifications, reporting whether it
+// was still parked.
+func (q *notificationQueue) unpark(parked *parkedNotification) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, p := range q.parked {
+		if p == parked {
+			q.parked = append(q.parked[:i], q.parked[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// expire drops parked when its timeout expires before there is room for it.
+func (q *notificationQueue) expire(parked *parkedNotification) {
+	if !q.unpark(parked) {
+		return
+	}
+	q.count(&q.dropped, "dropped", parked.notification.Method)
+	parked.result <- ErrNotificationChannelBlocked
+	if !parked.waiting && q.expired != nil {
+		q.expired(parked.notification.Method)
+	}
+}
+
+// append adds notification to the queue and wakes up run. q.mu must be held.
+func (q *notificationQueue) append(notification mcp.JSONRPCNotification) {
+	q.items = append(q.items, notification)
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// indexFunc returns the index of the first queued notification matching f,
+// or -1. q.mu must be held.
+func (q *notificationQueue) indexFunc(f func(mcp.JSONRPCNotification) bool) int {
+	for i, n := range q.items {
+		if f(n) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parkedIndexFunc returns the index of the first parked notification
+// matching f, or -1. q.mu must be held.
+func (q *notificationQueue) parkedIndexFunc(f func(mcp.JSONRPCNotification) bool) int {
+	for i, p := range q.parked {
+		if f(p.notification) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (q *notificationQueue) pop() (mcp.JSONRPCNotification, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return mcp.JSONRPCNotification{}, false
+	}
+	notification := q.items[0]
+	q.items = q.items[1:]
+	q.admitParked()
+	return notification, true
+}
+
+// run passes the queued notifications on to channel until the queue is
+// closed.
+func (q *notificationQueue) run(channel chan<- mcp.JSONRPCNotification) {
+	for {
+		select {
+		case <-q.ready:
+		case <-q.done:
+			return
+		}
+		for {
+			notification, ok := q.pop()
+			if !ok {
+				break
+			}
+			select {
+			case channel <- notification:
+				q.count(&q.delivered, "delivered", notification.Method)
+			case <-q.done:
+				return
+			}
+		}
+	}
+}
+
+// close stops the queue and discards the parked notifications.
+func (q *notificationQueue) close() {
+	q.closeOnce.Do(func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		close(q.done)
+		for _, parked := range q.parked {
+			if parked.timer != nil {
+				parked.timer.Stop()
+			}
+			parked.result <- ErrSessionNotFound
+		}
+		q.parked = nil
+	})
+}
+
+// count increments counter for a notification with the given method, and
+// reports the event to observe.
+func (q *notificationQueue) count(counter *atomic.Uint64, event, method string) {
+	counter.Add(1)
+	if q.observe != nil {
+		q.observe(event, method)
+	}
+}
+
+func (q *notificationQueue) stats() NotificationQueueStats {
+	q.mu.Lock()
+	pending := len(q.items) + len(q.parked)
+	q.mu.Unlock()
+	return NotificationQueueStats{
+		Pending:   pending,
+		Capacity:  q.size,
+		Delivered: q.delivered.Load(),
+		Dropped:   q.dropped.Load(),
+		Coalesced: q.coalesced.Load(),
+	}
+}
+
+// sameNotificationKey reports whether a and b are notifications of the same
+// method with the same value of the key parameter.
+func sameNotificationKey(a, b mcp.JSONRPCNotification, key string) bool {
+	if a.Method != b.Method {
+		return false
+	}
+	if key == "" {
+		return true
+	}
+	aValue, aOK := a.Params.AdditionalFields[key]
+	bValue, bOK := b.Params.AdditionalFields[key]
+	return aOK && bOK && reflect.DeepEqual(aValue, bValue)
+}
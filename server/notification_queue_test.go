@@ -0,0 +1,318 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func testNotification(method string, params map[string]any) mcp.JSONRPCNotification {
+	return mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: method,
+			Params: mcp.NotificationParams{AdditionalFields: params},
+		},
+	}
+}
+
+func queuedValues(q *notificationQueue) []any {
+	var values []any
+	for _, n := range q.items {
+		values = append(values, n.Params.AdditionalFields["value"])
+	}
+	return values
+}
+
+func TestNotificationQueue_Policies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("drop newest", func(t *testing.T) {
+		q := newNotificationQueue(2)
+		for i := range 2 {
+			_, err := q.push(ctx, testNotification("test", map[string]any{"value": i}), NotificationDelivery{})
+			require.NoError(t, err)
+		}
+		_, err := q.push(ctx, testNotification("test", map[string]any{"value": 2}), NotificationDelivery{})
+		assert.ErrorIs(t, err, ErrNotificationChannelBlocked)
+		assert.Equal(t, []any{0, 1}, queuedValues(q))
+		assert.Equal(t, uint64(1), q.stats().Dropped)
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		q := newNotificationQueue(2)
+		delivery := NotificationDelivery{Policy: NotificationDropOldest}
+		var evicted *mcp.JSONRPCNotification
+		for i := range 3 {
+			var err error
+			evicted, err = q.push(ctx, testNotification("test", map[string]any{"value": i}), delivery)
+			require.NoError(t, err)
+		}
+		require.NotNil(t, evicted)
+		assert.Equal(t, 0, evicted.Params.AdditionalFields["value"])
+		assert.Equal(t, []any{1, 2}, queuedValues(q))
+	})
+
+	t.Run("drop oldest keeps reliable notifications", func(t *testing.T) {
+		q := newNotificationQueue(3)
+		logs := NotificationDelivery{Policy: NotificationDropOldest}
+		updates := NotificationDelivery{Policy: NotificationBlock}
+		_, err := q.push(ctx, testNotification(mcp.MethodNotificationResourceUpdated, map[string]any{"value": "updated"}), updates)
+		require.NoError(t, err)
+		_, err = q.push(ctx, testNotification("other", map[string]any{"value": "other"}), NotificationDelivery{})
+		require.NoError(t, err)
+		for i := range 10 {
+			_, err := q.push(ctx, testNotification("notifications/message", map[string]any{"value": i}), logs)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, []any{"updated", "other", 9}, queuedValues(q))
+
+		// With nothing left that may be dropped, the log message is dropped.
+		q = newNotificationQueue(1)
+		_, err = q.push(ctx, testNotification(mcp.MethodNotificationResourceUpdated, map[string]any{"value": "updated"}), updates)
+		require.NoError(t, err)
+		evicted, err := q.push(ctx, testNotification("notifications/message", map[string]any{"value": 0}), logs)
+		assert.ErrorIs(t, err, ErrNotificationChannelBlocked)
+		assert.Nil(t, evicted)
+		assert.Equal(t, []any{"updated"}, queuedValues(q))
+
+		// Other lossy notifications make room when there is no older log
+		// message.
+		q = newNotificationQueue(1)
+		_, err = q.push(ctx, testNotification("list_changed", map[string]any{"value": "changed"}), NotificationDelivery{Policy: NotificationDeduplicate})
+		require.NoError(t, err)
+		evicted, err = q.push(ctx, testNotification("notifications/message", map[string]any{"value": 0}), logs)
+		require.NoError(t, err)
+		require.NotNil(t, evicted)
+		assert.Equal(t, "list_changed", evicted.Method)
+		assert.Equal(t, []any{0}, queuedValues(q))
+	})
+
+	t.Run("coalesce", func(t *testing.T) {
+		q := newNotificationQueue(10)
+		delivery := NotificationDelivery{Policy: NotificationCoalesce, Key: "token"}
+		for i := range 3 {
+			_, err := q.push(ctx, testNotification("progress", map[string]any{"token": "a", "value": i}), delivery)
+			require.NoError(t, err)
+		}
+		_, err := q.push(ctx, testNotification("progress", map[string]any{"token": "b", "value": 10}), delivery)
+		require.NoError(t, err)
+		assert.Equal(t, []any{2, 10}, queuedValues(q))
+		assert.Equal(t, uint64(2), q.stats().Coalesced)
+	})
+
+	t.Run("deduplicate", func(t *testing.T) {
+		q := newNotificationQueue(10)
+		delivery := NotificationDelivery{Policy: NotificationDeduplicate}
+		for range 3 {
+			_, err := q.push(ctx, testNotification("list_changed", nil), delivery)
+			require.NoError(t, err)
+		}
+		assert.Len(t, q.items, 1)
+		assert.Equal(t, uint64(2), q.stats().Coalesced)
+	})
+
+	t.Run("block", func(t *testing.T) {
+		q := newNotificationQueue(1)
+		delivery := NotificationDelivery{Policy: NotificationBlock, Timeout: 20 * time.Millisecond}
+		_, err := q.push(ctx, testNotification("test", map[string]any{"value": 0}), delivery)
+		require.NoError(t, err)
+
+		_, err = q.push(ctx, testNotification("test", map[string]any{"value": 1}), delivery)
+		assert.ErrorIs(t, err, ErrNotificationChannelBlocked)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			q.pop()
+		}()
+		delivery.Timeout = time.Second
+		_, err = q.push(ctx, testNotification("test", map[string]any{"value": 2}), delivery)
+		require.NoError(t, err)
+		assert.Equal(t, []any{2}, queuedValues(q))
+
+		q.close()
+		_, err = q.push(ctx, testNotification("test", nil), delivery)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
+
+func TestMCPServer_NotificationQueue(t *testing.T) {
+	var blocked atomic.Int32
+	hooks := &Hooks{}
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		if errors.Is(err, ErrNotificationChannelBlocked) {
+			blocked.Add(1)
+		}
+	})
+	metrics := NewMetrics()
+	s := NewMCPServer("test", "1.0.0",
+		WithHooks(hooks),
+		WithMetrics(metrics),
+		WithNotificationQueue(4),
+		WithNotificationDelivery("custom", NotificationDelivery{Policy: NotificationDropOldest}),
+	)
+
+	// Nobody reads the unbuffered channel until the notifications are queued.
+	channel := make(chan mcp.JSONRPCNotification)
+	session := fakeSession{sessionID: "queued", notificationChannel: channel, initialized: true}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+
+	for i := range 5 {
+		require.NoError(t, s.SendNotificationToSpecificClient("queued", "notifications/progress",
+			map[string]any{"progressToken": "token", "progress": i}))
+	}
+	for range 3 {
+		s.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+	}
+	for i := range 6 {
+		require.NoError(t, s.SendNotificationToSpecificClient("queued", "custom", map[string]any{"value": i}))
+	}
+
+	stats, ok := s.NotificationQueueStats("queued")
+	require.True(t, ok)
+	assert.Equal(t, 4, stats.Capacity)
+	assert.LessOrEqual(t, stats.Pending, 4)
+	assert.NotZero(t, stats.Dropped)
+	assert.NotZero(t, stats.Coalesced)
+	require.Eventually(t, func() bool { return blocked.Load() == int32(stats.Dropped) }, time.Second, 10*time.Millisecond)
+
+	var received []mcp.JSONRPCNotification
+	for {
+		select {
+		case n := <-channel:
+			received = append(received, n)
+			continue
+		case <-time.After(50 * time.Millisecond):
+		}
+		break
+	}
+	// The newest custom notifications survive.
+	require.NotEmpty(t, received)
+	last := received[len(received)-1]
+	assert.Equal(t, "custom", last.Method)
+	assert.Equal(t, 5, last.Params.AdditionalFields["value"])
+
+	delivered := 0
+	for _, n := range received {
+		if n.Method == "custom" {
+			delivered++
+		}
+	}
+	text := metricsText(t, metrics)
+	assert.Contains(t, text, `mcp_notification_queue_delivered_total{transport="other",method="custom"} `+strconv.Itoa(delivered)+"\n")
+	assert.Contains(t, text, `mcp_notification_queue_coalesced_total{transport="other",method="notifications/progress"} `)
+	// Queue metrics are not labelled by session.
+	assert.NotContains(t, text, `session=`)
+
+	s.UnregisterSession(context.Background(), "queued")
+	_, ok = s.NotificationQueueStats("queued")
+	assert.False(t, ok)
+}
+
+func TestMCPServer_NotificationQueue_Coalesce(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithNotificationQueue(10))
+	channel := make(chan mcp.JSONRPCNotification)
+	session := fakeSession{sessionID: "progress", notificationChannel: channel, initialized: true}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+
+	for i := range 10 {
+		require.NoError(t, s.SendNotificationToSpecificClient("progress", "notifications/progress",
+			map[string]any{"progressToken": "token", "progress": i}))
+	}
+
+	// At most one notification was taken by the channel before the others
+	// were coalesced into the latest value.
+	var progress []any
+	for len(progress) == 0 || progress[len(progress)-1] != 9 {
+		select {
+		case n := <-channel:
+			progress = append(progress, n.Params.AdditionalFields["progress"])
+		case <-time.After(time.Second):
+			t.Fatalf("latest progress not received, got %v", progress)
+		}
+	}
+	assert.LessOrEqual(t, len(progress), 2)
+}
+
+func TestMCPServer_NotificationQueue_BlockingBroadcast(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0",
+		WithNotificationQueue(1),
+		WithNotificationDelivery(mcp.MethodNotificationTasksStatus, NotificationDelivery{Policy: NotificationBlock, Timeout: time.Minute}),
+	)
+	channel := make(chan mcp.JSONRPCNotification)
+	session := fakeSession{sessionID: "blocked", notificationChannel: channel, initialized: true}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+
+	// The channel is never read, so the queue fills up. Broadcasts must not
+	// wait for room, since they are sent while holding the task lock.
+	start := time.Now()
+	for i := range 5 {
+		s.SendNotificationToAllClients(mcp.MethodNotificationTasksStatus, map[string]any{"taskId": strconv.Itoa(i)})
+	}
+	assert.Less(t, time.Since(start), time.Second)
+
+	s.UnregisterSession(context.Background(), "blocked")
+}
+
+func TestMCPServer_NotificationQueue_BroadcastOrder(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0",
+		WithNotificationQueue(2),
+		WithNotificationDelivery(mcp.MethodNotificationTasksStatus, NotificationDelivery{Policy: NotificationBlock, Timeout: time.Minute}),
+	)
+	channel := make(chan mcp.JSONRPCNotification)
+	session := fakeSession{sessionID: "slow", notificationChannel: channel, initialized: true}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	defer s.UnregisterSession(context.Background(), "slow")
+
+	// The queue fills up, so the later statuses wait for room without
+	// blocking the broadcasts, and must still arrive in order.
+	statuses := []string{"working", "input_required", "working", "completed"}
+	for _, status := range statuses {
+		s.SendNotificationToAllClients(mcp.MethodNotificationTasksStatus, map[string]any{"taskId": "task", "status": status})
+	}
+	stats, ok := s.NotificationQueueStats("slow")
+	require.True(t, ok)
+	assert.Equal(t, uint64(0), stats.Dropped)
+
+	var received []any
+	for range statuses {
+		select {
+		case n := <-channel:
+			received = append(received, n.Params.AdditionalFields["status"])
+		case <-time.After(time.Second):
+			t.Fatalf("statuses not received, got %v", received)
+		}
+	}
+	assert.Equal(t, []any{"working", "input_required", "working", "completed"}, received)
+}
+
+func TestNotificationQueue_ParkedExpire(t *testing.T) {
+	q := newNotificationQueue(1)
+	expired := make(chan string, 1)
+	q.expired = func(method string) {
+		expired <- method
+	}
+	delivery := NotificationDelivery{Policy: NotificationBlock, Timeout: 10 * time.Millisecond}
+	_, err := q.offer(testNotification("first", nil), delivery)
+	require.NoError(t, err)
+	_, err = q.offer(testNotification("second", nil), delivery)
+	require.NoError(t, err)
+	assert.Equal(t, 2, q.stats().Pending)
+
+	select {
+	case method := <-expired:
+		assert.Equal(t, "second", method)
+	case <-time.After(time.Second):
+		t.Fatal("parked notification did not expire")
+	}
+	assert.Equal(t, uint64(1), q.stats().Dropped)
+	assert.Equal(t, 1, q.stats().Pending)
+}
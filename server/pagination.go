@@ -0,0 +1,218 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// cursorFormat is the version of the encoded cursor payload. It is bumped
+// whenever the payload changes incompatibly so old cursors are rejected.
+const cursorFormat = 2
+
+// paginationCursor is the payload of an opaque pagination cursor.
+type paginationCursor struct {
+	// Format is the cursorFormat the cursor was encoded with.
+	Format int `json:"f"`
+	// Kind identifies the list the cursor was issued for, e.g. "mcp.Tool".
+	Kind string `json:"k"`
+	// After is the key of the last element of the previous page, see
+	// paginationKey.
+	After string `json:"a"`
+	// Version is a fingerprint of the list at the time the cursor was issued.
+	Version uint64 `json:"v"`
+	// PageSize carries the client's page size hint over to the next page.
+	PageSize int `json:"n,omitempty"`
+}
+
+// WithPaginationCursorKey signs pagination cursors with HMAC-SHA256 using key.
+// Cursors that were not issued by a server holding the same key are rejected
+// with an INVALID_PARAMS error, so clients cannot forge positions in a list.
+func WithPaginationCursorKey(key []byte) ServerOption {
+	return func(s *MCPServer) {
+		s.paginationKey = slices.Clone(key)
+	}
+}
+
+// WithPaginationSnapshots binds every cursor to the version of the list it was
+// issued for. If the list changes between two pages, the next request fails
+// with an INVALID_PARAMS error wrapping ErrStaleCursor and the client has to
+// start again from the first page. Without this option, cursors survive list
+// mutations and resume after the last element the client has seen.
+func WithPaginationSnapshots() ServerOption {
+	return func(s *MCPServer) {
+		s.paginationSnapshots = true
+	}
+}
+
+// listByPagination sorts allElements by their paginationKey and returns the
+// page selected by the cursor and page size hint in params, along with the
+// cursor of the next page.
+func listByPagination[T mcp.Named](
+	_ context.Context,
+	s *MCPServer,
+	params mcp.PaginatedParams,
+	allElements []T,
+) ([]T, mcp.Cursor, error) {
+	slices.SortFunc(allElements, func(a, b T) int {
+		return cmp.Compare(paginationKey(a), paginationKey(b))
+	})
+
+	kind := fmt.Sprintf("%T", *new(T))
+	version := listVersion(allElements)
+	pageSize := pageSizeHint(params.Meta)
+
+	startPos := 0
+	if params.Cursor != "" {
+		c, err := s.decodeCursor(params.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if c.Kind != kind {
+			return nil, "", fmt.Errorf("%w: cursor was issued for a different list", ErrInvalidCursor)
+		}
+		if s.paginationSnapshots && c.Version != version {
+			return nil, "", ErrStaleCursor
+		}
+		if pageSize == 0 {
+			pageSize = c.PageSize
+		}
+		startPos = sort.Search(len(allElements), func(i int) bool {
+			return paginationKey(allElements[i]) > c.After
+		})
+	}
+
+	limit := pageSize
+	if s.paginationLimit != nil && (limit == 0 || limit > *s.paginationLimit) {
+		limit = *s.paginationLimit
+	}
+
+	endPos := len(allElements)
+	if limit > 0 && len(allElements) > startPos+limit {
+		endPos = startPos + limit
+	}
+	elementsToReturn := allElements[startPos:endPos]
+
+	if limit == 0 || len(elementsToReturn) < limit {
+		return elementsToReturn, "", nil
+	}
+	nextCursor, err := s.encodeCursor(paginationCursor{
+		Format:   cursorFormat,
+		Kind:     kind,
+		After:    paginationKey(elementsToReturn[len(elementsToReturn)-1]),
+		Version:  version,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return elementsToReturn, nextCursor, nil
+}
+
+// paginationKey returns the key element is ordered by in a paginated list.
+// It must be unique within the list: resources and resource templates are
+// keyed by URI and URI template, since their names may repeat, and other
+// elements by name.
+func paginationKey[T mcp.Named](element T) string {
+	switch e := any(element).(type) {
+	case mcp.Resource:
+		return e.URI
+	case mcp.ResourceTemplate:
+		if e.URITemplate != nil && e.URITemplate.Template != nil {
+			return e.URITemplate.Raw()
+		}
+	}
+	return element.GetName()
+}
+
+// listVersion fingerprints the keys of a sorted list.
+func listVersion[T mcp.Named](elements []T) uint64 {
+	h := fnv.New64a()
+	for _, element := range elements {
+		h.Write([]byte(paginationKey(element)))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// pageSizeHint extracts the PageSizeMetaKey hint from request metadata.
+// Missing or non-positive hints return 0, meaning no preference.
+func pageSizeHint(meta *mcp.Meta) int {
+	if meta == nil {
+		return 0
+	}
+	var size float64
+	switch v := meta.AdditionalFields[mcp.PageSizeMetaKey].(type) {
+	case float64:
+		size = v
+	case int:
+		size = float64(v)
+	case int64:
+		size = float64(v)
+	case json.Number:
+		size, _ = v.Float64()
+	}
+	if size < 1 || size > math.MaxInt32 {
+		return 0
+	}
+	return int(size)
+}
+
+// encodeCursor serializes c as an opaque cursor, appending an HMAC signature
+// when a cursor key has been configured.
+func (s *MCPServer) encodeCursor(c paginationCursor) (mcp.Cursor, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	cursor := base64.RawURLEncoding.EncodeToString(payload)
+	if s.paginationKey != nil {
+		cursor += "." + base64.RawURLEncoding.EncodeToString(s.signCursor(payload))
+	}
+	return mcp.Cursor(cursor), nil
+}
+
+// decodeCursor parses and verifies a cursor produced by encodeCursor.
+func (s *MCPServer) decodeCursor(cursor mcp.Cursor) (paginationCursor, error) {
+	encoded, signature, signed := strings.Cut(string(cursor), ".")
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return paginationCursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	if s.paginationKey != nil {
+		mac, err := base64.RawURLEncoding.DecodeString(signature)
+		if !signed || err != nil || !hmac.Equal(mac, s.signCursor(payload)) {
+			return paginationCursor{}, fmt.Errorf("%w: signature mismatch", ErrInvalidCursor)
+		}
+	} else if signed {
+		return paginationCursor{}, fmt.Errorf("%w: unexpected signature", ErrInvalidCursor)
+	}
+
+	var c paginationCursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return paginationCursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if c.Format != cursorFormat {
+		return paginationCursor{}, fmt.Errorf("%w: unsupported format %d", ErrInvalidCursor, c.Format)
+	}
+	return c, nil
+}
+
+// signCursor computes the HMAC-SHA256 of a cursor payload.
+func (s *MCPServer) signCursor(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.paginationKey)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func newPaginationTestServer(t *testing.T, opts ...ServerOption) *MCPServer {
+	t.Helper()
+	server := NewMCPServer("test-server", "1.0.0", append([]ServerOption{
+		WithToolCapabilities(false),
+		WithPromptCapabilities(false),
+		WithPaginationLimit(5),
+	}, opts...)...)
+	for i := range 12 {
+		server.AddTool(mcp.NewTool(fmt.Sprintf("tool-%02d", i)), nil)
+	}
+	return server
+}
+
+func listToolsPage(t *testing.T, server *MCPServer, params mcp.PaginatedParams) mcp.JSONRPCMessage {
+	t.Helper()
+	request, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/list",
+		"params":  params,
+	})
+	require.NoError(t, err)
+	return server.HandleMessage(context.Background(), request)
+}
+
+func requireToolsPage(t *testing.T, response mcp.JSONRPCMessage) mcp.ListToolsResult {
+	t.Helper()
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected response, got %#v", response)
+	result, ok := resp.Result.(mcp.ListToolsResult)
+	require.True(t, ok)
+	return result
+}
+
+func requireInvalidParams(t *testing.T, response mcp.JSONRPCMessage) string {
+	t.Helper()
+	errorResponse, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "expected error, got %#v", response)
+	assert.Equal(t, mcp.INVALID_PARAMS, errorResponse.Error.Code)
+	return errorResponse.Error.Message
+}
+
+func toolNames(tools []mcp.Tool) []string {
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	return names
+}
+
+func TestPagination_WalksAllPages(t *testing.T) {
+	server := newPaginationTestServer(t)
+
+	var names []string
+	var cursor mcp.Cursor
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "pagination did not terminate")
+		result := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{Cursor: cursor}))
+		names = append(names, toolNames(result.Tools)...)
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	assert.Len(t, names, 12)
+	assert.Equal(t, "tool-00", names[0])
+	assert.Equal(t, "tool-11", names[11])
+}
+
+func TestPagination_CursorSurvivesMutation(t *testing.T) {
+	server := newPaginationTestServer(t)
+
+	first := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{}))
+	require.Equal(t, "tool-04", first.Tools[4].Name)
+
+	server.DeleteTools("tool-05")
+	server.AddTool(mcp.NewTool("tool-00a"), nil)
+
+	second := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{Cursor: first.NextCursor}))
+	assert.Equal(t, []string{"tool-06", "tool-07", "tool-08", "tool-09", "tool-10"}, toolNames(second.Tools))
+}
+
+func TestPagination_Snapshots(t *testing.T) {
+	server := newPaginationTestServer(t, WithPaginationSnapshots())
+
+	first := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{}))
+	second := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{Cursor: first.NextCursor}))
+	assert.Equal(t, "tool-05", second.Tools[0].Name)
+
+	server.AddTool(mcp.NewTool("tool-99"), nil)
+
+	message := requireInvalidParams(t, listToolsPage(t, server, mcp.PaginatedParams{Cursor: second.NextCursor}))
+	assert.Contains(t, message, ErrStaleCursor.Error())
+}
+
+func TestPagination_SignedCursors(t *testing.T) {
+	server := newPaginationTestServer(t, WithPaginationCursorKey([]byte("secret")))
+
+	first := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{}))
+	require.NotEmpty(t, first.NextCursor)
+
+	second := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{Cursor: first.NextCursor}))
+	assert.Equal(t, "tool-05", second.Tools[0].Name)
+
+	t.Run("forged payload", func(t *testing.T) {
+		unsigned := NewMCPServer("other", "1.0.0")
+		forged, err := unsigned.encodeCursor(paginationCursor{Format: cursorFormat, Kind: "mcp.Tool", After: "tool-09"})
+		require.NoError(t, err)
+
+		message := requireInvalidParams(t, listToolsPage(t, server, mcp.PaginatedParams{Cursor: forged}))
+		assert.Contains(t, message, ErrInvalidCursor.Error())
+	})
+
+	t.Run("different key", func(t *testing.T) {
+		other := newPaginationTestServer(t, WithPaginationCursorKey([]byte("other-secret")))
+		message := requireInvalidParams(t, listToolsPage(t, other, mcp.PaginatedParams{Cursor: first.NextCursor}))
+		assert.Contains(t, message, "signature mismatch")
+	})
+
+	t.Run("signed cursor on unsigned server", func(t *testing.T) {
+		unsigned := newPaginationTestServer(t)
+		requireInvalidParams(t, listToolsPage(t, unsigned, mcp.PaginatedParams{Cursor: first.NextCursor}))
+	})
+}
+
+func TestPagination_DuplicateResourceNames(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0",
+		WithResourceCapabilities(false, false),
+		WithPaginationLimit(5),
+	)
+	for i := range 6 {
+		// Resources and templates of different roots may share a name.
+		server.AddResource(mcp.NewResource(fmt.Sprintf("file:///root-%d/README.md", i), "README.md"), nil)
+		server.AddResourceTemplate(mcp.NewResourceTemplate(fmt.Sprintf("file:///root-%d/{path}", i), "files"), nil)
+	}
+
+	list := func(method string, cursor mcp.Cursor) (any, mcp.Cursor) {
+		t.Helper()
+		request, err := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  method,
+			"params":  mcp.PaginatedParams{Cursor: cursor},
+		})
+		require.NoError(t, err)
+		resp, ok := server.HandleMessage(context.Background(), request).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		switch result := resp.Result.(type) {
+		case mcp.ListResourcesResult:
+			return result.Resources, result.NextCursor
+		case mcp.ListResourceTemplatesResult:
+			return result.ResourceTemplates, result.NextCursor
+		}
+		t.Fatalf("unexpected result %#v", resp.Result)
+		return nil, ""
+	}
+
+	var uris []string
+	var cursor mcp.Cursor
+	for pages := 0; pages == 0 || cursor != ""; pages++ {
+		require.Less(t, pages, 3, "pagination did not terminate")
+		var resources any
+		resources, cursor = list("resources/list", cursor)
+		for _, resource := range resources.([]mcp.Resource) {
+			uris = append(uris, resource.URI)
+		}
+	}
+	assert.Len(t, uris, 6)
+	assert.Equal(t, "file:///root-5/README.md", uris[5])
+
+	var templates []string
+	for pages := 0; pages == 0 || cursor != ""; pages++ {
+		require.Less(t, pages, 3, "pagination did not terminate")
+		var page any
+		page, cursor = list("resources/templates/list", cursor)
+		for _, template := range page.([]mcp.ResourceTemplate) {
+			templates = append(templates, template.URITemplate.Raw())
+		}
+	}
+	assert.Len(t, templates, 6)
+	assert.Equal(t, "file:///root-5/{path}", templates[5])
+}
+
+func TestPagination_CursorForDifferentList(t *testing.T) {
+	server := newPaginationTestServer(t)
+	for i := range 6 {
+		server.AddPrompt(mcp.NewPrompt(fmt.Sprintf("prompt-%d", i)), nil)
+	}
+
+	tools := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{}))
+	require.NotEmpty(t, tools.NextCursor)
+
+	response := server.HandleMessage(context.Background(), fmt.Appendf(nil, `{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "prompts/list",
+		"params": {"cursor": %q}
+	}`, tools.NextCursor))
+	message := requireInvalidParams(t, response)
+	assert.Contains(t, message, "different list")
+}
+
+func TestPagination_PageSizeHint(t *testing.T) {
+	server := newPaginationTestServer(t)
+
+	first := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{Meta: mcp.WithPageSize(3)}))
+	assert.Equal(t, []string{"tool-00", "tool-01", "tool-02"}, toolNames(first.Tools))
+
+	// The hint is carried over by the cursor.
+	second := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{Cursor: first.NextCursor}))
+	assert.Equal(t, []string{"tool-03", "tool-04", "tool-05"}, toolNames(second.Tools))
+
+	// A hint never raises the server limit.
+	large := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{Meta: mcp.WithPageSize(100)}))
+	assert.Len(t, large.Tools, 5)
+
+	// Invalid hints are ignored.
+	invalid := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{Meta: mcp.WithPageSize(-1)}))
+	assert.Len(t, invalid.Tools, 5)
+}
+
+func TestPagination_PageSizeHintWithoutLimit(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithToolCapabilities(false))
+	for i := range 4 {
+		server.AddTool(mcp.NewTool(fmt.Sprintf("tool-%d", i)), nil)
+	}
+
+	all := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{}))
+	assert.Len(t, all.Tools, 4)
+	assert.Empty(t, all.NextCursor)
+
+	page := requireToolsPage(t, listToolsPage(t, server, mcp.PaginatedParams{Meta: mcp.WithPageSize(3)}))
+	assert.Len(t, page.Tools, 3)
+	assert.NotEmpty(t, page.NextCursor)
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Ping sends a ping request to the client of the session with the given ID
+// and waits for the response. It returns ErrSessionNotFound if the session
+// is not registered, and an error wrapping ErrSessionDoesNotSupportPing if
+// the transport cannot send requests to the client.
+func (s *MCPServer) Ping(ctx context.Context, sessionID string) error {
+	value, ok := s.sessions.Load(sessionID)
+	if !ok {
+		return ErrSessionNotFound
+	}
+	session, ok := value.(SessionWithPing)
+	if !ok {
+		return ErrSessionDoesNotSupportPing
+	}
+	return session.Ping(ctx)
+}
+
+// WithLivenessMonitor pings the sessions that have not sent anything for
+// interval, and removes the sessions that fail to answer maxMissed pings in
+// a row. Each ping waits up to interval for the response.
+//
+// A removed session is terminated by its transport, which closes its
+// stream, and unregistered, which calls the OnUnregisterSession hooks and
+// frees its per-session tools and resources. Sessions that cannot be pinged,
+// such as streamable HTTP sessions without a listening GET stream, are left
+// to the transport's own idle handling.
+//
+// The monitor starts when the first session is registered and stops when
+// Shutdown is called.
+func WithLivenessMonitor(interval time.Duration, maxMissed int) ServerOption {
+	return func(s *MCPServer) {
+		if interval <= 0 || maxMissed <= 0 {
+			return
+		}
+		s.liveness = &livenessMonitor{
+			interval:  interval,
+			maxMissed: maxMissed,
+			missed:    make(map[string]int),
+			stop:      make(chan struct{}),
+		}
+	}
+}
+
+// livenessMonitor pings idle sessions and removes the ones that stop
+// answering.
+type livenessMonitor struct {
+	interval  time.Duration
+	maxMissed int
+	// missed counts the pings each session missed in a row. It is only
+	// used by the monitor goroutine.
+	missed    map[string]int
+	stop      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// start starts the monitor goroutine unless it is already running.
+func (m *livenessMonitor) start(s *MCPServer) {
+	m.startOnce.Do(func() {
+		go m.run(s)
+	})
+}
+
+func (m *livenessMonitor) run(s *MCPServer) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.check(s)
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+func (m *livenessMonitor) close() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+// check pings the idle sessions and removes the ones that missed too many
+// pings.
+func (m *livenessMonitor) check(s *MCPServer) {
+	now := time.Now()
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make(map[string]error)
+	)
+	s.sessions.Range(func(_, value any) bool {
+		session, ok := value.(SessionWithPing)
+		if !ok || !session.Initialized() {
+			return true
+		}
+		sessionID := session.SessionID()
+		if active, ok := session.(sessionWithLastActivity); ok && now.Sub(active.lastActivity()) < m.interval {
+			delete(m.missed, sessionID)
+			return true
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), m.interval)
+			defer cancel()
+			err := session.Ping(ctx)
+			mu.Lock()
+			results[sessionID] = err
+			mu.Unlock()
+		}()
+		return true
+	})
+	wg.Wait()
+
+	for sessionID, err := range results {
+		if err == nil || errors.Is(err, ErrSessionDoesNotSupportPing) {
+			delete(m.missed, sessionID)
+			continue
+		}
+		m.missed[sessionID]++
+		if m.missed[sessionID] >= m.maxMissed {
+			delete(m.missed, sessionID)
+			s.terminateSession(context.Background(), sessionID)
+		}
+	}
+	for sessionID := range m.missed {
+		if _, ok := s.sessions.Load(sessionID); !ok {
+			delete(m.missed, sessionID)
+		}
+	}
+}
+
+// sessionTerminator ends the session with the given ID and releases its
+// transport state, reporting whether the session belonged to the transport.
+type sessionTerminator func(ctx context.Context, sessionID string) bool
+
+// addSessionTerminator registers the terminator of a transport serving s. It
+// returns a function that removes it again, which the transport calls once it
+// stops serving s.
+func (s *MCPServer) addSessionTerminator(terminate sessionTerminator) func() {
+	entry := &terminate
+	s.sessionTerminatorsMu.Lock()
+	defer s.sessionTerminatorsMu.Unlock()
+	s.sessionTerminators = append(s.sessionTerminators, entry)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.sessionTerminatorsMu.Lock()
+			defer s.sessionTerminatorsMu.Unlock()
+			// terminateSession iterates over the slice without the lock, so
+			// it is replaced rather than changed in place.
+			s.sessionTerminators = slices.DeleteFunc(slices.Clone(s.sessionTerminators), func(t *sessionTerminator) bool {
+				return t == entry
+			})
+		})
+	}
+}
+
+// terminateSession ends the session with the given ID through the transport
+// serving it, and unregisters it.
+func (s *MCPServer) terminateSession(ctx context.Context, sessionID string) {
+	s.sessionTerminatorsMu.RLock()
+	terminators := s.sessionTerminators
+	s.sessionTerminatorsMu.RUnlock()
+	for _, terminate := range terminators {
+		if (*terminate)(ctx, sessionID) {
+			break
+		}
+	}
+	s.UnregisterSession(ctx, sessionID)
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// nextPingID reads messages from lines until it finds a ping request and
+// returns its ID. Lines of SSE streams are stripped of their data: prefix.
+func nextPingID(t *testing.T, lines *bufio.Scanner) int64 {
+	t.Helper()
+	for lines.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(lines.Text(), "data:"))
+		var request struct {
+			ID     int64  `json:"id"`
+			Method string `json:"method"`
+		}
+		if json.Unmarshal([]byte(line), &request) == nil && request.Method == string(mcp.MethodPing) {
+			return request.ID
+		}
+	}
+	t.Fatalf("no ping request received: %v", lines.Err())
+	return 0
+}
+
+func pingResponse(id int64) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{}}`, id)
+}
+
+func TestMCPServer_Ping(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	ctx := context.Background()
+
+	assert.ErrorIs(t, s.Ping(ctx, "missing"), ErrSessionNotFound)
+
+	session := fakeSession{sessionID: "fake", notificationChannel: make(chan mcp.JSONRPCNotification, 1), initialized: true}
+	require.NoError(t, s.RegisterSession(ctx, session))
+	assert.ErrorIs(t, s.Ping(ctx, "fake"), ErrSessionDoesNotSupportPing)
+}
+
+func TestStdioServer_Ping(t *testing.T) {
+	mcpServer := NewMCPServer("test", "1.0.0")
+	stdioServer := NewStdioServer(mcpServer)
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	defer stdinWriter.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = stdioServer.Listen(ctx, stdinReader, stdoutWriter)
+	}()
+
+	sessionID := stdioServer.session.SessionID()
+	require.Eventually(t, func() bool {
+		_, ok := mcpServer.sessions.Load(sessionID)
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	pingCtx, pingCancel := context.WithTimeout(ctx, 2*time.Second)
+	defer pingCancel()
+	lines := bufio.NewScanner(stdoutReader)
+	for range 2 {
+		pinged := make(chan error, 1)
+		go func() {
+			pinged <- mcpServer.Ping(pingCtx, sessionID)
+		}()
+		id := nextPingID(t, lines)
+		_, err := io.WriteString(stdinWriter, pingResponse(id)+"\n")
+		require.NoError(t, err)
+		require.NoError(t, <-pinged)
+	}
+}
+
+func TestStreamableHTTPServer_Ping(t *testing.T) {
+	mcpServer := NewMCPServer("test", "1.0.0")
+	httpServer := NewStreamableHTTPServer(mcpServer, WithStateful(true))
+	server := httptest.NewServer(httpServer)
+	defer server.Close()
+
+	sessionID := initializeStreamableSession(t, server.URL)
+
+	// Without a listening stream the client cannot be reached.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	assert.ErrorIs(t, mcpServer.Ping(ctx, sessionID), ErrSessionDoesNotSupportPing)
+
+	stream := openStreamableStream(t, server.URL, sessionID)
+	defer stream.Body.Close()
+
+	pinged := make(chan error, 1)
+	go func() {
+		pinged <- mcpServer.Ping(ctx, sessionID)
+	}()
+
+	id := nextPingID(t, bufio.NewScanner(stream.Body))
+	resp := postStreamable(t, server.URL, sessionID, pingResponse(id))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	require.NoError(t, <-pinged)
+}
+
+func TestSSEServer_Ping(t *testing.T) {
+	mcpServer := NewMCPServer("test", "1.0.0")
+	testServer := NewTestServer(mcpServer)
+	defer testServer.Close()
+
+	stream, err := http.Get(testServer.URL + "/sse")
+	require.NoError(t, err)
+	defer stream.Body.Close()
+
+	lines := bufio.NewScanner(stream.Body)
+	var endpoint string
+	for endpoint == "" && lines.Scan() {
+		if strings.HasPrefix(lines.Text(), "data: ") {
+			endpoint = strings.TrimSpace(strings.TrimPrefix(lines.Text(), "data: "))
+		}
+	}
+	require.NotEmpty(t, endpoint)
+	sessionID := endpoint[strings.Index(endpoint, "sessionId=")+len("sessionId="):]
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	pinged := make(chan error, 1)
+	go func() {
+		pinged <- mcpServer.Ping(ctx, sessionID)
+	}()
+
+	id := nextPingID(t, lines)
+	resp, err := http.Post(endpoint, "application/json", strings.NewReader(pingResponse(id)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	require.NoError(t, <-pinged)
+}
+
+func TestMCPServer_LivenessMonitor(t *testing.T) {
+	unregistered := make(chan string, 1)
+	hooks := &Hooks{}
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session ClientSession) {
+		unregistered <- session.SessionID()
+	})
+	mcpServer := NewMCPServer("test", "1.0.0",
+		WithHooks(hooks),
+		WithToolCapabilities(true),
+		WithLivenessMonitor(20*time.Millisecond, 2),
+	)
+	defer func() {
+		_ = mcpServer.Shutdown(context.Background())
+	}()
+	httpServer := NewStreamableHTTPServer(mcpServer, WithStateful(true))
+	server := httptest.NewServer(httpServer)
+	defer server.Close()
+
+	sessionID := initializeStreamableSession(t, server.URL)
+	require.NoError(t, mcpServer.AddSessionTool(sessionID, mcp.NewTool("session-tool"),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), nil
+		}))
+
+	// The client keeps its stream open but never answers the pings.
+	stream := openStreamableStream(t, server.URL, sessionID)
+	defer stream.Body.Close()
+
+	select {
+	case id := <-unregistered:
+		assert.Equal(t, sessionID, id)
+	case <-time.After(2 * time.Second):
+		t.Fatal("unresponsive session was not unregistered")
+	}
+	_, ok := httpServer.activeSessions.Load(sessionID)
+	assert.False(t, ok)
+	assert.Empty(t, httpServer.sessionTools.get(sessionID))
+
+	// The listening stream is closed.
+	_, err := io.ReadAll(stream.Body)
+	require.NoError(t, err)
+}
+
+func TestStdioServer_LivenessMonitor(t *testing.T) {
+	mcpServer := NewMCPServer("test", "1.0.0", WithLivenessMonitor(20*time.Millisecond, 2))
+	defer func() {
+		_ = mcpServer.Shutdown(context.Background())
+	}()
+	stdioServer := NewStdioServer(mcpServer)
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	defer stdinWriter.Close()
+	// The client reads the pings but never answers them.
+	go func() {
+		_, _ = io.Copy(io.Discard, stdoutReader)
+	}()
+	listened := make(chan error, 1)
+	go func() {
+		listened <- stdioServer.Listen(context.Background(), stdinReader, stdoutWriter)
+	}()
+	_, err := io.WriteString(stdinWriter,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`+"\n")
+	require.NoError(t, err)
+
+	select {
+	case err := <-listened:
+		assert.ErrorIs(t, err, ErrSessionTerminated)
+	case <-time.After(2 * time.Second):
+		t.Fatal("unresponsive stdio session was not terminated")
+	}
+	_, ok := mcpServer.sessions.Load(stdioServer.session.SessionID())
+	assert.False(t, ok)
+}
+
+func initializeStreamableSession(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Post(url, "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(HeaderKeySessionID)
+	require.NotEmpty(t, sessionID)
+	return sessionID
+}
+
+func openStreamableStream(t *testing.T, url, sessionID string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set(HeaderKeySessionID, sessionID)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return resp
+}
+
+func postStreamable(t *testing.T, url, sessionID, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderKeySessionID, sessionID)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestMCPServer_SessionTerminatorsRemoved(t *testing.T) {
+	mcpServer := NewMCPServer("test", "1.0.0", WithLivenessMonitor(time.Minute, 1))
+	terminators := func() int {
+		mcpServer.sessionTerminatorsMu.RLock()
+		defer mcpServer.sessionTerminatorsMu.RUnlock()
+		return len(mcpServer.sessionTerminators)
+	}
+
+	for range 3 {
+		stdioServer := NewStdioServer(mcpServer)
+		require.NoError(t, stdioServer.Listen(context.Background(), strings.NewReader(""), io.Discard))
+	}
+	assert.Zero(t, terminators(), "stdio servers that stopped listening")
+
+	httpServer := NewStreamableHTTPServer(mcpServer)
+	sseServer := NewSSEServer(mcpServer)
+	assert.Equal(t, 2, terminators())
+	require.NoError(t, httpServer.Shutdown(context.Background()))
+	require.NoError(t, sseServer.Shutdown(context.Background()))
+	assert.Zero(t, terminators(), "shut down servers")
+}
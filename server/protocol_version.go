@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Protocol versions that introduced fields older clients do not know.
+const (
+	// protocolVersionDefault is the version assumed for clients whose version
+	// is unknown, as the specification requires for requests without an
+	// Mcp-Protocol-Version header.
+	// https://modelcontextprotocol.io/specification/2025-06-18/basic/transports#protocol-version-header
+	protocolVersionDefault = "2025-03-26"
+	// protocolVersionToolAnnotations added tool annotations, including the
+	// title of tools.
+	protocolVersionToolAnnotations = "2025-03-26"
+	// protocolVersionStructuredOutput added structured tool output, output
+	// schemas and titles.
+	protocolVersionStructuredOutput = "2025-06-18"
+	// protocolVersionTasks added tasks, icons, tool execution hints, URL
+	// mode elicitation and the description and website of implementations.
+	protocolVersionTasks = "2025-11-25"
+)
+
+// ProtocolVersionFromContext returns the protocol version negotiated with the
+// client of the current session. For sessions that were not initialized, such
+// as those of a stateless streamable HTTP server, it falls back to the
+// Mcp-Protocol-Version header of the request. When neither is known, it
+// returns 2025-03-26, the version the specification says to assume.
+func ProtocolVersionFromContext(ctx context.Context) string {
+	if version, ok := knownProtocolVersion(ctx); ok {
+		return version
+	}
+	return protocolVersionDefault
+}
+
+// knownProtocolVersion returns the protocol version negotiated with the
+// client of the current session or sent in the Mcp-Protocol-Version header of
+// the request. It reports false if there is neither.
+func knownProtocolVersion(ctx context.Context) (string, bool) {
+	if session, ok := ClientSessionFromContext(ctx).(SessionWithProtocolVersion); ok {
+		if version := session.GetProtocolVersion(); version != "" {
+			return version, true
+		}
+	}
+	if header, ok := ctx.Value(requestHeader).(http.Header); ok {
+		if version := header.Get(HeaderKeyProtocolVersion); slices.Contains(mcp.ValidProtocolVersions, version) {
+			return version, true
+		}
+	}
+	return "", false
+}
+
+// sessionProtocolVersion returns the protocol version negotiated with the
+// client of session, or protocolVersionDefault if it is unknown.
+func sessionProtocolVersion(session ClientSession) string {
+	if s, ok := session.(SessionWithProtocolVersion); ok {
+		if version := s.GetProtocolVersion(); version != "" {
+			return version
+		}
+	}
+	return protocolVersionDefault
+}
+
+// protocolVersionAtLeast reports whether version is minVersion or newer.
+// Versions are dates, so they compare as strings.
+func protocolVersionAtLeast(version, minVersion string) bool {
+	return version >= minVersion
+}
+
+// handleVersionedMessage handles message and downgrades the result for
+// clients that negotiated an older protocol version. Results for clients
+// whose version is unknown, such as in-process clients without a session,
+// are not changed.
+func (s *MCPServer) handleVersionedMessage(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage {
+	response := s.handleMessage(ctx, message)
+	result, ok := response.(mcp.JSONRPCResponse)
+	if !ok {
+		return response
+	}
+	version, known := knownProtocolVersion(ctx)
+	if initializeResult, ok := result.Result.(mcp.InitializeResult); ok && !known {
+		// The version negotiated by this request is known even without a
+		// session to store it in.
+		version, known = initializeResult.ProtocolVersion, true
+	}
+	if known {
+		result.Result = downgradeResult(version, result.Result)
+	}
+	return result
+}
+
+// downgradeResult returns result without the fields that were added after
+// version. Tools, prompts and resources are copied before they are changed,
+// since they are shared with the server.
+func downgradeResult(version string, result any) any {
+	if protocolVersionAtLeast(version, mcp.LATEST_PROTOCOL_VERSION) {
+		return result
+	}
+
+	switch r := result.(type) {
+	case mcp.InitializeResult:
+		// The result carries the version negotiated by this request, which
+		// the session may not know yet.
+		if !protocolVersionAtLeast(r.ProtocolVersion, protocolVersionStructuredOutput) {
+			r.ServerInfo.Title = ""
+		}
+		if !protocolVersionAtLeast(r.ProtocolVersion, protocolVersionTasks) {
+			r.Capabilities.Tasks = nil
+			r.ServerInfo.Description = ""
+			r.ServerInfo.WebsiteURL = ""
+			r.ServerInfo.Icons = nil
+		}
+		return r
+	case mcp.ListToolsResult:
+		tools := make([]mcp.Tool, len(r.Tools))
+		for i, tool := range r.Tools {
+			tools[i] = downgradeTool(version, tool)
+		}
+		r.Tools = tools
+		return r
+	case *mcp.CallToolResult:
+		return downgradeCallToolResult(version, r)
+	case mcp.ListPromptsResult:
+		r.Prompts = slices.Clone(r.Prompts)
+		for i := range r.Prompts {
+			r.Prompts[i].Icons = nil
+		}
+		return r
+	case mcp.ListResourcesResult:
+		r.Resources = slices.Clone(r.Resources)
+		for i := range r.Resources {
+			r.Resources[i].Icons = nil
+		}
+		return r
+	case mcp.ListResourceTemplatesResult:
+		r.ResourceTemplates = slices.Clone(r.ResourceTemplates)
+		for i := range r.ResourceTemplates {
+			r.ResourceTemplates[i].Icons = nil
+		}
+		return r
+	}
+	return result
+}
+
+func downgradeTool(version string, tool mcp.Tool) mcp.Tool {
+	if !protocolVersionAtLeast(version, protocolVersionToolAnnotations) {
+		tool.Annotations = mcp.ToolAnnotation{}
+	}
+	if !protocolVersionAtLeast(version, protocolVersionStructuredOutput) {
+		tool.OutputSchema = mcp.ToolOutputSchema{}
+		tool.RawOutputSchema = nil
+	}
+	if !protocolVersionAtLeast(version, protocolVersionTasks) {
+		tool.Icons = nil
+		tool.Execution = nil
+	}
+	return tool
+}
+
+// downgradeCallToolResult drops structured content for clients that predate
+// it. If the tool returned no unstructured content, the structured content is
+// sent as JSON text instead, as the specification recommends.
+func downgradeCallToolResult(version string, result *mcp.CallToolResult) *mcp.CallToolResult {
+	if protocolVersionAtLeast(version, protocolVersionStructuredOutput) || result.StructuredContent == nil {
+		return result
+	}
+	downgraded := *result
+	downgraded.StructuredContent = nil
+	if len(downgraded.Content) == 0 {
+		if text, err := json.Marshal(result.StructuredContent); err == nil {
+			downgraded.Content = []mcp.Content{mcp.NewTextContent(string(text))}
+		}
+	}
+	return &downgraded
+}
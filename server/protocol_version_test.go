@@ -0,0 +1,226 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestProtocolVersionFromContext(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	assert.Equal(t, "2025-03-26", ProtocolVersionFromContext(context.Background()))
+
+	session := NewInProcessSession("session", nil)
+	ctx := s.WithContext(context.Background(), session)
+	assert.Equal(t, "2025-03-26", ProtocolVersionFromContext(ctx))
+
+	header := http.Header{}
+	header.Set(HeaderKeyProtocolVersion, "2025-06-18")
+	headerCtx := context.WithValue(ctx, requestHeader, header)
+	assert.Equal(t, "2025-06-18", ProtocolVersionFromContext(headerCtx))
+
+	header.Set(HeaderKeyProtocolVersion, "1999-01-01")
+	assert.Equal(t, "2025-03-26", ProtocolVersionFromContext(headerCtx))
+
+	header.Set(HeaderKeyProtocolVersion, "2025-06-18")
+	session.SetProtocolVersion("2025-11-25")
+	assert.Equal(t, "2025-11-25", ProtocolVersionFromContext(headerCtx))
+}
+
+// handleWithVersion initializes a session with the given protocol version and
+// returns the result of request, handled in that session.
+func handleWithVersion(t *testing.T, s *MCPServer, version, request string) map[string]any {
+	t.Helper()
+	session := NewInProcessSession("session-"+version, nil)
+	ctx := s.WithContext(context.Background(), session)
+	initialize := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":%q}}`, version)
+	s.HandleMessage(ctx, json.RawMessage(initialize))
+	require.Equal(t, version, session.GetProtocolVersion())
+
+	data, err := json.Marshal(s.HandleMessage(ctx, json.RawMessage(request)))
+	require.NoError(t, err)
+	var response struct {
+		Result map[string]any `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(data, &response))
+	require.NotNil(t, response.Result, string(data))
+	return response.Result
+}
+
+func TestMCPServer_DowngradesResultsForOlderClients(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0",
+		WithToolCapabilities(false),
+		WithPromptCapabilities(false),
+		WithTaskCapabilities(true, true, true),
+	)
+	icon := mcp.Icon{Src: "https://example.com/icon.png"}
+	s.AddTool(mcp.NewTool("weather",
+		mcp.WithTitleAnnotation("Weather"),
+		mcp.WithToolIcons(icon),
+		mcp.WithTaskSupport(mcp.TaskSupportOptional),
+		mcp.WithOutputSchema[struct {
+			Temperature float64 `json:"temperature"`
+		}](),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{StructuredContent: map[string]any{"temperature": 21.5}}, nil
+	})
+	s.AddPrompt(mcp.NewPrompt("greeting", mcp.WithPromptIcons(icon)),
+		func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return mcp.NewGetPromptResult("greeting", nil), nil
+		})
+
+	const (
+		initialize = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"%s"}}`
+		listTools  = `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`
+		callTool   = `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"weather"}}`
+		listPrompt = `{"jsonrpc":"2.0","id":4,"method":"prompts/list"}`
+	)
+
+	t.Run("latest", func(t *testing.T) {
+		version := mcp.LATEST_PROTOCOL_VERSION
+		capabilities := handleWithVersion(t, s, version, fmt.Sprintf(initialize, version))["capabilities"].(map[string]any)
+		assert.Contains(t, capabilities, "tasks")
+
+		tool := handleWithVersion(t, s, version, listTools)["tools"].([]any)[0].(map[string]any)
+		assert.Contains(t, tool, "icons")
+		assert.Contains(t, tool, "execution")
+		assert.Contains(t, tool, "outputSchema")
+
+		result := handleWithVersion(t, s, version, callTool)
+		assert.Equal(t, map[string]any{"temperature": 21.5}, result["structuredContent"])
+
+		prompt := handleWithVersion(t, s, version, listPrompt)["prompts"].([]any)[0].(map[string]any)
+		assert.Contains(t, prompt, "icons")
+	})
+
+	t.Run("2025-06-18", func(t *testing.T) {
+		const version = "2025-06-18"
+		capabilities := handleWithVersion(t, s, version, fmt.Sprintf(initialize, version))["capabilities"].(map[string]any)
+		assert.NotContains(t, capabilities, "tasks")
+
+		tool := handleWithVersion(t, s, version, listTools)["tools"].([]any)[0].(map[string]any)
+		assert.NotContains(t, tool, "icons")
+		assert.NotContains(t, tool, "execution")
+		assert.Contains(t, tool, "outputSchema")
+
+		result := handleWithVersion(t, s, version, callTool)
+		assert.Contains(t, result, "structuredContent")
+
+		prompt := handleWithVersion(t, s, version, listPrompt)["prompts"].([]any)[0].(map[string]any)
+		assert.NotContains(t, prompt, "icons")
+	})
+
+	t.Run("2025-03-26", func(t *testing.T) {
+		const version = "2025-03-26"
+		tool := handleWithVersion(t, s, version, listTools)["tools"].([]any)[0].(map[string]any)
+		assert.NotContains(t, tool, "outputSchema")
+
+		result := handleWithVersion(t, s, version, callTool)
+		assert.NotContains(t, result, "structuredContent")
+		content := result["content"].([]any)
+		require.Len(t, content, 1)
+		assert.JSONEq(t, `{"temperature":21.5}`, content[0].(map[string]any)["text"].(string))
+	})
+
+	t.Run("2024-11-05", func(t *testing.T) {
+		const version = "2024-11-05"
+		tool := handleWithVersion(t, s, version, listTools)["tools"].([]any)[0].(map[string]any)
+		assert.NotContains(t, tool["annotations"], "title")
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		// Results for requests without a negotiated version or header are
+		// not downgraded.
+		data, err := json.Marshal(s.HandleMessage(context.Background(), json.RawMessage(listTools)))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"icons"`)
+		assert.Contains(t, string(data), `"outputSchema":{"properties":{"temperature":{"type":"number"}}`)
+		assert.Contains(t, string(data), `"title":"Weather"`)
+
+		data, err = json.Marshal(s.HandleMessage(context.Background(), json.RawMessage(callTool)))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"structuredContent":{"temperature":21.5}`)
+	})
+
+	t.Run("unknown version with header", func(t *testing.T) {
+		header := http.Header{}
+		header.Set(HeaderKeyProtocolVersion, "2025-03-26")
+		ctx := context.WithValue(context.Background(), requestHeader, header)
+		data, err := json.Marshal(s.HandleMessage(ctx, json.RawMessage(listTools)))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), `"outputSchema"`)
+	})
+
+	t.Run("server info", func(t *testing.T) {
+		info := mcp.Implementation{
+			Name:        "test",
+			Version:     "1.0.0",
+			Title:       "Test",
+			Description: "A test server",
+			Icons:       []mcp.Icon{icon},
+		}
+		result := downgradeResult("2025-06-18", mcp.InitializeResult{ProtocolVersion: "2025-06-18", ServerInfo: info}).(mcp.InitializeResult)
+		assert.Equal(t, mcp.Implementation{Name: "test", Version: "1.0.0", Title: "Test"}, result.ServerInfo)
+		result = downgradeResult("2025-03-26", mcp.InitializeResult{ProtocolVersion: "2025-03-26", ServerInfo: info}).(mcp.InitializeResult)
+		assert.Equal(t, mcp.Implementation{Name: "test", Version: "1.0.0"}, result.ServerInfo)
+	})
+
+	t.Run("server tools are not modified", func(t *testing.T) {
+		tool := s.GetTool("weather")
+		require.NotNil(t, tool)
+		assert.NotEmpty(t, tool.Tool.Icons)
+		assert.NotNil(t, tool.Tool.Execution)
+	})
+}
+
+func TestMCPServer_RequestURLElicitation_ProtocolVersion(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0", WithElicitation())
+	session := NewInProcessSession("session", nil)
+	session.SetProtocolVersion("2025-06-18")
+
+	_, err := s.RequestURLElicitation(context.Background(), session, "id", "https://example.com", "Sign in")
+	assert.ErrorIs(t, err, ErrURLElicitationNotSupported)
+}
+
+func TestStreamableHTTP_ProtocolVersionHeader(t *testing.T) {
+	s := NewMCPServer("test", "1.0.0")
+	server := NewTestStreamableHTTPServer(s)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(HeaderKeySessionID)
+	require.NotEmpty(t, sessionID)
+
+	for _, tc := range []struct {
+		header string
+		status int
+	}{
+		{"", http.StatusOK},
+		{"2025-06-18", http.StatusOK},
+		{"2025-03-26", http.StatusBadRequest},
+		{"not-a-version", http.StatusBadRequest},
+	} {
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"ping"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(HeaderKeySessionID, sessionID)
+		if tc.header != "" {
+			req.Header.Set(HeaderKeyProtocolVersion, tc.header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, tc.status, resp.StatusCode, "header %q", tc.header)
+	}
+}
@@ -11,13 +11,11 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
-// HandleMessage processes an incoming JSON-RPC message and returns an appropriate response
-func (s *MCPServer) HandleMessage(
+// handleMessage processes an incoming JSON-RPC message and returns an appropriate response
+func (s *MCPServer) handleMessage(
 	ctx context.Context,
 	message json.RawMessage,
 ) mcp.JSONRPCMessage {
-	// Add server to context
-	ctx = context.WithValue(ctx, serverKey{}, s)
 	var err *requestError
 
 	var baseMessage struct {
@@ -225,6 +223,58 @@ func (s *MCPServer) HandleMessage(
 		}
 		s.hooks.afterReadResource(ctx, baseMessage.ID, &request, result)
 		return createResponse(baseMessage.ID, *result)
+	case mcp.MethodResourcesSubscribe:
+		var request mcp.SubscribeRequest
+		var result *mcp.EmptyResult
+		if s.capabilities.resources == nil {
+			err = &requestError{
+				id:   baseMessage.ID,
+				code: mcp.METHOD_NOT_FOUND,
+				err:  fmt.Errorf("resources %w", ErrUnsupported),
+			}
+		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
+			err = &requestError{
+				id:   baseMessage.ID,
+				code: mcp.INVALID_REQUEST,
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+			}
+		} else {
+			request.Header = headers
+			s.hooks.beforeSubscribe(ctx, baseMessage.ID, &request)
+			result, err = s.handleSubscribe(ctx, baseMessage.ID, request)
+		}
+		if err != nil {
+			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			return err.ToJSONRPCError()
+		}
+		s.hooks.afterSubscribe(ctx, baseMessage.ID, &request, result)
+		return createResponse(baseMessage.ID, *result)
+	case mcp.MethodResourcesUnsubscribe:
+		var request mcp.UnsubscribeRequest
+		var result *mcp.EmptyResult
+		if s.capabilities.resources == nil {
+			err = &requestError{
+				id:   baseMessage.ID,
+				code: mcp.METHOD_NOT_FOUND,
+				err:  fmt.Errorf("resources %w", ErrUnsupported),
+			}
+		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
+			err = &requestError{
+				id:   baseMessage.ID,
+				code: mcp.INVALID_REQUEST,
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+			}
+		} else {
+			request.Header = headers
+			s.hooks.beforeUnsubscribe(ctx, baseMessage.ID, &request)
+			result, err = s.handleUnsubscribe(ctx, baseMessage.ID, request)
+		}
+		if err != nil {
+			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			return err.ToJSONRPCError()
+		}
+		s.hooks.afterUnsubscribe(ctx, baseMessage.ID, &request, result)
+		return createResponse(baseMessage.ID, *result)
 	case mcp.MethodPromptsList:
 		var request mcp.ListPromptsRequest
 		var result *mcp.ListPromptsResult
@@ -460,6 +510,9 @@ func (s *MCPServer) HandleMessage(
 		s.hooks.afterComplete(ctx, baseMessage.ID, &request, result)
 		return createResponse(baseMessage.ID, *result)
 	default:
+		if handler, ok := s.requestHandler(baseMessage.Method); ok {
+			return s.handleCustomRequest(ctx, baseMessage.ID, baseMessage.Method, message, headers, handler)
+		}
 		return createErrorResponse(
 			baseMessage.ID,
 			mcp.METHOD_NOT_FOUND,
@@ -467,3 +520,25 @@ func (s *MCPServer) HandleMessage(
 		)
 	}
 }
+
+// requestMethods are the request methods of the protocol handled by
+// handleMessage.
+var requestMethods = map[mcp.MCPMethod]struct{}{
+	mcp.MethodInitialize:             {},
+	mcp.MethodPing:                   {},
+	mcp.MethodSetLogLevel:            {},
+	mcp.MethodResourcesList:          {},
+	mcp.MethodResourcesTemplatesList: {},
+	mcp.MethodResourcesRead:          {},
+	mcp.MethodResourcesSubscribe:     {},
+	mcp.MethodResourcesUnsubscribe:   {},
+	mcp.MethodPromptsList:            {},
+	mcp.MethodPromptsGet:             {},
+	mcp.MethodToolsList:              {},
+	mcp.MethodToolsCall:              {},
+	mcp.MethodTasksGet:               {},
+	mcp.MethodTasksList:              {},
+	mcp.MethodTasksResult:            {},
+	mcp.MethodTasksCancel:            {},
+	mcp.MethodCompletionComplete:     {},
+}
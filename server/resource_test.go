@@ -251,3 +251,42 @@ func TestMCPServer_RemoveResource(t *testing.T) {
 		})
 	}
 }
+
+func TestMCPServer_DeleteResourceTemplates(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(false, true))
+	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, nil
+	}
+	server.AddResourceTemplate(mcp.NewResourceTemplate("test://a/{id}", "A"), handler)
+	server.AddResourceTemplate(mcp.NewResourceTemplate("test://b/{id}", "B"), handler)
+
+	notificationChannel := make(chan mcp.JSONRPCNotification, 10)
+	require.NoError(t, server.RegisterSession(context.Background(), &fakeSession{
+		sessionID:           "test",
+		notificationChannel: notificationChannel,
+		initialized:         true,
+	}))
+
+	// Unknown templates are ignored without a notification.
+	server.DeleteResourceTemplates("test://unknown/{id}")
+	server.DeleteResourceTemplates("test://a/{id}")
+	select {
+	case notification := <-notificationChannel:
+		assert.Equal(t, mcp.MethodNotificationResourcesListChanged, notification.Method)
+	case <-time.After(time.Second):
+		t.Fatal("no list_changed notification")
+	}
+	assert.Empty(t, notificationChannel)
+
+	response := server.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "resources/templates/list"
+	}`))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "Expected JSONRPCResponse, got %T", response)
+	result, ok := resp.Result.(mcp.ListResourceTemplatesResult)
+	require.True(t, ok)
+	require.Len(t, result.ResourceTemplates, 1)
+	assert.Equal(t, "B", result.ResourceTemplates[0].Name)
+}
@@ -12,6 +12,8 @@ var (
 	ErrNoClientSession = errors.New("no active client session")
 	// ErrRootsNotSupported is returned when the session does not support roots
 	ErrRootsNotSupported = errors.New("session does not support roots")
+	// ErrPathOutsideRoots is returned when a path does not lie inside any of the client's roots
+	ErrPathOutsideRoots = errors.New("path is outside the client's roots")
 )
 
 // RequestRoots sends an list roots request to the client.
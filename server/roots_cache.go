@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RootsFromContext returns the roots of the client of the session in ctx.
+//
+// The roots are requested from the client the first time and cached for the
+// session when the client declared the roots listChanged capability and the
+// session is registered with the server. The cache is invalidated when the
+// client sends notifications/roots/list_changed, and the roots are requested
+// again on the next call. Roots of clients that cannot notify changes, and
+// of unregistered sessions such as the ones of stateless transports, are
+// requested on every call.
+//
+// It returns ErrNoClientSession if ctx has no session, and
+// ErrRootsNotSupported if the session cannot list roots or the client did
+// not declare the roots capability.
+func RootsFromContext(ctx context.Context) ([]mcp.Root, error) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, ErrNoClientSession
+	}
+	rootsSession, ok := session.(SessionWithRoots)
+	if !ok {
+		return nil, ErrRootsNotSupported
+	}
+
+	cacheable := false
+	if clientInfo, ok := session.(SessionWithClientInfo); ok {
+		roots := clientInfo.GetClientCapabilities().Roots
+		if roots == nil {
+			return nil, ErrRootsNotSupported
+		}
+		cacheable = roots.ListChanged
+	}
+
+	s := ServerFromContext(ctx)
+	if s == nil || !cacheable {
+		return listRoots(ctx, rootsSession)
+	}
+	return s.roots.get(ctx, rootsSession, func(sessionID string) bool {
+		_, ok := s.sessions.Load(sessionID)
+		return ok
+	})
+}
+
+// listRoots requests the roots from the client of session.
+func listRoots(ctx context.Context, session SessionWithRoots) ([]mcp.Root, error) {
+	result, err := session.ListRoots(ctx, mcp.ListRootsRequest{
+		Request: mcp.Request{
+			Method: string(mcp.MethodListRoots),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Roots, nil
+}
+
+// rootsCache caches the roots of the clients of the sessions.
+type rootsCache struct {
+	mu      sync.Mutex
+	entries map[string]*rootsEntry
+}
+
+// rootsEntry holds the cached roots of a session.
+type rootsEntry struct {
+	// version is incremented when the roots of the client change.
+	version atomic.Uint64
+
+	// mu serializes the requests to the client so that concurrent callers
+	// share one request.
+	mu            sync.Mutex
+	roots         []mcp.Root
+	cached        bool
+	cachedVersion uint64
+}
+
+// entry returns the entry of the session with the given ID, creating it if
+// the session is registered, or nil. The check is made under the lock that
+// remove takes, so no entry is created after the session was unregistered.
+func (c *rootsCache) entry(sessionID string, registered func(string) bool) *rootsEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[sessionID]; ok {
+		return entry
+	}
+	if !registered(sessionID) {
+		return nil
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]*rootsEntry)
+	}
+	entry := &rootsEntry{}
+	c.entries[sessionID] = entry
+	return entry
+}
+
+// get returns the cached roots of session, requesting them from the client
+// if they are not cached or changed since. Roots of sessions that are not
+// registered are not cached.
+func (c *rootsCache) get(ctx context.Context, session SessionWithRoots, registered func(string) bool) ([]mcp.Root, error) {
+	entry := c.entry(session.SessionID(), registered)
+	if entry == nil {
+		return listRoots(ctx, session)
+	}
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	version := entry.version.Load()
+	if entry.cached && entry.cachedVersion == version {
+		return slices.Clone(entry.roots), nil
+	}
+	roots, err := listRoots(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	// If the roots changed during the request, the next call requests them
+	// again.
+	entry.roots = roots
+	entry.cached = true
+	entry.cachedVersion = version
+	return slices.Clone(roots), nil
+}
+
+// invalidate marks the cached roots of the session with the given ID as
+// changed.
+func (c *rootsCache) invalidate(sessionID string) {
+	c.mu.Lock()
+	entry, ok := c.entries[sessionID]
+	c.mu.Unlock()
+	if ok {
+		entry.version.Add(1)
+	}
+}
+
+// remove drops the cached roots of the session with the given ID.
+func (c *rootsCache) remove(sessionID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, sessionID)
+}
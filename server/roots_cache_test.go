@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// countingRootsHandler answers list roots requests with roots and counts
+// them.
+type countingRootsHandler struct {
+	roots atomic.Value
+	calls atomic.Int32
+}
+
+func (h *countingRootsHandler) ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
+	h.calls.Add(1)
+	return &mcp.ListRootsResult{Roots: h.roots.Load().([]mcp.Root)}, nil
+}
+
+func rootsCapabilities(listChanged bool) mcp.ClientCapabilities {
+	return mcp.ClientCapabilities{
+		Roots: &struct {
+			ListChanged bool `json:"listChanged,omitempty"`
+		}{ListChanged: listChanged},
+	}
+}
+
+func TestRootsFromContext(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", WithRoots())
+	handler := &countingRootsHandler{}
+	handler.roots.Store([]mcp.Root{{URI: "file:///project", Name: "project"}})
+	session := NewInProcessSessionWithHandlers("roots", nil, nil, handler)
+	session.SetClientCapabilities(rootsCapabilities(true))
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+	ctx := context.WithValue(server.WithContext(context.Background(), session), serverKey{}, server)
+
+	roots, err := RootsFromContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []mcp.Root{{URI: "file:///project", Name: "project"}}, roots)
+
+	// The roots are cached.
+	_, err = RootsFromContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), handler.calls.Load())
+
+	// A list changed notification refreshes them.
+	handler.roots.Store([]mcp.Root{{URI: "file:///other"}})
+	server.HandleMessage(server.WithContext(context.Background(), session),
+		[]byte(`{"jsonrpc":"2.0","method":"notifications/roots/list_changed"}`))
+	roots, err = RootsFromContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []mcp.Root{{URI: "file:///other"}}, roots)
+	assert.Equal(t, int32(2), handler.calls.Load())
+
+	// The cache is dropped with the session, and not created again by later
+	// calls.
+	server.UnregisterSession(context.Background(), session.SessionID())
+	_, err = RootsFromContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), handler.calls.Load())
+	server.roots.mu.Lock()
+	assert.NotContains(t, server.roots.entries, session.SessionID())
+	server.roots.mu.Unlock()
+}
+
+func TestRootsFromContext_UnregisteredSession(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", WithRoots())
+	handler := &countingRootsHandler{}
+	handler.roots.Store([]mcp.Root{})
+	session := NewInProcessSessionWithHandlers("stateless", nil, nil, handler)
+	session.SetClientCapabilities(rootsCapabilities(true))
+	ctx := context.WithValue(server.WithContext(context.Background(), session), serverKey{}, server)
+
+	// Sessions the server does not know of, such as stateless ones, are not
+	// cached, so nothing is left behind when they go away.
+	for range 2 {
+		_, err := RootsFromContext(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), handler.calls.Load())
+	server.roots.mu.Lock()
+	assert.Empty(t, server.roots.entries)
+	server.roots.mu.Unlock()
+}
+
+func TestRootsFromContext_WithoutListChanged(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", WithRoots())
+	handler := &countingRootsHandler{}
+	handler.roots.Store([]mcp.Root{})
+	session := NewInProcessSessionWithHandlers("roots", nil, nil, handler)
+	session.SetClientCapabilities(rootsCapabilities(false))
+	ctx := context.WithValue(server.WithContext(context.Background(), session), serverKey{}, server)
+
+	// Clients that cannot notify changes are asked every time.
+	for range 2 {
+		_, err := RootsFromContext(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), handler.calls.Load())
+}
+
+func TestRootsFromContext_Unsupported(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0")
+
+	_, err := RootsFromContext(context.Background())
+	assert.ErrorIs(t, err, ErrNoClientSession)
+
+	basic := &mockBasicRootsSession{sessionID: "basic"}
+	_, err = RootsFromContext(server.WithContext(context.Background(), basic))
+	assert.ErrorIs(t, err, ErrRootsNotSupported)
+
+	// The client did not declare the roots capability.
+	session := NewInProcessSessionWithHandlers("roots", nil, nil, &countingRootsHandler{})
+	_, err = RootsFromContext(server.WithContext(context.Background(), session))
+	assert.ErrorIs(t, err, ErrRootsNotSupported)
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// PathInContextRoots is PathInRoots with the roots of the client of the
+// session in ctx, see RootsFromContext.
+func PathInContextRoots(ctx context.Context, pathOrURI string) (string, error) {
+	roots, err := RootsFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return PathInRoots(pathOrURI, roots)
+}
+
+// PathInRoots checks that pathOrURI, an absolute local path or a file:// URI,
+// lies inside one of roots, and returns its absolute local path with symlinks
+// resolved. Tools should use the returned path to access the file rather
+// than the one they were given.
+//
+// The check only holds at the time it is made: a component of the path that
+// is replaced by a symlink between the check and the access can still lead
+// outside the roots. Callers that share the tree with untrusted writers need
+// additional protection, such as opening files relative to a directory
+// handle that cannot be escaped.
+//
+// Symlinks are resolved in the path and in the roots, so a symlink inside a
+// root that points outside of it is rejected. The path does not have to
+// exist, in which case its nearest existing parent is resolved. Roots that
+// are not file:// URIs are ignored.
+//
+// It returns an error wrapping ErrPathOutsideRoots if the path is relative or
+// not inside any of the roots.
+func PathInRoots(pathOrURI string, roots []mcp.Root) (string, error) {
+	path, err := localPath(pathOrURI)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("%w: %s is not an absolute path", ErrPathOutsideRoots, pathOrURI)
+	}
+	resolved, err := resolveSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+
+	for _, root := range roots {
+		if !strings.HasPrefix(root.URI, "file:") {
+			continue
+		}
+		rootPath, err := localPath(root.URI)
+		if err != nil || !filepath.IsAbs(rootPath) {
+			continue
+		}
+		resolvedRoot, err := resolveSymlinks(rootPath)
+		if err != nil {
+			continue
+		}
+		if pathWithin(resolvedRoot, resolved) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrPathOutsideRoots, pathOrURI)
+}
+
+// localPath returns the cleaned local path of a path or file:// URI.
+func localPath(pathOrURI string) (string, error) {
+	if !strings.HasPrefix(pathOrURI, "file:") {
+		return filepath.Clean(pathOrURI), nil
+	}
+	u, err := url.Parse(pathOrURI)
+	if err != nil {
+		return "", fmt.Errorf("invalid file URI %q: %w", pathOrURI, err)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("file URI %q does not refer to a local file", pathOrURI)
+	}
+	path := u.Path
+	// file:///C:/dir is the URI of C:\dir
+	if runtime.GOOS == "windows" && len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.Clean(filepath.FromSlash(path)), nil
+}
+
+// resolveSymlinks resolves the symlinks in path. If path does not exist, the
+// symlinks of its nearest existing parent are resolved.
+func resolveSymlinks(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return resolved, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	// A dangling symlink could be followed when the file is created.
+	if _, lstatErr := os.Lstat(path); lstatErr == nil {
+		return "", fmt.Errorf("failed to resolve symlink %s: %w", path, err)
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path, nil
+	}
+	resolvedParent, err := resolveSymlinks(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolvedParent, filepath.Base(path)), nil
+}
+
+// pathWithin reports whether path is root or lies inside it.
+func pathWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPathInRoots(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require privileges on windows")
+	}
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	root := filepath.Join(base, "root")
+	outside := filepath.Join(base, "outside")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "dir"), 0o755))
+	require.NoError(t, os.MkdirAll(outside, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "dir", "file.txt"), nil, 0o644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+	require.NoError(t, os.Symlink(filepath.Join(root, "dir"), filepath.Join(base, "link")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "missing"), filepath.Join(root, "dangling")))
+
+	roots := []mcp.Root{
+		{URI: "https://example.com/repo"},
+		{URI: fileURI(root)},
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		outside bool
+	}{
+		{name: "path", path: filepath.Join(root, "dir", "file.txt"), want: filepath.Join(root, "dir", "file.txt")},
+		{name: "uri", path: fileURI(filepath.Join(root, "dir")), want: filepath.Join(root, "dir")},
+		{name: "root", path: root, want: root},
+		{name: "new file", path: filepath.Join(root, "dir", "new", "file.txt"), want: filepath.Join(root, "dir", "new", "file.txt")},
+		{name: "symlink into root", path: filepath.Join(base, "link", "file.txt"), want: filepath.Join(root, "dir", "file.txt")},
+		{name: "dot dot", path: filepath.Join(root, "dir", "..", "..", "outside"), outside: true},
+		{name: "symlink out of root", path: filepath.Join(root, "escape", "file.txt"), outside: true},
+		{name: "sibling with root prefix", path: root + "-other", outside: true},
+		{name: "relative", path: filepath.Join("dir", "file.txt"), outside: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PathInRoots(tt.path, roots)
+			if tt.outside {
+				assert.ErrorIs(t, err, ErrPathOutsideRoots)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err = PathInRoots(filepath.Join(root, "dangling"), roots)
+	assert.Error(t, err)
+	_, err = PathInRoots("file://remote/share/file.txt", roots)
+	assert.Error(t, err)
+}
+
+func TestPathInContextRoots(t *testing.T) {
+	root := t.TempDir()
+	server := NewMCPServer("test", "1.0.0", WithRoots())
+	handler := &countingRootsHandler{}
+	handler.roots.Store([]mcp.Root{{URI: fileURI(root)}})
+	session := NewInProcessSessionWithHandlers("roots", nil, nil, handler)
+	session.SetClientCapabilities(rootsCapabilities(true))
+	ctx := context.WithValue(server.WithContext(context.Background(), session), serverKey{}, server)
+
+	_, err := PathInContextRoots(ctx, filepath.Join(root, "file.txt"))
+	require.NoError(t, err)
+	_, err = PathInContextRoots(ctx, filepath.Dir(root))
+	assert.ErrorIs(t, err, ErrPathOutsideRoots)
+
+	_, err = PathInContextRoots(context.Background(), root)
+	assert.ErrorIs(t, err, ErrNoClientSession)
+}
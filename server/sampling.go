@@ -2,11 +2,27 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
+var (
+	// ErrSamplingToolsNotSupported is returned when a sampling request offers
+	// tools to a client that did not declare the sampling.tools capability.
+	ErrSamplingToolsNotSupported = errors.New("client does not support tools in sampling requests")
+	// ErrSamplingMaxSteps is returned by SampleWithTools when the model still
+	// calls tools after the maximum number of sampling requests.
+	ErrSamplingMaxSteps = errors.New("sampling loop reached the maximum number of steps")
+	// ErrSamplingNoTools is returned by SampleWithTools when neither the
+	// request nor WithSamplingToolNames names the tools to offer.
+	ErrSamplingNoTools = errors.New("no tools to offer in the sampling loop")
+)
+
 // EnableSampling enables sampling capabilities for the server.
 // This allows the server to send sampling requests to clients that support it.
 func (s *MCPServer) EnableSampling() {
@@ -25,6 +41,10 @@ func (s *MCPServer) RequestSampling(ctx context.Context, request mcp.CreateMessa
 		return nil, fmt.Errorf("no active session")
 	}
 
+	if err := checkSamplingTools(session, request); err != nil {
+		return nil, err
+	}
+
 	// Check if the session supports sampling requests
 	if samplingSession, ok := session.(SessionWithSampling); ok {
 		return samplingSession.RequestSampling(ctx, request)
@@ -59,3 +79,213 @@ func InProcessSamplingHandlerFromContext(ctx context.Context) SamplingHandler {
 	}
 	return nil
 }
+
+// checkSamplingTools rejects sampling requests with tools for initialized
+// clients that did not declare support for them.
+func checkSamplingTools(session ClientSession, request mcp.CreateMessageRequest) error {
+	if len(request.Tools) == 0 && request.ToolChoice == nil {
+		return nil
+	}
+	s, ok := session.(SessionWithClientInfo)
+	if !ok || !session.Initialized() {
+		return nil
+	}
+	if s.GetClientCapabilities().SamplingTools == nil {
+		return ErrSamplingToolsNotSupported
+	}
+	return nil
+}
+
+// defaultSamplingMaxSteps is the default maximum number of sampling requests
+// sent by SampleWithTools.
+const defaultSamplingMaxSteps = 10
+
+// samplingLoopOptions holds the options of SampleWithTools.
+type samplingLoopOptions struct {
+	maxSteps  int
+	toolNames []string
+}
+
+// SamplingLoopOption configures SampleWithTools.
+type SamplingLoopOption func(*samplingLoopOptions)
+
+// WithSamplingMaxSteps sets the maximum number of sampling requests sent by
+// SampleWithTools. The default is 10.
+func WithSamplingMaxSteps(steps int) SamplingLoopOption {
+	return func(o *samplingLoopOptions) {
+		if steps > 0 {
+			o.maxSteps = steps
+		}
+	}
+}
+
+// WithSamplingToolNames offers the model the tools with the given names that
+// are visible to the session. It is ignored if the request lists its tools.
+func WithSamplingToolNames(names ...string) SamplingLoopOption {
+	return func(o *samplingLoopOptions) {
+		o.toolNames = names
+	}
+}
+
+// SamplingLoopResult is the outcome of SampleWithTools.
+type SamplingLoopResult struct {
+	// Result is the last result returned by the client.
+	Result *mcp.CreateMessageResult
+	// Messages is the whole conversation: the request messages followed by
+	// the model's replies and the tool results sent back to it.
+	Messages []mcp.SamplingMessage
+	// Steps is the number of sampling requests sent.
+	Steps int
+}
+
+// SampleWithTools runs an agentic loop on top of RequestSampling. It offers
+// the server's tools to the model, calls the tools the model asks for and
+// sends their results back, until the model stops for another reason than
+// tool use.
+//
+// The tools to offer are request.Tools or, if it is empty, the ones named
+// with WithSamplingToolNames; without either ErrSamplingNoTools is returned.
+// Tools are never offered implicitly, so a tool calling SampleWithTools does
+// not offer itself unless asked to. The model can only call the offered
+// tools. They are called like tools/call requests of the client with the
+// same context, so session-specific tools, tool filters, hooks and
+// middlewares apply. Errors of tool calls are reported to the model rather
+// than ending the loop.
+func (s *MCPServer) SampleWithTools(
+	ctx context.Context,
+	request mcp.CreateMessageRequest,
+	opts ...SamplingLoopOption,
+) (*SamplingLoopResult, error) {
+	options := samplingLoopOptions{maxSteps: defaultSamplingMaxSteps}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if len(request.Tools) == 0 {
+		if len(options.toolNames) == 0 {
+			return nil, ErrSamplingNoTools
+		}
+		tools := slices.DeleteFunc(s.toolsForContext(ctx), func(tool mcp.Tool) bool {
+			return !slices.Contains(options.toolNames, tool.Name)
+		})
+		slices.SortFunc(tools, func(a, b mcp.Tool) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		request.Tools = tools
+	}
+
+	loop := &SamplingLoopResult{
+		Messages: slices.Clone(request.Messages),
+	}
+	for loop.Steps < options.maxSteps {
+		request.Messages = loop.Messages
+		result, err := s.RequestSampling(ctx, request)
+		if err != nil {
+			return loop, err
+		}
+		loop.Steps++
+		loop.Result = result
+		loop.Messages = append(loop.Messages, result.SamplingMessage)
+
+		toolUses := samplingToolUses(result.Content)
+		if len(toolUses) == 0 || result.StopReason != mcp.StopReasonToolUse {
+			return loop, nil
+		}
+
+		toolResults := make([]mcp.Content, 0, len(toolUses))
+		for _, toolUse := range toolUses {
+			toolResults = append(toolResults, s.callSamplingTool(ctx, request.Tools, toolUse))
+		}
+		loop.Messages = append(loop.Messages, mcp.SamplingMessage{
+			Role:    mcp.RoleUser,
+			Content: toolResults,
+		})
+	}
+	return loop, ErrSamplingMaxSteps
+}
+
+// samplingToolUses returns the tool uses in the content of a sampling result.
+func samplingToolUses(content any) []mcp.ToolUseContent {
+	var toolUses []mcp.ToolUseContent
+	collect := func(c any) {
+		switch c := c.(type) {
+		case mcp.ToolUseContent:
+			toolUses = append(toolUses, c)
+		case *mcp.ToolUseContent:
+			toolUses = append(toolUses, *c)
+		}
+	}
+	switch content := content.(type) {
+	case []mcp.Content:
+		for _, c := range content {
+			collect(c)
+		}
+	case []any:
+		for _, c := range content {
+			collect(c)
+		}
+	default:
+		collect(content)
+	}
+	return toolUses
+}
+
+// callSamplingTool calls the tool requested by the model and converts the
+// outcome to a tool result. The call goes through HandleMessage like a
+// tools/call request of the client, so message middlewares, hooks and tool
+// middlewares apply. Tools that were not offered to the model are not called.
+func (s *MCPServer) callSamplingTool(ctx context.Context, offered []mcp.Tool, toolUse mcp.ToolUseContent) mcp.ToolResultContent {
+	if !slices.ContainsFunc(offered, func(tool mcp.Tool) bool { return tool.Name == toolUse.Name }) {
+		return samplingToolError(toolUse.ID, fmt.Sprintf("tool %q was not offered", toolUse.Name))
+	}
+
+	message, err := json.Marshal(mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(toolUse.ID),
+		Params:  mcp.CallToolParams{Name: toolUse.Name, Arguments: toolUse.Input},
+		Request: mcp.Request{
+			Method: string(mcp.MethodToolsCall),
+		},
+	})
+	if err != nil {
+		return samplingToolError(toolUse.ID, err.Error())
+	}
+
+	var callResult *mcp.CallToolResult
+	switch response := s.HandleMessage(ctx, message).(type) {
+	case mcp.JSONRPCResponse:
+		callResult, err = samplingCallResult(response.Result)
+	case mcp.JSONRPCError:
+		err = errors.New(response.Error.Message)
+	default:
+		err = fmt.Errorf("tool %q did not return a result", toolUse.Name)
+	}
+	if err != nil {
+		return samplingToolError(toolUse.ID, err.Error())
+	}
+	result := mcp.NewToolResultContent(toolUse.ID, callResult.Content...)
+	result.StructuredContent = callResult.StructuredContent
+	result.IsError = callResult.IsError
+	return result
+}
+
+// samplingCallResult returns the result of a tools/call response as a
+// CallToolResult.
+func samplingCallResult(result any) (*mcp.CallToolResult, error) {
+	if callResult, ok := result.(*mcp.CallToolResult); ok {
+		return callResult, nil
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	message := json.RawMessage(raw)
+	return mcp.ParseCallToolResult(&message)
+}
+
+// samplingToolError returns a tool result reporting message as an error.
+func samplingToolError(toolUseID, message string) mcp.ToolResultContent {
+	result := mcp.NewToolResultContent(toolUseID, mcp.NewTextContent(message))
+	result.IsError = true
+	return result
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -152,3 +155,141 @@ func TestMCPServer_EnableSampling_SetsCapability(t *testing.T) {
 		t.Error("sampling capability should be set after EnableSampling() is called")
 	}
 }
+
+// samplingHandlerFunc adapts a function to the SamplingHandler interface.
+type samplingHandlerFunc func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+
+func (f samplingHandlerFunc) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	return f(ctx, request)
+}
+
+func samplingToolsSession(handler SamplingHandler, capabilities mcp.ClientCapabilities) *InProcessSession {
+	session := NewInProcessSession("sampling-tools", handler)
+	session.SetClientCapabilities(capabilities)
+	session.Initialize()
+	return session
+}
+
+func TestMCPServer_RequestSampling_ToolsCapability(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0")
+	handler := samplingHandlerFunc(func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		return &mcp.CreateMessageResult{Model: "test-model"}, nil
+	})
+	request := mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{
+			Tools:      []mcp.Tool{mcp.NewTool("weather")},
+			ToolChoice: &mcp.ToolChoice{Mode: mcp.ToolChoiceAuto},
+		},
+	}
+
+	session := samplingToolsSession(handler, mcp.ClientCapabilities{Sampling: &struct{}{}})
+	_, err := server.RequestSampling(server.WithContext(context.Background(), session), request)
+	assert.ErrorIs(t, err, ErrSamplingToolsNotSupported)
+
+	session = samplingToolsSession(handler, mcp.ClientCapabilities{
+		Sampling: &struct{}{}, SamplingTools: &struct{}{},
+	})
+	_, err = server.RequestSampling(server.WithContext(context.Background(), session), request)
+	assert.NoError(t, err)
+}
+
+func TestMCPServer_SampleWithTools(t *testing.T) {
+	var calledTools []string
+	hooks := &Hooks{}
+	hooks.AddAfterCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest, result any) {
+		calledTools = append(calledTools, message.Params.Name)
+	})
+	server := NewMCPServer("test", "1.0.0", WithToolCapabilities(false), WithHooks(hooks))
+	server.AddTool(mcp.NewTool("weather", mcp.WithString("city")),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("sunny in " + request.GetString("city", "")), nil
+		})
+	server.AddTool(mcp.NewTool("other"),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("other"), nil
+		})
+
+	var requests []mcp.CreateMessageRequest
+	handler := samplingHandlerFunc(func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		requests = append(requests, request)
+		if len(requests) == 1 {
+			return &mcp.CreateMessageResult{
+				SamplingMessage: mcp.SamplingMessage{
+					Role: mcp.RoleAssistant,
+					Content: []mcp.Content{
+						mcp.NewToolUseContent("call-1", "weather", map[string]any{"city": "Paris"}),
+						mcp.NewToolUseContent("call-2", "other", nil),
+					},
+				},
+				Model:      "test-model",
+				StopReason: mcp.StopReasonToolUse,
+			}, nil
+		}
+		return &mcp.CreateMessageResult{
+			SamplingMessage: mcp.SamplingMessage{
+				Role:    mcp.RoleAssistant,
+				Content: mcp.NewTextContent("It is sunny in Paris."),
+			},
+			Model:      "test-model",
+			StopReason: mcp.StopReasonEndTurn,
+		}, nil
+	})
+	session := samplingToolsSession(handler, mcp.ClientCapabilities{
+		Sampling: &struct{}{}, SamplingTools: &struct{}{},
+	})
+	ctx := server.WithContext(context.Background(), session)
+
+	request := mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{
+			Messages: []mcp.SamplingMessage{
+				{Role: mcp.RoleUser, Content: mcp.NewTextContent("What is the weather in Paris?")},
+			},
+			MaxTokens: 100,
+		},
+	}
+
+	_, err := server.SampleWithTools(ctx, request)
+	require.ErrorIs(t, err, ErrSamplingNoTools)
+
+	result, err := server.SampleWithTools(ctx, request, WithSamplingToolNames("weather"))
+	require.NoError(t, err)
+	// Tools are called like tools/call requests, and only if offered.
+	assert.Equal(t, []string{"weather"}, calledTools)
+	assert.Equal(t, 2, result.Steps)
+	assert.Equal(t, mcp.NewTextContent("It is sunny in Paris."), result.Result.Content)
+	require.Len(t, result.Messages, 4)
+
+	require.Len(t, requests, 2)
+	require.Len(t, requests[0].Tools, 1)
+	assert.Equal(t, "weather", requests[0].Tools[0].Name)
+	require.Len(t, requests[1].Messages, 3)
+
+	toolResults := requests[1].Messages[2]
+	assert.Equal(t, mcp.RoleUser, toolResults.Role)
+	content, ok := toolResults.Content.([]mcp.Content)
+	require.True(t, ok)
+	require.Len(t, content, 2)
+	assert.Equal(t, mcp.NewToolResultContent("call-1", mcp.NewTextContent("sunny in Paris")), content[0])
+	notOffered := content[1].(mcp.ToolResultContent)
+	assert.Equal(t, "call-2", notOffered.ToolUseID)
+	assert.True(t, notOffered.IsError)
+
+	t.Run("max steps", func(t *testing.T) {
+		requests = nil
+		handler := samplingHandlerFunc(func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			return &mcp.CreateMessageResult{
+				SamplingMessage: mcp.SamplingMessage{
+					Role:    mcp.RoleAssistant,
+					Content: mcp.NewToolUseContent("call", "other", nil),
+				},
+				StopReason: mcp.StopReasonToolUse,
+			}, nil
+		})
+		session := samplingToolsSession(handler, mcp.ClientCapabilities{
+			Sampling: &struct{}{}, SamplingTools: &struct{}{},
+		})
+		result, err := server.SampleWithTools(server.WithContext(context.Background(), session), request, WithSamplingToolNames("other"), WithSamplingMaxSteps(3))
+		assert.ErrorIs(t, err, ErrSamplingMaxSteps)
+		assert.Equal(t, 3, result.Steps)
+	})
+}
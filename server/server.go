@@ -2,15 +2,12 @@
 package server
 
 import (
-	"cmp"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"maps"
 	"slices"
-	"sort"
 	"sync"
 	"time"
 
@@ -66,6 +63,14 @@ type TaskToolHandlerFunc func(ctx context.Context, request mcp.CallToolRequest)
 // ToolHandlerMiddleware is a middleware function that wraps a ToolHandlerFunc.
 type ToolHandlerMiddleware func(ToolHandlerFunc) ToolHandlerFunc
 
+// MessageHandlerFunc handles a raw JSON-RPC message and returns the response,
+// or nil if the message does not call for one.
+type MessageHandlerFunc func(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage
+
+// MessageHandlerMiddleware is a middleware function that wraps the handling of
+// every JSON-RPC message received by the server.
+type MessageHandlerMiddleware func(MessageHandlerFunc) MessageHandlerFunc
+
 // ResourceHandlerMiddleware is a middleware function that wraps a ResourceHandlerFunc.
 type ResourceHandlerMiddleware func(ResourceHandlerFunc) ResourceHandlerFunc
 
@@ -113,6 +118,30 @@ func ServerFromContext(ctx context.Context) *MCPServer {
 	return nil
 }
 
+// HandleMessage processes an incoming JSON-RPC message and returns an appropriate response
+func (s *MCPServer) HandleMessage(
+	ctx context.Context,
+	message json.RawMessage,
+) mcp.JSONRPCMessage {
+	// Add server to context
+	ctx = context.WithValue(ctx, serverKey{}, s)
+
+	ctx, done, ok := s.requests.begin(ctx)
+	defer done()
+	if !ok {
+		if response := rejectWhileDraining(message); response != nil {
+			return response
+		}
+	}
+
+	var handler MessageHandlerFunc = s.handleVersionedMessage
+	// Apply middlewares in reverse order
+	for i := len(s.messageHandlerMiddlewares) - 1; i >= 0; i-- {
+		handler = s.messageHandlerMiddlewares[i](handler)
+	}
+	return handler(ctx, message)
+}
+
 // UnparsableMessageError is attached to the RequestError when json.Unmarshal
 // fails on the request.
 type UnparsableMessageError struct {
@@ -174,9 +203,11 @@ type MCPServer struct {
 	toolsMu                sync.RWMutex
 	toolMiddlewareMu       sync.RWMutex
 	notificationHandlersMu sync.RWMutex
+	requestHandlersMu      sync.RWMutex
 	capabilitiesMu         sync.RWMutex
 	toolFiltersMu          sync.RWMutex
 	tasksMu                sync.RWMutex
+	sessionTerminatorsMu   sync.RWMutex
 
 	name                       string
 	version                    string
@@ -189,19 +220,32 @@ type MCPServer struct {
 	taskTools                  map[string]ServerTaskTool
 	toolHandlerMiddlewares     []ToolHandlerMiddleware
 	resourceHandlerMiddlewares []ResourceHandlerMiddleware
+	messageHandlerMiddlewares  []MessageHandlerMiddleware
+	clientRequestMiddlewares   []ClientRequestMiddleware
 	toolFilters                []ToolFilterFunc
 	notificationHandlers       map[string]NotificationHandlerFunc
+	requestHandlers            map[string]RequestHandlerFunc
 	promptCompletionProvider   PromptCompletionProvider
 	resourceCompletionProvider ResourceCompletionProvider
 	capabilities               serverCapabilities
 	paginationLimit            *int
+	paginationKey              []byte
+	paginationSnapshots        bool
 	sessions                   sync.Map
 	hooks                      *Hooks
 	taskHooks                  *TaskHooks
 	tasks                      map[string]*taskEntry
-	expiredTasks               map[string]time.Time // Tracks recently expired task IDs with expiration timestamp
-	maxConcurrentTasks         *int                 // Optional limit on concurrent running tasks
-	activeTasks                int                  // Current count of running (non-terminal) tasks
+	expiredTasks               map[string]time.Time  // Tracks recently expired task IDs with expiration timestamp
+	maxConcurrentTasks         *int                  // Optional limit on concurrent running tasks
+	activeTasks                int                   // Current count of running (non-terminal) tasks
+	notificationQueues         *notificationQueues   // Per-session notification queues, nil unless enabled
+	requests                   *requestTracker       // Requests being handled, for Shutdown
+	liveness                   *livenessMonitor      // Pings idle sessions, nil unless enabled
+	clientRequestTimeout       time.Duration         // Timeout of requests sent to clients, 0 for none
+	sessionTerminators         []*sessionTerminator  // Transports' session terminators, for the liveness monitor
+	roots                      rootsCache            // Cached roots of the sessions' clients, see RootsFromContext
+	subscriptions              resourceSubscriptions // Resources the sessions subscribed to
+	metrics                    *Metrics              // Collected metrics, nil unless enabled
 }
 
 // WithPaginationLimit sets the pagination limit for the server.
@@ -213,15 +257,16 @@ func WithPaginationLimit(limit int) ServerOption {
 
 // serverCapabilities defines the supported features of the MCP server
 type serverCapabilities struct {
-	tools       *toolCapabilities
-	resources   *resourceCapabilities
-	prompts     *promptCapabilities
-	logging     *bool
-	sampling    *bool
-	elicitation *bool
-	roots       *bool
-	tasks       *taskCapabilities
-	completions *bool
+	tools        *toolCapabilities
+	resources    *resourceCapabilities
+	prompts      *promptCapabilities
+	logging      *bool
+	sampling     *bool
+	elicitation  *bool
+	roots        *bool
+	tasks        *taskCapabilities
+	completions  *bool
+	experimental map[string]any
 }
 
 // resourceCapabilities defines the supported resource-related features
@@ -284,6 +329,18 @@ func WithToolHandlerMiddleware(
 	}
 }
 
+// WithMessageHandlerMiddleware allows adding a middleware around the handling
+// of every JSON-RPC message, including notifications and responses. Unlike
+// hooks, a middleware can change the context passed to handlers, which makes
+// it suitable for tracing.
+func WithMessageHandlerMiddleware(
+	messageHandlerMiddleware MessageHandlerMiddleware,
+) ServerOption {
+	return func(s *MCPServer) {
+		s.messageHandlerMiddlewares = append(s.messageHandlerMiddlewares, messageHandlerMiddleware)
+	}
+}
+
 // WithResourceHandlerMiddleware allows adding a middleware for the
 // resource handler call chain.
 func WithResourceHandlerMiddleware(
@@ -461,10 +518,12 @@ func NewMCPServer(
 		name:                       name,
 		version:                    version,
 		notificationHandlers:       make(map[string]NotificationHandlerFunc),
+		requestHandlers:            make(map[string]RequestHandlerFunc),
 		tasks:                      make(map[string]*taskEntry),
 		expiredTasks:               make(map[string]time.Time),
 		promptCompletionProvider:   &DefaultPromptCompletionProvider{},
 		resourceCompletionProvider: &DefaultResourceCompletionProvider{},
+		requests:                   newRequestTracker(),
 		capabilities: serverCapabilities{
 			tools:       nil,
 			resources:   nil,
@@ -482,6 +541,9 @@ func NewMCPServer(
 		opt(s)
 	}
 
+	if s.metrics != nil {
+		s.metrics.register(s)
+	}
 	return s
 }
 
@@ -587,6 +649,25 @@ func (s *MCPServer) SetResourceTemplates(templates ...ServerResourceTemplate) {
 	s.AddResourceTemplates(templates...)
 }
 
+// DeleteResourceTemplates removes resource templates from the server by
+// their URI templates
+func (s *MCPServer) DeleteResourceTemplates(uriTemplates ...string) {
+	s.resourcesMu.Lock()
+	var exists bool
+	for _, uriTemplate := range uriTemplates {
+		if _, ok := s.resourceTemplates[uriTemplate]; ok {
+			delete(s.resourceTemplates, uriTemplate)
+			exists = true
+		}
+	}
+	s.resourcesMu.Unlock()
+
+	// Send notification to all initialized sessions if listChanged capability is enabled and we actually remove a template
+	if exists && s.capabilities.resources != nil && s.capabilities.resources.listChanged {
+		s.SendNotificationToAllClients(mcp.MethodNotificationResourcesListChanged, nil)
+	}
+}
+
 // AddResourceTemplate registers a new resource template and its handler
 func (s *MCPServer) AddResourceTemplate(
 	template mcp.ResourceTemplate,
@@ -884,6 +965,8 @@ func (s *MCPServer) handleInitialize(
 		capabilities.Completions = &struct{}{}
 	}
 
+	capabilities.Experimental = s.experimentalCapabilities()
+
 	result := mcp.InitializeResult{
 		ProtocolVersion: s.protocolVersion(request.Params.ProtocolVersion),
 		ServerInfo: mcp.Implementation{
@@ -902,6 +985,9 @@ func (s *MCPServer) handleInitialize(
 			sessionWithClientInfo.SetClientInfo(request.Params.ClientInfo)
 			sessionWithClientInfo.SetClientCapabilities(request.Params.Capabilities)
 		}
+		if sessionWithProtocolVersion, ok := session.(SessionWithProtocolVersion); ok {
+			sessionWithProtocolVersion.SetProtocolVersion(result.ProtocolVersion)
+		}
 	}
 
 	return &result, nil
@@ -913,7 +999,7 @@ func (s *MCPServer) protocolVersion(clientVersion string) string {
 	// during initialization - the server SHOULD assume protocol version 2025-03-26
 	// https://modelcontextprotocol.io/specification/2025-06-18/basic/transports#protocol-version-header
 	if len(clientVersion) == 0 {
-		clientVersion = "2025-03-26"
+		clientVersion = protocolVersionDefault
 	}
 
 	if slices.Contains(mcp.ValidProtocolVersions, clientVersion) {
@@ -974,42 +1060,6 @@ func (s *MCPServer) handleSetLevel(
 	return &mcp.EmptyResult{}, nil
 }
 
-func listByPagination[T mcp.Named](
-	_ context.Context,
-	s *MCPServer,
-	cursor mcp.Cursor,
-	allElements []T,
-) ([]T, mcp.Cursor, error) {
-	startPos := 0
-	if cursor != "" {
-		c, err := base64.StdEncoding.DecodeString(string(cursor))
-		if err != nil {
-			return nil, "", err
-		}
-		cString := string(c)
-		startPos = sort.Search(len(allElements), func(i int) bool {
-			return allElements[i].GetName() > cString
-		})
-	}
-	endPos := len(allElements)
-	if s.paginationLimit != nil {
-		if len(allElements) > startPos+*s.paginationLimit {
-			endPos = startPos + *s.paginationLimit
-		}
-	}
-	elementsToReturn := allElements[startPos:endPos]
-	// set the next cursor
-	nextCursor := func() mcp.Cursor {
-		if s.paginationLimit != nil && len(elementsToReturn) >= *s.paginationLimit {
-			nc := elementsToReturn[len(elementsToReturn)-1].GetName()
-			toString := base64.StdEncoding.EncodeToString([]byte(nc))
-			return mcp.Cursor(toString)
-		}
-		return ""
-	}()
-	return elementsToReturn, nextCursor, nil
-}
-
 func (s *MCPServer) handleListResources(
 	ctx context.Context,
 	id any,
@@ -1035,17 +1085,12 @@ func (s *MCPServer) handleListResources(
 		}
 	}
 
-	// Sort the resources by name
-	resourcesList := slices.SortedFunc(maps.Values(resourceMap), func(a, b mcp.Resource) int {
-		return cmp.Compare(a.Name, b.Name)
-	})
-
 	// Apply pagination
 	resourcesToReturn, nextCursor, err := listByPagination(
 		ctx,
 		s,
-		request.Params.Cursor,
-		resourcesList,
+		request.Params,
+		slices.Collect(maps.Values(resourceMap)),
 	)
 	if err != nil {
 		return nil, &requestError{
@@ -1095,20 +1140,11 @@ func (s *MCPServer) handleListResourceTemplates(
 		}
 	}
 
-	// Convert map to slice for sorting and pagination
-	templates := make([]mcp.ResourceTemplate, 0, len(templateMap))
-	for _, template := range templateMap {
-		templates = append(templates, template)
-	}
-
-	sort.Slice(templates, func(i, j int) bool {
-		return templates[i].Name < templates[j].Name
-	})
 	templatesToReturn, nextCursor, err := listByPagination(
 		ctx,
 		s,
-		request.Params.Cursor,
-		templates,
+		request.Params,
+		slices.Collect(maps.Values(templateMap)),
 	)
 	if err != nil {
 		return nil, &requestError{
@@ -1282,14 +1318,10 @@ func (s *MCPServer) handleListPrompts(
 	}
 	s.promptsMu.RUnlock()
 
-	// sort prompts by name
-	sort.Slice(prompts, func(i, j int) bool {
-		return prompts[i].Name < prompts[j].Name
-	})
 	promptsToReturn, nextCursor, err := listByPagination(
 		ctx,
 		s,
-		request.Params.Cursor,
+		request.Params,
 		prompts,
 	)
 	if err != nil {
@@ -1337,33 +1369,18 @@ func (s *MCPServer) handleGetPrompt(
 	return result, nil
 }
 
-func (s *MCPServer) handleListTools(
-	ctx context.Context,
-	id any,
-	request mcp.ListToolsRequest,
-) (*mcp.ListToolsResult, *requestError) {
-	// Get the base tools from the server (both regular and task tools)
+// toolsForContext returns the tools visible to the session in ctx: regular
+// tools, task tools and session-specific overrides merged by name, with the
+// tool filters applied.
+func (s *MCPServer) toolsForContext(ctx context.Context) []mcp.Tool {
 	s.toolsMu.RLock()
-	tools := make([]mcp.Tool, 0, len(s.tools)+len(s.taskTools))
-
-	// Get all tool names for consistent ordering
-	toolNames := make([]string, 0, len(s.tools)+len(s.taskTools))
-	for name := range s.tools {
-		toolNames = append(toolNames, name)
-	}
-	for name := range s.taskTools {
-		toolNames = append(toolNames, name)
+	toolMap := make(map[string]mcp.Tool, len(s.tools)+len(s.taskTools))
+	for name, tool := range s.tools {
+		toolMap[name] = tool.Tool
 	}
-
-	// Sort the tool names for consistent ordering
-	sort.Strings(toolNames)
-
-	// Add tools in sorted order
-	for _, name := range toolNames {
-		if tool, ok := s.tools[name]; ok {
-			tools = append(tools, tool.Tool)
-		} else if taskTool, ok := s.taskTools[name]; ok {
-			tools = append(tools, taskTool.Tool)
+	for name, taskTool := range s.taskTools {
+		if _, ok := toolMap[name]; !ok {
+			toolMap[name] = taskTool.Tool
 		}
 	}
 	s.toolsMu.RUnlock()
@@ -1372,34 +1389,12 @@ func (s *MCPServer) handleListTools(
 	session := ClientSessionFromContext(ctx)
 	if session != nil {
 		if sessionWithTools, ok := session.(SessionWithTools); ok {
-			if sessionTools := sessionWithTools.GetSessionTools(); sessionTools != nil {
-				// Override or add session-specific tools
-				// We need to create a map first to merge the tools properly
-				toolMap := make(map[string]mcp.Tool)
-
-				// Add global tools first
-				for _, tool := range tools {
-					toolMap[tool.Name] = tool
-				}
-
-				// Then override with session-specific tools
-				for name, serverTool := range sessionTools {
-					toolMap[name] = serverTool.Tool
-				}
-
-				// Convert back to slice
-				tools = make([]mcp.Tool, 0, len(toolMap))
-				for _, tool := range toolMap {
-					tools = append(tools, tool)
-				}
-
-				// Sort again to maintain consistent ordering
-				sort.Slice(tools, func(i, j int) bool {
-					return tools[i].Name < tools[j].Name
-				})
+			for name, serverTool := range sessionWithTools.GetSessionTools() {
+				toolMap[name] = serverTool.Tool
 			}
 		}
 	}
+	tools := slices.Collect(maps.Values(toolMap))
 
 	// Apply tool filters if any are defined
 	s.toolFiltersMu.RLock()
@@ -1409,12 +1404,22 @@ func (s *MCPServer) handleListTools(
 		}
 	}
 	s.toolFiltersMu.RUnlock()
+	return tools
+}
+
+func (s *MCPServer) handleListTools(
+	ctx context.Context,
+	id any,
+	request mcp.ListToolsRequest,
+) (*mcp.ListToolsResult, *requestError) {
+	// Ordering is left to listByPagination.
+	tools := s.toolsForContext(ctx)
 
 	// Apply pagination
 	toolsToReturn, nextCursor, err := listByPagination(
 		ctx,
 		s,
-		request.Params.Cursor,
+		request.Params,
 		tools,
 	)
 	if err != nil {
@@ -1592,13 +1597,21 @@ func (s *MCPServer) handleTaskAugmentedToolCall(
 		}
 	}
 
-	// Execute tool asynchronously
+	// Execute tool asynchronously. The task outlives this request, so it
+	// keeps the request context alive until it finishes.
 	// For regular tools being used as tasks, we need different execution logic
+	release := retainRequestContext(ctx)
 	if hasTaskHandler {
-		go s.executeTaskTool(ctx, entry, toolToUse, request)
+		go func() {
+			defer release()
+			s.executeTaskTool(ctx, entry, toolToUse, request)
+		}()
 	} else {
 		// Execute regular tool wrapped as a task
-		go s.executeRegularToolAsTask(ctx, entry, regularTool, request)
+		go func() {
+			defer release()
+			s.executeRegularToolAsTask(ctx, entry, regularTool, request)
+		}()
 	}
 
 	// Return CreateTaskResult immediately with task as top-level field
@@ -1775,6 +1788,12 @@ func (s *MCPServer) handleNotification(
 	ctx context.Context,
 	notification mcp.JSONRPCNotification,
 ) mcp.JSONRPCMessage {
+	if notification.Method == mcp.MethodNotificationRootsListChanged {
+		if session := ClientSessionFromContext(ctx); session != nil {
+			s.roots.invalidate(session.SessionID())
+		}
+	}
+
 	s.notificationHandlersMu.RLock()
 	handler, ok := s.notificationHandlers[notification.Method]
 	s.notificationHandlersMu.RUnlock()
@@ -1832,16 +1851,11 @@ func (s *MCPServer) handleListTasks(
 ) (*mcp.ListTasksResult, *requestError) {
 	tasks := s.listTasks(ctx)
 
-	// Sort tasks by TaskId for consistent pagination
-	sort.Slice(tasks, func(i, j int) bool {
-		return tasks[i].TaskId < tasks[j].TaskId
-	})
-
 	// Apply pagination
 	tasksToReturn, nextCursor, err := listByPagination(
 		ctx,
 		s,
-		request.Params.Cursor,
+		request.Params,
 		tasks,
 	)
 	if err != nil {
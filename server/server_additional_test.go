@@ -2,7 +2,6 @@ package server
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -246,7 +245,8 @@ func TestMCPServer_PaginationEdgeCases(t *testing.T) {
 		}
 
 		// Create cursor that points beyond the list
-		beyondCursor := base64.StdEncoding.EncodeToString([]byte("tool-99"))
+		beyondCursor, err := server.encodeCursor(paginationCursor{Format: cursorFormat, Kind: "mcp.Tool", After: "tool-99"})
+		require.NoError(t, err)
 
 		response := server.HandleMessage(context.Background(), fmt.Appendf(nil, `{
 			"jsonrpc": "2.0",
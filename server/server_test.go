@@ -465,7 +465,8 @@ func TestMCPServer_HandleValidMessages(t *testing.T) {
 
 func TestMCPServer_HandlePagination(t *testing.T) {
 	server := createTestServer()
-	cursor := base64.StdEncoding.EncodeToString([]byte("My Resource"))
+	cursor, err := server.encodeCursor(paginationCursor{Format: cursorFormat, Kind: "mcp.Resource", After: "resource://testresource"})
+	require.NoError(t, err)
 	tests := []struct {
 		name     string
 		message  string
@@ -2255,8 +2256,11 @@ func BenchmarkMCPServer_Pagination(b *testing.B) {
 	list := getTools(10000)
 	ctx := context.Background()
 	server := createTestServer()
+	cursor, err := server.encodeCursor(paginationCursor{Format: cursorFormat, Kind: "mcp.Tool", After: "tool654"})
+	require.NoError(b, err)
+	params := mcp.PaginatedParams{Cursor: cursor}
 	for i := 0; i < b.N; i++ {
-		_, _, _ = listByPagination(ctx, server, "dG9vbDY1NA==", list)
+		_, _, _ = listByPagination(ctx, server, params, list)
 	}
 }
 
@@ -4144,3 +4148,35 @@ func TestServerTaskTool_TypeDefinitions(t *testing.T) {
 		assert.Equal(t, 0, len(server.taskTools))
 	})
 }
+
+func TestMCPServer_MessageHandlerMiddleware(t *testing.T) {
+	type contextKey struct{}
+	var calls []string
+	middleware := func(name string) MessageHandlerMiddleware {
+		return func(next MessageHandlerFunc) MessageHandlerFunc {
+			return func(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage {
+				calls = append(calls, name)
+				assert.NotNil(t, ServerFromContext(ctx))
+				return next(context.WithValue(ctx, contextKey{}, name), message)
+			}
+		}
+	}
+
+	server := NewMCPServer("test-server", "1.0.0",
+		WithMessageHandlerMiddleware(middleware("outer")),
+		WithMessageHandlerMiddleware(middleware("inner")),
+	)
+	server.AddTool(mcp.NewTool("context"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(ctx.Value(contextKey{}).(string)), nil
+	})
+
+	response := server.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"context"}}`,
+	))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected response, got %#v", response)
+	result, ok := resp.Result.(*mcp.CallToolResult)
+	require.True(t, ok)
+	assert.Equal(t, "inner", result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, []string{"outer", "inner"}, calls)
+}
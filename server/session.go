@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"maps"
 	"net/url"
@@ -76,6 +77,16 @@ type SessionWithClientInfo interface {
 	SetClientCapabilities(clientCapabilities mcp.ClientCapabilities)
 }
 
+// SessionWithProtocolVersion is an extension of ClientSession that can store the
+// protocol version negotiated during initialization
+type SessionWithProtocolVersion interface {
+	ClientSession
+	// GetProtocolVersion returns the negotiated protocol version, or "" before initialization
+	GetProtocolVersion() string
+	// SetProtocolVersion sets the negotiated protocol version
+	SetProtocolVersion(version string)
+}
+
 // SessionWithElicitation is an extension of ClientSession that can send elicitation requests
 type SessionWithElicitation interface {
 	ClientSession
@@ -90,6 +101,21 @@ type SessionWithRoots interface {
 	ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error)
 }
 
+// SessionWithPing is an extension of ClientSession that can send ping requests
+type SessionWithPing interface {
+	ClientSession
+	// Ping sends a ping request to the client and waits for the response
+	Ping(ctx context.Context) error
+}
+
+// SessionWithRequests is an extension of ClientSession that can send requests
+// with any method to the client
+type SessionWithRequests interface {
+	ClientSession
+	// SendRequest sends a request to the client and waits for the raw result
+	SendRequest(ctx context.Context, method string, params any) (json.RawMessage, error)
+}
+
 // SessionWithStreamableHTTPConfig extends ClientSession to support streamable HTTP transport configurations
 type SessionWithStreamableHTTPConfig interface {
 	ClientSession
@@ -134,6 +160,9 @@ func (s *MCPServer) RegisterSession(
 	if _, exists := s.sessions.LoadOrStore(sessionID, session); exists {
 		return ErrSessionExists
 	}
+	if s.liveness != nil {
+		s.liveness.start(s)
+	}
 	s.hooks.RegisterSession(ctx, session)
 	return nil
 }
@@ -175,25 +204,9 @@ func (s *MCPServer) sendNotificationToAllClients(notification mcp.JSONRPCNotific
 			if sessionWithStreamableHTTPConfig, ok := session.(SessionWithStreamableHTTPConfig); ok {
 				sessionWithStreamableHTTPConfig.UpgradeToSSEWhenReceiveNotification()
 			}
-			select {
-			case session.NotificationChannel() <- notification:
-				// Successfully sent notification
-			default:
-				// Channel is blocked, if there's an error hook, use it
-				if s.hooks != nil && len(s.hooks.OnError) > 0 {
-					err := ErrNotificationChannelBlocked
-					// Copy hooks pointer to local variable to avoid race condition
-					hooks := s.hooks
-					go func(sessionID string, hooks *Hooks) {
-						ctx := context.Background()
-						// Use the error hook to report the blocked channel
-						hooks.onError(ctx, nil, "notification", map[string]any{
-							"method":    notification.Method,
-							"sessionID": sessionID,
-						}, fmt.Errorf("notification channel blocked for session %s: %w", sessionID, err))
-					}(session.SessionID(), hooks)
-				}
-			}
+			// Broadcasts are sent from code that may hold locks, such as task
+			// completion, so they do not wait for room in a full queue.
+			_ = s.deliverNotification(context.Background(), session, notification, false)
 		}
 		return true
 	})
@@ -204,28 +217,50 @@ func (s *MCPServer) sendNotificationToSpecificClient(session ClientSession, noti
 	if sessionWithStreamableHTTPConfig, ok := session.(SessionWithStreamableHTTPConfig); ok {
 		sessionWithStreamableHTTPConfig.UpgradeToSSEWhenReceiveNotification()
 	}
+	return s.deliverNotification(context.Background(), session, notification, true)
+}
+
+// deliverNotification queues notification for session if the server has
+// notification queues, and otherwise sends it to the session's notification
+// channel without blocking. Dropped notifications are reported to the error
+// hooks. If wait is false, the caller does not wait for room in a full queue,
+// see enqueueNotification.
+func (s *MCPServer) deliverNotification(
+	ctx context.Context,
+	session ClientSession,
+	notification mcp.JSONRPCNotification,
+	wait bool,
+) error {
+	if queued, err := s.enqueueNotification(ctx, session, notification, wait); queued {
+		return err
+	}
 	select {
 	case session.NotificationChannel() <- notification:
 		return nil
 	default:
-		// Channel is blocked, if there's an error hook, use it
-		if s.hooks != nil && len(s.hooks.OnError) > 0 {
-			err := ErrNotificationChannelBlocked
-			ctx := context.Background()
-			// Copy hooks pointer to local variable to avoid race condition
-			hooks := s.hooks
-			go func(sID string, hooks *Hooks) {
-				// Use the error hook to report the blocked channel
-				hooks.onError(ctx, nil, "notification", map[string]any{
-					"method":    notification.Method,
-					"sessionID": sID,
-				}, fmt.Errorf("notification channel blocked for session %s: %w", sID, err))
-			}(session.SessionID(), hooks)
-		}
+		s.reportBlockedNotification(ctx, session.SessionID(), notification.Method)
 		return ErrNotificationChannelBlocked
 	}
 }
 
+// reportBlockedNotification reports a dropped notification to the error
+// hooks, if there are any.
+func (s *MCPServer) reportBlockedNotification(ctx context.Context, sessionID, method string) {
+	if s.hooks == nil || len(s.hooks.OnError) == 0 {
+		return
+	}
+	err := ErrNotificationChannelBlocked
+	// Copy hooks pointer to local variable to avoid race condition
+	hooks := s.hooks
+	go func(sessionID string, hooks *Hooks) {
+		// Use the error hook to report the blocked channel
+		hooks.onError(ctx, nil, "notification", map[string]any{
+			"method":    method,
+			"sessionID": sessionID,
+		}, fmt.Errorf("notification channel blocked for session %s: %w", sessionID, err))
+	}(sessionID, hooks)
+}
+
 func (s *MCPServer) SendLogMessageToSpecificClient(sessionID string, notification mcp.LoggingMessageNotification) error {
 	sessionValue, ok := s.sessions.Load(sessionID)
 	if !ok {
@@ -254,6 +289,9 @@ func (s *MCPServer) UnregisterSession(
 	if !ok {
 		return
 	}
+	s.closeNotificationQueue(sessionID)
+	s.roots.remove(sessionID)
+	s.subscriptions.removeSession(sessionID)
 	if session, ok := sessionValue.(ClientSession); ok {
 		s.hooks.UnregisterSession(ctx, session)
 	}
@@ -286,26 +324,7 @@ func (s *MCPServer) sendNotificationCore(
 	if sessionWithStreamableHTTPConfig, ok := session.(SessionWithStreamableHTTPConfig); ok {
 		sessionWithStreamableHTTPConfig.UpgradeToSSEWhenReceiveNotification()
 	}
-	select {
-	case session.NotificationChannel() <- notification:
-		return nil
-	default:
-		// Channel is blocked, if there's an error hook, use it
-		if s.hooks != nil && len(s.hooks.OnError) > 0 {
-			method := notification.Method
-			err := ErrNotificationChannelBlocked
-			// Copy hooks pointer to local variable to avoid race condition
-			hooks := s.hooks
-			go func(sessionID string, hooks *Hooks) {
-				// Use the error hook to report the blocked channel
-				hooks.onError(ctx, nil, "notification", map[string]any{
-					"method":    method,
-					"sessionID": sessionID,
-				}, fmt.Errorf("notification channel blocked for session %s: %w", sessionID, err))
-			}(session.SessionID(), hooks)
-		}
-		return ErrNotificationChannelBlocked
-	}
+	return s.deliverNotification(ctx, session, notification, true)
 }
 
 // SendNotificationToClient sends a notification to the current client
@@ -0,0 +1,332 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// LogHandler is a slog.Handler that forwards log records to the client as
+// notifications/message. The client is taken from the context passed to the
+// logger, so records are only forwarded when the logger is called with the
+// context of a request handler:
+//
+//	logger := slog.New(server.NewLogHandler(server.WithLogHandlerNext(slog.Default().Handler())))
+//
+//	func handleTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+//		logger.InfoContext(ctx, "processing", "tool", request.Params.Name)
+//		...
+//	}
+//
+// Records below the level the client selected with logging/setLevel are not
+// forwarded. Forwarded notifications are rate limited per session; records
+// exceeding the limit are dropped for the client but still reach the next
+// handler, if one is configured.
+type LogHandler struct {
+	next       slog.Handler
+	loggerName string
+	limiter    *logRateLimiter
+
+	// attrs holds the attributes added with WithAttrs, nested under the
+	// groups opened with WithGroup.
+	attrs  map[string]any
+	groups []string
+}
+
+// LogHandlerOption configures a LogHandler.
+type LogHandlerOption func(*LogHandler)
+
+// WithLogHandlerNext passes every record to next in addition to forwarding it
+// to the client, for example to keep writing logs to stderr.
+func WithLogHandlerNext(next slog.Handler) LogHandlerOption {
+	return func(h *LogHandler) {
+		h.next = next
+	}
+}
+
+// WithLoggerName sets the logger name sent with every notification.
+func WithLoggerName(name string) LogHandlerOption {
+	return func(h *LogHandler) {
+		h.loggerName = name
+	}
+}
+
+// WithLogRateLimit limits the notifications sent to each session to perSecond
+// on average, allowing bursts of up to burst notifications. A non-positive
+// perSecond disables rate limiting.
+func WithLogRateLimit(perSecond float64, burst int) LogHandlerOption {
+	return func(h *LogHandler) {
+		if perSecond <= 0 {
+			h.limiter = nil
+			return
+		}
+		h.limiter = newLogRateLimiter(perSecond, max(burst, 1))
+	}
+}
+
+// NewLogHandler creates a LogHandler. By default, each session receives at
+// most 10 notifications per second with bursts of up to 50.
+func NewLogHandler(opts ...LogHandlerOption) *LogHandler {
+	h := &LogHandler{
+		limiter: newLogRateLimiter(10, 50),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Enabled reports whether a record at level would be forwarded to the client
+// of the session in ctx or handled by the next handler.
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.next != nil && h.next.Enabled(ctx, level) {
+		return true
+	}
+	_, ok := h.loggingSession(ctx, level)
+	return ok
+}
+
+// Handle passes the record to the next handler and forwards it to the client.
+func (h *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	if h.next != nil && h.next.Enabled(ctx, record.Level) {
+		if err := h.next.Handle(ctx, record.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if session, ok := h.loggingSession(ctx, record.Level); ok {
+		if h.limiter == nil || h.limiter.allow(session.SessionID(), time.Now()) {
+			notification := mcp.NewLoggingMessageNotification(
+				loggingLevelFromSlog(record.Level),
+				h.loggerName,
+				h.recordData(record),
+			)
+			if err := ServerFromContext(ctx).SendLogMessageToClient(ctx, notification); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a handler that includes attrs in every record.
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	clone := h.clone()
+	if h.next != nil {
+		clone.next = h.next.WithAttrs(attrs)
+	}
+	group := clone.attrs
+	for _, name := range clone.groups {
+		next, ok := group[name].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			group[name] = next
+		}
+		group = next
+	}
+	addLogAttrs(group, attrs)
+	return clone
+}
+
+// WithGroup returns a handler that nests subsequent attributes under name.
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := h.clone()
+	if h.next != nil {
+		clone.next = h.next.WithGroup(name)
+	}
+	clone.groups = append(clone.groups, name)
+	return clone
+}
+
+// loggingSession returns the session in ctx if a record at level should be
+// sent to it.
+func (h *LogHandler) loggingSession(ctx context.Context, level slog.Level) (SessionWithLogging, bool) {
+	if ServerFromContext(ctx) == nil {
+		return nil, false
+	}
+	session, ok := ClientSessionFromContext(ctx).(SessionWithLogging)
+	if !ok || !session.Initialized() {
+		return nil, false
+	}
+	return session, loggingLevelFromSlog(level).ShouldSendTo(session.GetLogLevel())
+}
+
+// recordData builds the data of the notification: the message under "msg"
+// and the attributes as a JSON object, with groups as nested objects.
+func (h *LogHandler) recordData(record slog.Record) map[string]any {
+	data := cloneLogAttrs(h.attrs)
+	data["msg"] = record.Message
+
+	group := data
+	for _, name := range h.groups {
+		next, ok := group[name].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			group[name] = next
+		}
+		group = next
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addLogAttrs(group, []slog.Attr{attr})
+		return true
+	})
+	return data
+}
+
+// clone returns a copy of h that can be modified without affecting h.
+func (h *LogHandler) clone() *LogHandler {
+	return &LogHandler{
+		next:       h.next,
+		loggerName: h.loggerName,
+		limiter:    h.limiter,
+		attrs:      cloneLogAttrs(h.attrs),
+		groups:     append([]string(nil), h.groups...),
+	}
+}
+
+// addLogAttrs adds attrs to group following the slog.Handler rules: empty
+// attributes are ignored and groups without a key are inlined.
+func addLogAttrs(group map[string]any, attrs []slog.Attr) {
+	for _, attr := range attrs {
+		attr.Value = attr.Value.Resolve()
+		if attr.Equal(slog.Attr{}) {
+			continue
+		}
+		if attr.Value.Kind() != slog.KindGroup {
+			group[attr.Key] = logAttrValue(attr.Value)
+			continue
+		}
+		groupAttrs := attr.Value.Group()
+		if len(groupAttrs) == 0 {
+			continue
+		}
+		if attr.Key == "" {
+			addLogAttrs(group, groupAttrs)
+			continue
+		}
+		nested, ok := group[attr.Key].(map[string]any)
+		if !ok {
+			nested = map[string]any{}
+			group[attr.Key] = nested
+		}
+		addLogAttrs(nested, groupAttrs)
+	}
+}
+
+// logAttrValue converts a resolved slog value to a JSON friendly value.
+func logAttrValue(value slog.Value) any {
+	switch value.Kind() {
+	case slog.KindDuration:
+		return value.Duration().String()
+	case slog.KindTime:
+		return value.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return err.Error()
+		}
+	}
+	return value.Any()
+}
+
+func cloneLogAttrs(attrs map[string]any) map[string]any {
+	clone := make(map[string]any, len(attrs)+1)
+	for key, value := range attrs {
+		if nested, ok := value.(map[string]any); ok {
+			value = cloneLogAttrs(nested)
+		}
+		clone[key] = value
+	}
+	return clone
+}
+
+// loggingLevelFromSlog maps a slog level to the closest MCP logging level.
+// Levels between the slog constants map to the level below them, except that
+// the gap between Info and Warn maps to notice, and anything above Error maps
+// to critical.
+func loggingLevelFromSlog(level slog.Level) mcp.LoggingLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return mcp.LoggingLevelDebug
+	case level == slog.LevelInfo:
+		return mcp.LoggingLevelInfo
+	case level < slog.LevelWarn:
+		return mcp.LoggingLevelNotice
+	case level < slog.LevelError:
+		return mcp.LoggingLevelWarning
+	case level == slog.LevelError:
+		return mcp.LoggingLevelError
+	default:
+		return mcp.LoggingLevelCritical
+	}
+}
+
+// logRateLimiter is a token bucket per session.
+type logRateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*logBucket
+}
+
+type logBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// maxLogBuckets bounds the number of tracked sessions. When it is reached,
+// buckets that have refilled completely are discarded, since a new bucket
+// behaves the same.
+const maxLogBuckets = 1024
+
+func newLogRateLimiter(perSecond float64, burst int) *logRateLimiter {
+	return &logRateLimiter{
+		rate:    perSecond,
+		burst:   float64(burst),
+		buckets: make(map[string]*logBucket),
+	}
+}
+
+// allow takes a token from the bucket of sessionID, reporting whether one was
+// available at now.
+func (l *logRateLimiter) allow(sessionID string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[sessionID]
+	if !ok {
+		if len(l.buckets) >= maxLogBuckets {
+			l.prune(now)
+		}
+		bucket = &logBucket{tokens: l.burst, last: now}
+		l.buckets[sessionID] = bucket
+	}
+
+	bucket.tokens = min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+func (l *logRateLimiter) prune(now time.Time) {
+	for id, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, id)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func newLogHandlerTestContext(t *testing.T, level mcp.LoggingLevel) (context.Context, chan mcp.JSONRPCNotification) {
+	t.Helper()
+	server := NewMCPServer("test-server", "1.0.0", WithLogging())
+	notifications := make(chan mcp.JSONRPCNotification, 100)
+	session := &sessionTestClientWithLogging{
+		sessionID:           "session-1",
+		notificationChannel: notifications,
+	}
+	session.Initialize()
+	session.SetLogLevel(level)
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+
+	ctx := server.WithContext(context.Background(), session)
+	return context.WithValue(ctx, serverKey{}, server), notifications
+}
+
+func receiveLogNotification(t *testing.T, notifications chan mcp.JSONRPCNotification) map[string]any {
+	t.Helper()
+	select {
+	case notification := <-notifications:
+		assert.Equal(t, "notifications/message", notification.Method)
+		return notification.Params.AdditionalFields
+	default:
+		t.Fatal("expected a log notification")
+		return nil
+	}
+}
+
+func TestLogHandler_ForwardsRecords(t *testing.T) {
+	ctx, notifications := newLogHandlerTestContext(t, mcp.LoggingLevelInfo)
+	logger := slog.New(NewLogHandler(WithLoggerName("tools")))
+
+	logger.With("tool", "search").WithGroup("request").InfoContext(ctx, "processing",
+		"query", "mcp",
+		slog.Group("page", "size", 10),
+		"err", errors.New("boom"),
+	)
+
+	params := receiveLogNotification(t, notifications)
+	assert.Equal(t, mcp.LoggingLevelInfo, params["level"])
+	assert.Equal(t, "tools", params["logger"])
+	assert.Equal(t, map[string]any{
+		"msg":  "processing",
+		"tool": "search",
+		"request": map[string]any{
+			"query": "mcp",
+			"page":  map[string]any{"size": int64(10)},
+			"err":   "boom",
+		},
+	}, params["data"])
+}
+
+func TestLogHandler_HonoursSessionLevel(t *testing.T) {
+	ctx, notifications := newLogHandlerTestContext(t, mcp.LoggingLevelWarning)
+	handler := NewLogHandler()
+	logger := slog.New(handler)
+
+	assert.False(t, handler.Enabled(ctx, slog.LevelInfo))
+	assert.True(t, handler.Enabled(ctx, slog.LevelWarn))
+
+	logger.DebugContext(ctx, "debug")
+	logger.InfoContext(ctx, "info")
+	logger.WarnContext(ctx, "warn")
+	logger.ErrorContext(ctx, "error")
+
+	assert.Equal(t, mcp.LoggingLevelWarning, receiveLogNotification(t, notifications)["level"])
+	assert.Equal(t, mcp.LoggingLevelError, receiveLogNotification(t, notifications)["level"])
+	assert.Empty(t, notifications)
+}
+
+func TestLogHandler_WithoutSession(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewLogHandler(WithLogHandlerNext(slog.NewTextHandler(&buf, nil)))
+
+	slog.New(handler).InfoContext(context.Background(), "local only")
+	assert.Contains(t, buf.String(), "local only")
+	assert.False(t, NewLogHandler().Enabled(context.Background(), slog.LevelError))
+}
+
+func TestLogHandler_RateLimit(t *testing.T) {
+	ctx, notifications := newLogHandlerTestContext(t, mcp.LoggingLevelDebug)
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(
+		WithLogRateLimit(0.001, 3),
+		WithLogHandlerNext(slog.NewTextHandler(&buf, nil)),
+	))
+
+	for range 10 {
+		logger.InfoContext(ctx, "message")
+	}
+
+	assert.Len(t, notifications, 3)
+	assert.Equal(t, 10, bytes.Count(buf.Bytes(), []byte("message")), "next handler is not rate limited")
+}
+
+func TestLogRateLimiter_Refills(t *testing.T) {
+	limiter := newLogRateLimiter(2, 2)
+	now := time.Now()
+
+	assert.True(t, limiter.allow("a", now))
+	assert.True(t, limiter.allow("a", now))
+	assert.False(t, limiter.allow("a", now))
+	assert.True(t, limiter.allow("b", now), "sessions have separate buckets")
+
+	assert.True(t, limiter.allow("a", now.Add(500*time.Millisecond)))
+	assert.False(t, limiter.allow("a", now.Add(500*time.Millisecond)))
+}
+
+func TestLoggingLevelFromSlog(t *testing.T) {
+	tests := map[slog.Level]mcp.LoggingLevel{
+		slog.LevelDebug:     mcp.LoggingLevelDebug,
+		slog.LevelInfo:      mcp.LoggingLevelInfo,
+		slog.LevelInfo + 2:  mcp.LoggingLevelNotice,
+		slog.LevelWarn:      mcp.LoggingLevelWarning,
+		slog.LevelError:     mcp.LoggingLevelError,
+		slog.LevelError + 4: mcp.LoggingLevelCritical,
+	}
+	for level, expected := range tests {
+		assert.Equal(t, expected, loggingLevelFromSlog(level), level.String())
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -17,6 +16,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/util"
 )
 
 // sseSession represents an active SSE connection.
@@ -24,7 +24,6 @@ type sseSession struct {
 	done                chan struct{}
 	eventQueue          chan string // Channel for queuing events
 	sessionID           string
-	requestID           atomic.Int64
 	notificationChannel chan mcp.JSONRPCNotification
 	initialized         atomic.Bool
 	loggingLevel        atomic.Value
@@ -33,6 +32,10 @@ type sseSession struct {
 	resourceTemplates   sync.Map     // stores session-specific resource templates
 	clientInfo          atomic.Value // stores session-specific client info
 	clientCapabilities  atomic.Value // stores session-specific client capabilities
+	protocolVersion     atomic.Value // stores the negotiated protocol version
+	lastActive          atomic.Int64 // unix nanos of the last message from the client
+	closeOnce           sync.Once
+	requests            clientRequests // requests sent to the client awaiting a response
 }
 
 // SSEContextFunc is a function that takes an existing context and the current
@@ -171,6 +174,69 @@ func (s *sseSession) GetClientCapabilities() mcp.ClientCapabilities {
 	return mcp.ClientCapabilities{}
 }
 
+func (s *sseSession) GetProtocolVersion() string {
+	if value, ok := s.protocolVersion.Load().(string); ok {
+		return value
+	}
+	return ""
+}
+
+func (s *sseSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
+func (s *sseSession) lastActivity() time.Time {
+	return time.Unix(0, s.lastActive.Load())
+}
+
+// close closes the session's done channel, ending its SSE stream.
+func (s *sseSession) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.requests.close()
+	})
+}
+
+// SendRequest sends a request on the SSE stream and waits for the response,
+// which the client posts to the message endpoint.
+func (s *sseSession) SendRequest(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	return s.requests.send(ctx, s, method, params, func(ctx context.Context, request mcp.JSONRPCRequest) error {
+		message, err := json.Marshal(request)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s request: %w", request.Method, err)
+		}
+		select {
+		case s.eventQueue <- fmt.Sprintf("event: message\ndata: %s\n\n", message):
+			return nil
+		case <-s.done:
+			return ErrSessionClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// Ping implements SessionWithPing.
+func (s *sseSession) Ping(ctx context.Context) error {
+	_, err := s.SendRequest(ctx, string(mcp.MethodPing), nil)
+	return err
+}
+
+// RequestSampling implements SessionWithSampling.
+func (s *sseSession) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	return requestSampling(ctx, s, request)
+}
+
+// RequestElicitation implements SessionWithElicitation.
+func (s *sseSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	return sendClientRequest[mcp.ElicitationResult](ctx, s, mcp.MethodElicitationCreate, request.Params)
+}
+
+// ListRoots implements SessionWithRoots.
+func (s *sseSession) ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
+	return sendClientRequest[mcp.ListRootsResult](ctx, s, mcp.MethodListRoots, nil)
+}
+
 var (
 	_ ClientSession                = (*sseSession)(nil)
 	_ SessionWithTools             = (*sseSession)(nil)
@@ -178,6 +244,12 @@ var (
 	_ SessionWithResourceTemplates = (*sseSession)(nil)
 	_ SessionWithLogging           = (*sseSession)(nil)
 	_ SessionWithClientInfo        = (*sseSession)(nil)
+	_ SessionWithProtocolVersion   = (*sseSession)(nil)
+	_ SessionWithPing              = (*sseSession)(nil)
+	_ SessionWithRequests          = (*sseSession)(nil)
+	_ SessionWithSampling          = (*sseSession)(nil)
+	_ SessionWithElicitation       = (*sseSession)(nil)
+	_ SessionWithRoots             = (*sseSession)(nil)
 )
 
 // SSEServer implements a Server-Sent Events (SSE) based MCP server.
@@ -195,6 +267,10 @@ type SSEServer struct {
 	contextFunc                  SSEContextFunc
 	dynamicBasePathFunc          DynamicBasePathFunc
 	sessionIDGenFunc             SessionIDGenFunc
+	logger                       util.Logger
+	metrics                      *Metrics
+	shuttingDown                 atomic.Bool
+	removeTerminator             func() // Removes the session terminator from the MCPServer
 
 	keepAlive         bool
 	keepAliveInterval time.Duration
@@ -332,6 +408,29 @@ func WithSessionIDGenerator(fn SessionIDGenFunc) SSEOption {
 	}
 }
 
+// WithSSELogger sets the logger for the SSE server.
+func WithSSELogger(logger util.Logger) SSEOption {
+	return func(s *SSEServer) {
+		s.logger = logger
+	}
+}
+
+// WithSSEMetrics reports the server's open SSE streams to metrics and serves
+// metrics on DefaultMetricsPath below the base path.
+func WithSSEMetrics(metrics *Metrics) SSEOption {
+	return func(s *SSEServer) {
+		s.metrics = metrics
+		metrics.addStreams("sse", func() int {
+			streams := 0
+			s.sessions.Range(func(_, _ any) bool {
+				streams++
+				return true
+			})
+			return streams
+		})
+	}
+}
+
 // NewSSEServer creates a new SSE server instance with the given MCP server and options.
 func NewSSEServer(server *MCPServer, opts ...SSEOption) *SSEServer {
 	s := &SSEServer{
@@ -341,6 +440,7 @@ func NewSSEServer(server *MCPServer, opts ...SSEOption) *SSEServer {
 		useFullURLForMessageEndpoint: true,
 		keepAlive:                    false,
 		keepAliveInterval:            10 * time.Second,
+		logger:                       util.DefaultLogger(),
 		sessionIDGenFunc: func(ctx context.Context, r *http.Request) (string, error) {
 			return uuid.New().String(), nil
 		},
@@ -351,6 +451,8 @@ func NewSSEServer(server *MCPServer, opts ...SSEOption) *SSEServer {
 		opt(s)
 	}
 
+	s.removeTerminator = server.addSessionTerminator(s.terminateSession)
+
 	return s
 }
 
@@ -385,9 +487,18 @@ func (s *SSEServer) Start(addr string) error {
 	return srv.ListenAndServe()
 }
 
-// Shutdown gracefully stops the SSE server, closing all active sessions
-// and shutting down the HTTP server.
+// Shutdown gracefully stops the SSE server. It drains the MCPServer, waiting
+// for in-flight requests and tasks until ctx is done (see
+// MCPServer.Shutdown), then closes all active sessions and shuts down the
+// HTTP server. New requests are rejected while draining.
 func (s *SSEServer) Shutdown(ctx context.Context) error {
+	s.shuttingDown.Store(true)
+
+	// Responses are sent on the SSE streams, so they stay open while the
+	// MCPServer drains.
+	drainErr := s.server.Shutdown(ctx)
+	s.removeTerminator()
+
 	s.mu.RLock()
 	srv := s.srv
 	s.mu.RUnlock()
@@ -395,15 +506,17 @@ func (s *SSEServer) Shutdown(ctx context.Context) error {
 	if srv != nil {
 		s.sessions.Range(func(key, value any) bool {
 			if session, ok := value.(*sseSession); ok {
-				close(session.done)
+				session.close()
 			}
 			s.sessions.Delete(key)
 			return true
 		})
 
-		return srv.Shutdown(ctx)
+		if err := srv.Shutdown(ctx); err != nil {
+			return err
+		}
 	}
-	return nil
+	return drainErr
 }
 
 // handleSSE handles incoming SSE connection requests.
@@ -413,6 +526,10 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	if s.shuttingDown.Load() {
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -441,6 +558,7 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 		sessionID:           sessionID,
 		notificationChannel: make(chan mcp.JSONRPCNotification, 100),
 	}
+	session.lastActive.Store(time.Now().UnixNano())
 
 	s.sessions.Store(sessionID, session)
 	defer s.sessions.Delete(sessionID)
@@ -487,7 +605,7 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 				case <-ticker.C:
 					message := mcp.JSONRPCRequest{
 						JSONRPC: "2.0",
-						ID:      mcp.NewRequestId(session.requestID.Add(1)),
+						ID:      mcp.NewRequestId(session.requests.newRequestID()),
 						Request: mcp.Request{
 							Method: "ping",
 						},
@@ -525,7 +643,7 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 			fmt.Fprint(w, event)
 			flusher.Flush()
 		case <-r.Context().Done():
-			close(session.done)
+			session.close()
 			return
 		case <-session.done:
 			return
@@ -569,6 +687,7 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	session := sessionI.(*sseSession)
+	session.lastActive.Store(time.Now().UnixNano())
 
 	// Set the client context before handling the message
 	ctx := s.server.WithContext(r.Context(), session)
@@ -583,6 +702,13 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Responses to requests sent on the SSE stream go to the request waiting
+	// for them.
+	if session.requests.deliver(rawMessage) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
 	// Create a context that preserves all values from parent ctx but won't be canceled when the parent is canceled.
 	// this is required because the http ctx will be canceled when the client disconnects
 	detachedCtx := context.WithoutCancel(ctx)
@@ -604,7 +730,7 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 			var message string
 			if eventData, err := json.Marshal(response); err != nil {
 				// If there is an error marshalling the response, send a generic error response
-				log.Printf("failed to marshal response: %v", err)
+				s.logger.Errorf("failed to marshal response: %v", err)
 				message = "event: message\ndata: {\"error\": \"internal error\",\"jsonrpc\": \"2.0\", \"id\": null}\n\n"
 			} else {
 				message = fmt.Sprintf("event: message\ndata: %s\n\n", eventData)
@@ -618,12 +744,24 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 				// Session is closed, don't try to queue
 			default:
 				// Queue is full, log this situation
-				log.Printf("Event queue full for session %s", sessionID)
+				s.logger.Errorf("Event queue full for session %s", sessionID)
 			}
 		}
 	}(messageCtx)
 }
 
+// terminateSession unregisters the session with the given ID and closes its
+// SSE stream, reporting whether the session existed.
+func (s *SSEServer) terminateSession(ctx context.Context, sessionID string) bool {
+	value, ok := s.sessions.LoadAndDelete(sessionID)
+	if !ok {
+		return false
+	}
+	s.server.UnregisterSession(ctx, sessionID)
+	value.(*sseSession).close()
+	return true
+}
+
 // writeJSONRPCError writes a JSON-RPC error response with the given error details.
 func (s *SSEServer) writeJSONRPCError(
 	w http.ResponseWriter,
@@ -801,6 +939,10 @@ func (s *SSEServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		s.handleMessage(w, r)
 		return
 	}
+	if s.metrics != nil && path == normalizeURLPath(s.basePath, DefaultMetricsPath) {
+		s.metrics.ServeHTTP(w, r)
+		return
+	}
 
 	http.NotFound(w, r)
 }
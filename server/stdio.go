@@ -12,8 +12,12 @@ import (
 	"sync"
 	"sync/atomic"
 	"syscall"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/util"
 )
 
 // StdioContextFunc is a function that takes an existing context and returns
@@ -27,19 +31,26 @@ type StdioContextFunc func(ctx context.Context) context.Context
 // communicate via standard input/output streams using JSON-RPC messages.
 type StdioServer struct {
 	server      *MCPServer
-	errLogger   *log.Logger
+	session     *stdioSession
+	errLogger   util.Logger
 	contextFunc StdioContextFunc
 
-	// Thread-safe tool call processing
-	toolCallQueue  chan *toolCallWork
+	// Concurrent request processing
+	requestQueue   chan *requestWork
 	workerWg       sync.WaitGroup
 	workerPoolSize int
 	queueSize      int
-	writeMu        sync.Mutex // Protects concurrent writes
+
+	// Graceful shutdown
+	shutdownTimeout time.Duration
+	mu              sync.Mutex
+	stopListen      context.CancelFunc // Stops Listen, set while listening
+	stopped         atomic.Bool        // Whether Listen was stopped by Shutdown
+	terminated      atomic.Bool        // Whether the session was terminated
 }
 
-// toolCallWork represents a queued tool call request
-type toolCallWork struct {
+// requestWork represents a queued request
+type requestWork struct {
 	ctx     context.Context
 	message json.RawMessage
 	writer  io.Writer
@@ -50,6 +61,14 @@ type StdioOption func(*StdioServer)
 
 // WithErrorLogger sets the error logger for the server
 func WithErrorLogger(logger *log.Logger) StdioOption {
+	return func(s *StdioServer) {
+		s.errLogger = util.NewStdLogger(logger)
+	}
+}
+
+// WithStdioLogger sets the logger the server reports errors to. Use
+// util.NewSlogLogger to log through a *slog.Logger.
+func WithStdioLogger(logger util.Logger) StdioOption {
 	return func(s *StdioServer) {
 		s.errLogger = logger
 	}
@@ -64,68 +83,71 @@ func WithStdioContextFunc(fn StdioContextFunc) StdioOption {
 	}
 }
 
-// WithWorkerPoolSize sets the number of workers for processing tool calls
+// WithWorkerPoolSize sets the number of workers that process requests concurrently.
+// A pool size of 1 processes requests one at a time, in the order they were received.
 func WithWorkerPoolSize(size int) StdioOption {
 	return func(s *StdioServer) {
 		const maxWorkerPoolSize = 100
 		if size > 0 && size <= maxWorkerPoolSize {
 			s.workerPoolSize = size
 		} else if size > maxWorkerPoolSize {
-			s.errLogger.Printf("Worker pool size %d exceeds maximum (%d), using maximum", size, maxWorkerPoolSize)
+			s.errLogger.Errorf("Worker pool size %d exceeds maximum (%d), using maximum", size, maxWorkerPoolSize)
 			s.workerPoolSize = maxWorkerPoolSize
 		}
 	}
 }
 
-// WithQueueSize sets the size of the tool call queue
+// WithQueueSize sets how many requests can wait for a free worker. When the
+// queue is full, further requests are rejected with a JSON-RPC error until a
+// worker becomes available. The server keeps reading input meanwhile, since
+// the responses to its sampling, elicitation and roots requests arrive on the
+// same stream and the busy workers may be waiting for them.
 func WithQueueSize(size int) StdioOption {
 	return func(s *StdioServer) {
 		const maxQueueSize = 10000
 		if size > 0 && size <= maxQueueSize {
 			s.queueSize = size
 		} else if size > maxQueueSize {
-			s.errLogger.Printf("Queue size %d exceeds maximum (%d), using maximum", size, maxQueueSize)
+			s.errLogger.Errorf("Queue size %d exceeds maximum (%d), using maximum", size, maxQueueSize)
 			s.queueSize = maxQueueSize
 		}
 	}
 }
 
-// stdioSession is a static client session, since stdio has only one client.
-type stdioSession struct {
-	notifications       chan mcp.JSONRPCNotification
-	initialized         atomic.Bool
-	loggingLevel        atomic.Value
-	clientInfo          atomic.Value                        // stores session-specific client info
-	clientCapabilities  atomic.Value                        // stores session-specific client capabilities
-	writer              io.Writer                           // for sending requests to client
-	requestID           atomic.Int64                        // for generating unique request IDs
-	mu                  sync.RWMutex                        // protects writer
-	pendingRequests     map[int64]chan *samplingResponse    // for tracking pending sampling requests
-	pendingElicitations map[int64]chan *elicitationResponse // for tracking pending elicitation requests
-	pendingRoots        map[int64]chan *rootsResponse       // for tracking pending list roots requests
-	pendingMu           sync.RWMutex                        // protects pendingRequests and pendingElicitations
-}
-
-// samplingResponse represents a response to a sampling request
-type samplingResponse struct {
-	result *mcp.CreateMessageResult
-	err    error
-}
+// DefaultStdioShutdownTimeout is how long ServeStdio lets in-flight requests
+// and tasks finish after a termination signal.
+const DefaultStdioShutdownTimeout = 5 * time.Second
 
-// elicitationResponse represents a response to an elicitation request
-type elicitationResponse struct {
-	result *mcp.ElicitationResult
-	err    error
+// WithStdioShutdownTimeout sets how long ServeStdio lets in-flight requests
+// and tasks finish after a termination signal, before cancelling them. A
+// second signal stops the server at once.
+func WithStdioShutdownTimeout(timeout time.Duration) StdioOption {
+	return func(s *StdioServer) {
+		if timeout > 0 {
+			s.shutdownTimeout = timeout
+		}
+	}
 }
 
-// rootsResponse represents a response to an list root request
-type rootsResponse struct {
-	result *mcp.ListRootsResult
-	err    error
+// stdioSession is the client session of a StdioServer. Each StdioServer owns
+// one session, since a stdio connection has exactly one client.
+type stdioSession struct {
+	id                 string
+	notifications      chan mcp.JSONRPCNotification
+	initialized        atomic.Bool
+	loggingLevel       atomic.Value
+	clientInfo         atomic.Value   // stores session-specific client info
+	clientCapabilities atomic.Value   // stores session-specific client capabilities
+	protocolVersion    atomic.Value   // stores the negotiated protocol version
+	writer             io.Writer      // for sending requests to client
+	mu                 sync.RWMutex   // protects writer
+	writeMu            sync.Mutex     // serializes writes of requests and responses to the output
+	requests           clientRequests // for tracking pending requests to the client
+	lastActive         atomic.Int64   // unix nanos of the last message from the client
 }
 
 func (s *stdioSession) SessionID() string {
-	return "stdio"
+	return s.id
 }
 
 func (s *stdioSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
@@ -168,6 +190,17 @@ func (s *stdioSession) SetClientCapabilities(clientCapabilities mcp.ClientCapabi
 	s.clientCapabilities.Store(clientCapabilities)
 }
 
+func (s *stdioSession) GetProtocolVersion() string {
+	if value, ok := s.protocolVersion.Load().(string); ok {
+		return value
+	}
+	return ""
+}
+
+func (s *stdioSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
 func (s *stdioSession) SetLogLevel(level mcp.LoggingLevel) {
 	s.loggingLevel.Store(level)
 }
@@ -180,191 +213,58 @@ func (s *stdioSession) GetLogLevel() mcp.LoggingLevel {
 	return level.(mcp.LoggingLevel)
 }
 
-// RequestSampling sends a sampling request to the client and waits for the response.
-func (s *stdioSession) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+// SendRequest writes a request to the client and waits for the response.
+func (s *stdioSession) SendRequest(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	return s.requests.send(ctx, s, method, params, s.writeRequest)
+}
+
+// writeRequest writes request to the client as a single line.
+func (s *stdioSession) writeRequest(_ context.Context, request mcp.JSONRPCRequest) error {
 	s.mu.RLock()
 	writer := s.writer
 	s.mu.RUnlock()
 
 	if writer == nil {
-		return nil, fmt.Errorf("no writer available for sending requests")
+		return fmt.Errorf("no writer available for sending requests")
 	}
 
-	// Generate a unique request ID
-	id := s.requestID.Add(1)
-
-	// Create a response channel for this request
-	responseChan := make(chan *samplingResponse, 1)
-	s.pendingMu.Lock()
-	s.pendingRequests[id] = responseChan
-	s.pendingMu.Unlock()
-
-	// Cleanup function to remove the pending request
-	cleanup := func() {
-		s.pendingMu.Lock()
-		delete(s.pendingRequests, id)
-		s.pendingMu.Unlock()
-	}
-	defer cleanup()
-
-	// Create the JSON-RPC request
-	jsonRPCRequest := struct {
-		JSONRPC string                  `json:"jsonrpc"`
-		ID      int64                   `json:"id"`
-		Method  string                  `json:"method"`
-		Params  mcp.CreateMessageParams `json:"params"`
-	}{
-		JSONRPC: mcp.JSONRPC_VERSION,
-		ID:      id,
-		Method:  string(mcp.MethodSamplingCreateMessage),
-		Params:  request.CreateMessageParams,
-	}
-
-	// Marshal and send the request
-	requestBytes, err := json.Marshal(jsonRPCRequest)
+	requestBytes, err := json.Marshal(request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal sampling request: %w", err)
+		return fmt.Errorf("failed to marshal %s request: %w", request.Method, err)
 	}
 	requestBytes = append(requestBytes, '\n')
 
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
 	if _, err := writer.Write(requestBytes); err != nil {
-		return nil, fmt.Errorf("failed to write sampling request: %w", err)
+		return fmt.Errorf("failed to write %s request: %w", request.Method, err)
 	}
+	return nil
+}
 
-	// Wait for the response or context cancellation
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case response := <-responseChan:
-		if response.err != nil {
-			return nil, response.err
-		}
-		return response.result, nil
-	}
+// RequestSampling sends a sampling request to the client and waits for the response.
+func (s *stdioSession) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	return requestSampling(ctx, s, request)
 }
 
 // ListRoots sends an list roots request to the client and waits for the response.
 func (s *stdioSession) ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
-	s.mu.RLock()
-	writer := s.writer
-	s.mu.RUnlock()
-
-	if writer == nil {
-		return nil, fmt.Errorf("no writer available for sending requests")
-	}
-
-	// Generate a unique request ID
-	id := s.requestID.Add(1)
-
-	// Create a response channel for this request
-	responseChan := make(chan *rootsResponse, 1)
-	s.pendingMu.Lock()
-	s.pendingRoots[id] = responseChan
-	s.pendingMu.Unlock()
-
-	// Cleanup function to remove the pending request
-	cleanup := func() {
-		s.pendingMu.Lock()
-		delete(s.pendingRoots, id)
-		s.pendingMu.Unlock()
-	}
-	defer cleanup()
-
-	// Create the JSON-RPC request
-	jsonRPCRequest := struct {
-		JSONRPC string `json:"jsonrpc"`
-		ID      int64  `json:"id"`
-		Method  string `json:"method"`
-	}{
-		JSONRPC: mcp.JSONRPC_VERSION,
-		ID:      id,
-		Method:  string(mcp.MethodListRoots),
-	}
-
-	// Marshal and send the request
-	requestBytes, err := json.Marshal(jsonRPCRequest)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal list roots request: %w", err)
-	}
-	requestBytes = append(requestBytes, '\n')
-
-	if _, err := writer.Write(requestBytes); err != nil {
-		return nil, fmt.Errorf("failed to write list roots request: %w", err)
-	}
+	return sendClientRequest[mcp.ListRootsResult](ctx, s, mcp.MethodListRoots, nil)
+}
 
-	// Wait for the response or context cancellation
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case response := <-responseChan:
-		if response.err != nil {
-			return nil, response.err
-		}
-		return response.result, nil
-	}
+// Ping sends a ping request to the client and waits for the response.
+func (s *stdioSession) Ping(ctx context.Context) error {
+	_, err := s.SendRequest(ctx, string(mcp.MethodPing), nil)
+	return err
 }
 
 // RequestElicitation sends an elicitation request to the client and waits for the response.
 func (s *stdioSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
-	s.mu.RLock()
-	writer := s.writer
-	s.mu.RUnlock()
-
-	if writer == nil {
-		return nil, fmt.Errorf("no writer available for sending requests")
-	}
-
-	// Generate a unique request ID
-	id := s.requestID.Add(1)
-
-	// Create a response channel for this request
-	responseChan := make(chan *elicitationResponse, 1)
-	s.pendingMu.Lock()
-	s.pendingElicitations[id] = responseChan
-	s.pendingMu.Unlock()
-
-	// Cleanup function to remove the pending request
-	cleanup := func() {
-		s.pendingMu.Lock()
-		delete(s.pendingElicitations, id)
-		s.pendingMu.Unlock()
-	}
-	defer cleanup()
-
-	// Create the JSON-RPC request
-	jsonRPCRequest := struct {
-		JSONRPC string                `json:"jsonrpc"`
-		ID      int64                 `json:"id"`
-		Method  string                `json:"method"`
-		Params  mcp.ElicitationParams `json:"params"`
-	}{
-		JSONRPC: mcp.JSONRPC_VERSION,
-		ID:      id,
-		Method:  string(mcp.MethodElicitationCreate),
-		Params:  request.Params,
-	}
-
-	// Marshal and send the request
-	requestBytes, err := json.Marshal(jsonRPCRequest)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal elicitation request: %w", err)
-	}
-	requestBytes = append(requestBytes, '\n')
-
-	if _, err := writer.Write(requestBytes); err != nil {
-		return nil, fmt.Errorf("failed to write elicitation request: %w", err)
-	}
+	return sendClientRequest[mcp.ElicitationResult](ctx, s, mcp.MethodElicitationCreate, request.Params)
+}
 
-	// Wait for the response or context cancellation
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case response := <-responseChan:
-		if response.err != nil {
-			return nil, response.err
-		}
-		return response.result, nil
-	}
+func (s *stdioSession) lastActivity() time.Time {
+	return time.Unix(0, s.lastActive.Load())
 }
 
 // SetWriter sets the writer for sending requests to the client.
@@ -375,39 +275,54 @@ func (s *stdioSession) SetWriter(writer io.Writer) {
 }
 
 var (
-	_ ClientSession          = (*stdioSession)(nil)
-	_ SessionWithLogging     = (*stdioSession)(nil)
-	_ SessionWithClientInfo  = (*stdioSession)(nil)
-	_ SessionWithSampling    = (*stdioSession)(nil)
-	_ SessionWithElicitation = (*stdioSession)(nil)
-	_ SessionWithRoots       = (*stdioSession)(nil)
+	_ ClientSession              = (*stdioSession)(nil)
+	_ SessionWithLogging         = (*stdioSession)(nil)
+	_ SessionWithClientInfo      = (*stdioSession)(nil)
+	_ SessionWithProtocolVersion = (*stdioSession)(nil)
+	_ SessionWithSampling        = (*stdioSession)(nil)
+	_ SessionWithElicitation     = (*stdioSession)(nil)
+	_ SessionWithRoots           = (*stdioSession)(nil)
+	_ SessionWithPing            = (*stdioSession)(nil)
+	_ SessionWithRequests        = (*stdioSession)(nil)
 )
 
-var stdioSessionInstance = stdioSession{
-	notifications:       make(chan mcp.JSONRPCNotification, 100),
-	pendingRequests:     make(map[int64]chan *samplingResponse),
-	pendingElicitations: make(map[int64]chan *elicitationResponse),
-	pendingRoots:        make(map[int64]chan *rootsResponse),
+// newStdioSession creates a session with a unique ID, so that several stdio
+// servers can be registered with the same MCPServer.
+func newStdioSession() *stdioSession {
+	return &stdioSession{
+		id:            "stdio-" + uuid.New().String(),
+		notifications: make(chan mcp.JSONRPCNotification, 100),
+	}
 }
 
 // NewStdioServer creates a new stdio server wrapper around an MCPServer.
-// It initializes the server with a default error logger that discards all output.
+// It initializes the server with a default error logger that writes to stderr.
+// Each StdioServer has its own client session, so separate instances can
+// listen concurrently on different streams.
 func NewStdioServer(server *MCPServer) *StdioServer {
-	return &StdioServer{
-		server: server,
-		errLogger: log.New(
+	s := &StdioServer{
+		server:  server,
+		session: newStdioSession(),
+		errLogger: util.NewStdLogger(log.New(
 			os.Stderr,
 			"",
 			log.LstdFlags,
-		), // Default to discarding logs
-		workerPoolSize: 5,   // Default worker pool size
-		queueSize:      100, // Default queue size
+		)),
+		workerPoolSize:  5,   // Default worker pool size
+		queueSize:       100, // Default queue size
+		shutdownTimeout: DefaultStdioShutdownTimeout,
 	}
+	return s
 }
 
 // SetErrorLogger configures where error messages from the StdioServer are logged.
 // The provided logger will receive all error messages generated during server operation.
 func (s *StdioServer) SetErrorLogger(logger *log.Logger) {
+	s.errLogger = util.NewStdLogger(logger)
+}
+
+// SetLogger configures the logger that receives error messages from the StdioServer.
+func (s *StdioServer) SetLogger(logger util.Logger) {
 	s.errLogger = logger
 }
 
@@ -424,9 +339,9 @@ func (s *StdioServer) SetContextFunc(fn StdioContextFunc) {
 func (s *StdioServer) handleNotifications(ctx context.Context, stdout io.Writer) {
 	for {
 		select {
-		case notification := <-stdioSessionInstance.notifications:
+		case notification := <-s.session.notifications:
 			if err := s.writeResponse(notification, stdout); err != nil {
-				s.errLogger.Printf("Error writing notification: %v", err)
+				s.errLogger.Errorf("Error writing notification: %v", err)
 			}
 		case <-ctx.Done():
 			return
@@ -451,7 +366,7 @@ func (s *StdioServer) processInputStream(ctx context.Context, reader *bufio.Read
 			if err == io.EOF {
 				return nil
 			}
-			s.errLogger.Printf("Error reading input: %v", err)
+			s.errLogger.Errorf("Error reading input: %v", err)
 			return err
 		}
 
@@ -459,28 +374,27 @@ func (s *StdioServer) processInputStream(ctx context.Context, reader *bufio.Read
 			if err == io.EOF {
 				return nil
 			}
-			s.errLogger.Printf("Error handling message: %v", err)
+			s.errLogger.Errorf("Error handling message: %v", err)
 			return err
 		}
 	}
 }
 
-// toolCallWorker processes tool calls from the queue
-func (s *StdioServer) toolCallWorker(ctx context.Context) {
+// requestWorker processes requests from the queue
+func (s *StdioServer) requestWorker(ctx context.Context) {
 	defer s.workerWg.Done()
 
 	for {
 		select {
-		case work, ok := <-s.toolCallQueue:
+		case work, ok := <-s.requestQueue:
 			if !ok {
 				// Channel closed, exit worker
 				return
 			}
-			// Process the tool call
 			response := s.server.HandleMessage(work.ctx, work.message)
 			if response != nil {
 				if err := s.writeResponse(response, work.writer); err != nil {
-					s.errLogger.Printf("Error writing tool response: %v", err)
+					s.errLogger.Errorf("Error writing response: %v", err)
 				}
 			}
 		case <-ctx.Done():
@@ -523,18 +437,27 @@ func (s *StdioServer) Listen(
 	stdin io.Reader,
 	stdout io.Writer,
 ) error {
-	// Initialize the tool call queue
-	s.toolCallQueue = make(chan *toolCallWork, s.queueSize)
+	// Initialize the request queue
+	s.requestQueue = make(chan *requestWork, s.queueSize)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.mu.Lock()
+	s.stopListen = cancel
+	s.mu.Unlock()
 
 	// Set a static client context since stdio only has one client
-	if err := s.server.RegisterSession(ctx, &stdioSessionInstance); err != nil {
+	s.session.lastActive.Store(time.Now().UnixNano())
+	if err := s.server.RegisterSession(ctx, s.session); err != nil {
 		return fmt.Errorf("register session: %w", err)
 	}
-	defer s.server.UnregisterSession(ctx, stdioSessionInstance.SessionID())
-	ctx = s.server.WithContext(ctx, &stdioSessionInstance)
+	defer s.server.UnregisterSession(ctx, s.session.SessionID())
+	removeTerminator := s.server.addSessionTerminator(s.terminateSession)
+	defer removeTerminator()
+	ctx = s.server.WithContext(ctx, s.session)
 
 	// Set the writer for sending requests to the client
-	stdioSessionInstance.SetWriter(stdout)
+	s.session.SetWriter(stdout)
 
 	// Add in any custom context.
 	if s.contextFunc != nil {
@@ -543,10 +466,10 @@ func (s *StdioServer) Listen(
 
 	reader := bufio.NewReader(stdin)
 
-	// Start worker pool for tool calls
+	// Start worker pool for requests
 	for i := 0; i < s.workerPoolSize; i++ {
 		s.workerWg.Add(1)
-		go s.toolCallWorker(ctx)
+		go s.requestWorker(ctx)
 	}
 
 	// Start notification handler
@@ -556,12 +479,53 @@ func (s *StdioServer) Listen(
 	err := s.processInputStream(ctx, reader, stdout)
 
 	// Shutdown workers gracefully
-	close(s.toolCallQueue)
+	close(s.requestQueue)
 	s.workerWg.Wait()
 
+	if s.stopped.Load() {
+		return nil
+	}
+	if s.terminated.Load() {
+		return ErrSessionTerminated
+	}
+	return err
+}
+
+// Shutdown gracefully stops Listen. It drains the MCPServer, waiting for
+// in-flight requests and tasks until ctx is done (see MCPServer.Shutdown),
+// and then stops reading input. Requests read while draining are rejected,
+// but responses to sampling, elicitation and roots requests are still
+// processed. Listen returns nil once it has stopped.
+func (s *StdioServer) Shutdown(ctx context.Context) error {
+	err := s.server.Shutdown(ctx)
+
+	s.mu.Lock()
+	stop := s.stopListen
+	s.mu.Unlock()
+	if stop != nil {
+		s.stopped.Store(true)
+		stop()
+	}
 	return err
 }
 
+// terminateSession stops Listen if sessionID is the ID of the session of s,
+// for instance when its client stopped answering pings. Listen then returns
+// ErrSessionTerminated.
+func (s *StdioServer) terminateSession(_ context.Context, sessionID string) bool {
+	if sessionID != s.session.SessionID() {
+		return false
+	}
+	s.mu.Lock()
+	stop := s.stopListen
+	s.mu.Unlock()
+	if stop != nil {
+		s.terminated.Store(true)
+		stop()
+	}
+	return true
+}
+
 // processMessage handles a single JSON-RPC message and writes the response.
 // It parses the message, processes it through the wrapped MCPServer, and writes any response.
 // Returns an error if there are issues with message processing or response writing.
@@ -574,6 +538,7 @@ func (s *StdioServer) processMessage(
 	if len(line) == 0 {
 		return nil
 	}
+	s.session.lastActive.Store(time.Now().UnixNano())
 
 	// Parse the message as raw JSON
 	var rawMessage json.RawMessage
@@ -582,250 +547,51 @@ func (s *StdioServer) processMessage(
 		return s.writeResponse(response, writer)
 	}
 
-	// Check if this is a response to a sampling request
-	if s.handleSamplingResponse(rawMessage) {
-		return nil
-	}
-
-	// Check if this is a response to an elicitation request
-	if s.handleElicitationResponse(rawMessage) {
-		return nil
-	}
-
-	// Check if this is a response to an list roots request
-	if s.handleListRootsResponse(rawMessage) {
+	// Check if this is a response to a request sent to the client
+	if s.session.requests.deliver(rawMessage) {
 		return nil
 	}
 
-	// Check if this is a tool call that might need sampling (and thus should be processed concurrently)
+	// Requests are dispatched to the worker pool, so that a slow handler does
+	// not block the connection. Notifications and the initialize request are
+	// handled inline: the initialize response must be sent before any other
+	// request is processed, and notifications such as notifications/cancelled
+	// must take effect in the order they were received.
 	var baseMessage struct {
-		Method string `json:"method"`
-	}
-	if json.Unmarshal(rawMessage, &baseMessage) == nil && baseMessage.Method == "tools/call" {
-		// Queue tool calls for processing by workers
-		select {
-		case s.toolCallQueue <- &toolCallWork{
-			ctx:     ctx,
-			message: rawMessage,
-			writer:  writer,
-		}:
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			// Queue is full, process synchronously as fallback
-			s.errLogger.Printf("Tool call queue full, processing synchronously")
-			response := s.server.HandleMessage(ctx, rawMessage)
-			if response != nil {
-				return s.writeResponse(response, writer)
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if json.Unmarshal(rawMessage, &baseMessage) != nil ||
+		baseMessage.ID == nil ||
+		baseMessage.Method == string(mcp.MethodInitialize) {
+		response := s.server.HandleMessage(ctx, rawMessage)
+
+		// Only write response if there is one (not for notifications)
+		if response != nil {
+			if err := s.writeResponse(response, writer); err != nil {
+				return fmt.Errorf("failed to write response: %w", err)
 			}
-			return nil
-		}
-	}
-
-	// Handle other messages synchronously
-	response := s.server.HandleMessage(ctx, rawMessage)
-
-	// Only write response if there is one (not for notifications)
-	if response != nil {
-		if err := s.writeResponse(response, writer); err != nil {
-			return fmt.Errorf("failed to write response: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// handleSamplingResponse checks if the message is a response to a sampling request
-// and routes it to the appropriate pending request channel.
-func (s *StdioServer) handleSamplingResponse(rawMessage json.RawMessage) bool {
-	return stdioSessionInstance.handleSamplingResponse(rawMessage)
-}
-
-// handleSamplingResponse handles incoming sampling responses for this session
-func (s *stdioSession) handleSamplingResponse(rawMessage json.RawMessage) bool {
-	// Try to parse as a JSON-RPC response
-	var response struct {
-		JSONRPC string          `json:"jsonrpc"`
-		ID      json.Number     `json:"id"`
-		Result  json.RawMessage `json:"result,omitempty"`
-		Error   *struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		} `json:"error,omitempty"`
-	}
-
-	if err := json.Unmarshal(rawMessage, &response); err != nil {
-		return false
-	}
-	// Parse the ID as int64
-	idInt64, err := response.ID.Int64()
-	if err != nil || (response.Result == nil && response.Error == nil) {
-		return false
-	}
-
-	// Look for a pending request with this ID
-	s.pendingMu.RLock()
-	responseChan, exists := s.pendingRequests[idInt64]
-	s.pendingMu.RUnlock()
-
-	if !exists {
-		return false
-	} // Parse and send the response
-	samplingResp := &samplingResponse{}
-
-	if response.Error != nil {
-		samplingResp.err = fmt.Errorf("sampling request failed: %s", response.Error.Message)
-	} else {
-		var result mcp.CreateMessageResult
-		if err := json.Unmarshal(response.Result, &result); err != nil {
-			samplingResp.err = fmt.Errorf("failed to unmarshal sampling response: %w", err)
-		} else {
-			// Parse content from map[string]any to proper Content type (TextContent, ImageContent, AudioContent)
-			if contentMap, ok := result.Content.(map[string]any); ok {
-				content, err := mcp.ParseContent(contentMap)
-				if err != nil {
-					samplingResp.err = fmt.Errorf("failed to parse sampling response content: %w", err)
-				} else {
-					result.Content = content
-					samplingResp.result = &result
-				}
-			} else {
-				samplingResp.result = &result
-			}
-		}
-	}
-
-	// Send the response (non-blocking)
-	select {
-	case responseChan <- samplingResp:
-	default:
-		// Channel is full or closed, ignore
-	}
-
-	return true
-}
-
-// handleElicitationResponse checks if the message is a response to an elicitation request
-// and routes it to the appropriate pending request channel.
-func (s *StdioServer) handleElicitationResponse(rawMessage json.RawMessage) bool {
-	return stdioSessionInstance.handleElicitationResponse(rawMessage)
-}
-
-// handleElicitationResponse handles incoming elicitation responses for this session
-func (s *stdioSession) handleElicitationResponse(rawMessage json.RawMessage) bool {
-	// Try to parse as a JSON-RPC response
-	var response struct {
-		JSONRPC string          `json:"jsonrpc"`
-		ID      json.Number     `json:"id"`
-		Result  json.RawMessage `json:"result,omitempty"`
-		Error   *struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		} `json:"error,omitempty"`
-	}
-
-	if err := json.Unmarshal(rawMessage, &response); err != nil {
-		return false
-	}
-	// Parse the ID as int64
-	id, err := response.ID.Int64()
-	if err != nil || (response.Result == nil && response.Error == nil) {
-		return false
-	}
-
-	// Check if we have a pending elicitation request with this ID
-	s.pendingMu.RLock()
-	responseChan, exists := s.pendingElicitations[id]
-	s.pendingMu.RUnlock()
-
-	if !exists {
-		return false
-	}
-
-	// Parse and send the response
-	elicitationResp := &elicitationResponse{}
-
-	if response.Error != nil {
-		elicitationResp.err = fmt.Errorf("elicitation request failed: %s", response.Error.Message)
-	} else {
-		var result mcp.ElicitationResult
-		if err := json.Unmarshal(response.Result, &result); err != nil {
-			elicitationResp.err = fmt.Errorf("failed to unmarshal elicitation response: %w", err)
-		} else {
-			elicitationResp.result = &result
 		}
+		return nil
 	}
 
-	// Send the response (non-blocking)
+	// Reject the request if no worker has room for it. Waiting for room would
+	// stop reading input, and with it the responses to sampling, elicitation
+	// and roots requests that the workers may be waiting on.
 	select {
-	case responseChan <- elicitationResp:
+	case s.requestQueue <- &requestWork{
+		ctx:     ctx,
+		message: rawMessage,
+		writer:  writer,
+	}:
+		return nil
 	default:
-		// Channel is full or closed, ignore
-	}
-
-	return true
-}
-
-// handleListRootsResponse checks if the message is a response to an list roots request
-// and routes it to the appropriate pending request channel.
-func (s *StdioServer) handleListRootsResponse(rawMessage json.RawMessage) bool {
-	return stdioSessionInstance.handleListRootsResponse(rawMessage)
-}
-
-// handleListRootsResponse handles incoming list root responses for this session
-func (s *stdioSession) handleListRootsResponse(rawMessage json.RawMessage) bool {
-	// Try to parse as a JSON-RPC response
-	var response struct {
-		JSONRPC string          `json:"jsonrpc"`
-		ID      json.Number     `json:"id"`
-		Result  json.RawMessage `json:"result,omitempty"`
-		Error   *struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		} `json:"error,omitempty"`
-	}
-
-	if err := json.Unmarshal(rawMessage, &response); err != nil {
-		return false
-	}
-	// Parse the ID as int64
-	id, err := response.ID.Int64()
-	if err != nil || (response.Result == nil && response.Error == nil) {
-		return false
-	}
-
-	// Check if we have a pending list root request with this ID
-	s.pendingMu.RLock()
-	responseChan, exists := s.pendingRoots[id]
-	s.pendingMu.RUnlock()
-
-	if !exists {
-		return false
-	}
-
-	// Parse and send the response
-	rootsResp := &rootsResponse{}
-
-	if response.Error != nil {
-		rootsResp.err = fmt.Errorf("list root request failed: %s", response.Error.Message)
-	} else {
-		var result mcp.ListRootsResult
-		if err := json.Unmarshal(response.Result, &result); err != nil {
-			rootsResp.err = fmt.Errorf("failed to unmarshal list root response: %w", err)
-		} else {
-			rootsResp.result = &result
+		response := createErrorResponse(baseMessage.ID, mcp.INTERNAL_ERROR, "server is busy: request queue is full")
+		if err := s.writeResponse(response, writer); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
 		}
+		return nil
 	}
-
-	// Send the response (non-blocking)
-	select {
-	case responseChan <- rootsResp:
-	default:
-		// Channel is full or closed, ignore
-	}
-
-	return true
 }
 
 // writeResponse marshals and writes a JSON-RPC response message followed by a newline.
@@ -839,9 +605,9 @@ func (s *StdioServer) writeResponse(
 		return err
 	}
 
-	// Protect concurrent writes
-	s.writeMu.Lock()
-	defer s.writeMu.Unlock()
+	// Protect concurrent writes, including requests sent by the session
+	s.session.writeMu.Lock()
+	defer s.session.writeMu.Unlock()
 
 	// Write response followed by newline
 	if _, err := fmt.Fprintf(writer, "%s\n", responseBytes); err != nil {
@@ -870,6 +636,17 @@ func ServeStdio(server *MCPServer, opts ...StdioOption) error {
 
 	go func() {
 		<-sigChan
+		shutdownCtx, cancelShutdown := context.WithTimeout(ctx, s.shutdownTimeout)
+		defer cancelShutdown()
+		go func() {
+			// A second signal stops the server without waiting.
+			select {
+			case <-sigChan:
+				cancelShutdown()
+			case <-shutdownCtx.Done():
+			}
+		}()
+		_ = s.Shutdown(shutdownCtx)
 		cancel()
 	}()
 
@@ -484,3 +484,282 @@ func TestStdioServer(t *testing.T) {
 		}
 	})
 }
+
+func TestStdioServer_ConcurrentInstances(t *testing.T) {
+	mcpServer := NewMCPServer("test", "1.0.0")
+	mcpServer.EnableSampling()
+	mcpServer.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := mcpServer.RequestSampling(ctx, mcp.CreateMessageRequest{
+			CreateMessageParams: mcp.CreateMessageParams{
+				Messages:  []mcp.SamplingMessage{{Role: mcp.RoleUser, Content: mcp.NewTextContent("who are you?")}},
+				MaxTokens: 10,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(result.Content.(mcp.TextContent).Text), nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sessionIDs := make(chan string, 2)
+	var wg sync.WaitGroup
+	for _, name := range []string{"alice", "bob"} {
+		stdinReader, stdinWriter := io.Pipe()
+		stdoutReader, stdoutWriter := io.Pipe()
+
+		stdioServer := NewStdioServer(mcpServer)
+		stdioServer.SetErrorLogger(log.New(io.Discard, "", 0))
+		sessionIDs <- stdioServer.session.SessionID()
+		go func() {
+			_ = stdioServer.Listen(ctx, stdinReader, stdoutWriter)
+			stdoutWriter.Close()
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer stdinWriter.Close()
+
+			scanner := bufio.NewScanner(stdoutReader)
+			send := func(message any) {
+				data, err := json.Marshal(message)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := stdinWriter.Write(append(data, '\n')); err != nil {
+					t.Error(err)
+				}
+			}
+			receive := func() map[string]any {
+				if !scanner.Scan() {
+					t.Errorf("%s: failed to read message", name)
+					return nil
+				}
+				var message map[string]any
+				if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+					t.Error(err)
+				}
+				return message
+			}
+
+			send(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]any{
+				"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+				"clientInfo":      map[string]any{"name": name, "version": "1.0.0"},
+				"capabilities":    map[string]any{"sampling": map[string]any{}},
+			}})
+			receive()
+			send(map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"})
+			send(map[string]any{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": map[string]any{"name": "whoami"}})
+
+			samplingRequest := receive()
+			if samplingRequest["method"] != string(mcp.MethodSamplingCreateMessage) {
+				t.Errorf("%s: expected sampling request, got %v", name, samplingRequest)
+				return
+			}
+			send(map[string]any{"jsonrpc": "2.0", "id": samplingRequest["id"], "result": map[string]any{
+				"role":    "assistant",
+				"content": map[string]any{"type": "text", "text": name},
+				"model":   "test",
+			}})
+
+			response := receive()
+			result, _ := response["result"].(map[string]any)
+			content, _ := result["content"].([]any)
+			if len(content) != 1 || content[0].(map[string]any)["text"] != name {
+				t.Errorf("%s: expected tool result %q, got %v", name, name, response)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(sessionIDs)
+	first, second := <-sessionIDs, <-sessionIDs
+	if first == second {
+		t.Errorf("expected unique session IDs, got %q twice", first)
+	}
+}
+
+func TestStdioServer_ConcurrentRequests(t *testing.T) {
+	release := make(chan struct{})
+	mcpServer := NewMCPServer("test", "1.0.0", WithResourceCapabilities(false, false))
+	mcpServer.AddResource(mcp.NewResource("test://slow", "slow"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		<-release
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "done"}}, nil
+	})
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	stdioServer := NewStdioServer(mcpServer)
+	stdioServer.SetErrorLogger(log.New(io.Discard, "", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = stdioServer.Listen(ctx, stdinReader, stdoutWriter)
+		stdoutWriter.Close()
+	}()
+	defer stdinWriter.Close()
+
+	scanner := bufio.NewScanner(stdoutReader)
+	receiveID := func() float64 {
+		t.Helper()
+		if !scanner.Scan() {
+			t.Fatal("failed to read response")
+		}
+		var response map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		id, _ := response["id"].(float64)
+		return id
+	}
+
+	send := func(message string) {
+		t.Helper()
+		if _, err := stdinWriter.Write([]byte(message + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"test","version":"1.0.0"}}}`)
+	if id := receiveID(); id != 1 {
+		t.Fatalf("expected initialize response, got id %v", id)
+	}
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	send(`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"test://slow"}}`)
+	send(`{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+
+	if id := receiveID(); id != 3 {
+		t.Fatalf("expected ping response while resources/read is blocked, got id %v", id)
+	}
+	close(release)
+	if id := receiveID(); id != 2 {
+		t.Fatalf("expected resources/read response, got id %v", id)
+	}
+}
+
+func TestStdioServer_QueueFull(t *testing.T) {
+	mcpServer := NewMCPServer("test", "1.0.0", WithToolCapabilities(false))
+	mcpServer.AddTool(mcp.NewTool("ask"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if _, err := mcpServer.SendRequest(ctx, string(mcp.MethodPing), nil); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText("answered"), nil
+	})
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	stdioServer := NewStdioServer(mcpServer)
+	stdioServer.SetErrorLogger(log.New(io.Discard, "", 0))
+	WithWorkerPoolSize(1)(stdioServer)
+	WithQueueSize(1)(stdioServer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = stdioServer.Listen(ctx, stdinReader, stdoutWriter)
+		stdoutWriter.Close()
+	}()
+	defer stdinWriter.Close()
+
+	scanner := bufio.NewScanner(stdoutReader)
+	receive := func() map[string]any {
+		t.Helper()
+		if !scanner.Scan() {
+			t.Fatal("failed to read message")
+		}
+		var message map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			t.Fatal(err)
+		}
+		return message
+	}
+	send := func(message string) {
+		t.Helper()
+		if _, err := stdinWriter.Write([]byte(message + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","clientInfo":{"name":"test","version":"1.0.0"}}}`)
+	receive()
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	// The only worker waits for the client to answer its ping, and the
+	// queue holds one more request, so the last request is rejected.
+	send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"ask"}}`)
+	ping := receive()
+	if ping["method"] != string(mcp.MethodPing) {
+		t.Fatalf("expected ping request, got %v", ping)
+	}
+	send(`{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+	send(`{"jsonrpc":"2.0","id":4,"method":"ping"}`)
+	if rejected := receive(); rejected["id"] != float64(4) || rejected["error"] == nil {
+		t.Fatalf("expected error response to request 4, got %v", rejected)
+	}
+
+	// The answer to the ping is still read while the queue is full.
+	send(fmt.Sprintf(`{"jsonrpc":"2.0","id":%v,"result":{}}`, ping["id"]))
+	ids := map[any]bool{}
+	for range 2 {
+		response := receive()
+		if response["error"] != nil {
+			t.Fatalf("unexpected error response %v", response)
+		}
+		ids[response["id"]] = true
+	}
+	if !ids[float64(2)] || !ids[float64(3)] {
+		t.Fatalf("expected responses to requests 2 and 3, got %v", ids)
+	}
+}
+
+// overlapWriter records whether two writes ever overlapped.
+type overlapWriter struct {
+	active  sync.Mutex
+	overlap bool
+	mu      sync.Mutex
+}
+
+func (w *overlapWriter) Write(p []byte) (int, error) {
+	if !w.active.TryLock() {
+		w.mu.Lock()
+		w.overlap = true
+		w.mu.Unlock()
+		return len(p), nil
+	}
+	defer w.active.Unlock()
+	time.Sleep(100 * time.Microsecond)
+	return len(p), nil
+}
+
+func TestStdioServer_RequestsAndResponsesDoNotInterleave(t *testing.T) {
+	stdioServer := NewStdioServer(NewMCPServer("test", "1.0.0"))
+	writer := &overlapWriter{}
+	stdioServer.session.SetWriter(writer)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			request := mcp.JSONRPCRequest{JSONRPC: mcp.JSONRPC_VERSION, ID: mcp.NewRequestId(int64(i)), Request: mcp.Request{Method: "ping"}}
+			if err := stdioServer.session.writeRequest(context.Background(), request); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := stdioServer.writeResponse(mcp.NewJSONRPCResultResponse(mcp.NewRequestId(int64(i)), mcp.EmptyResult{}), writer); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if writer.overlap {
+		t.Fatal("a request and a response were written at the same time")
+	}
+}
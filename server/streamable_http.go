@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -110,6 +111,18 @@ func WithDisableStreaming(disable bool) StreamableHTTPOption {
 	}
 }
 
+// WithStreamableHTTPMetrics reports the server's open GET streams to metrics.
+// When the server is run with Start, metrics are served on
+// DefaultMetricsPath.
+func WithStreamableHTTPMetrics(metrics *Metrics) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.metrics = metrics
+		metrics.addStreams("streamable_http", func() int {
+			return int(s.openStreams.Load())
+		})
+	}
+}
+
 // WithHTTPContextFunc sets a function that will be called to customise the context
 // to the server using the incoming request.
 // This can be used to inject context values from headers, for example.
@@ -185,7 +198,6 @@ type StreamableHTTPServer struct {
 	sessionTools             *sessionToolsStore
 	sessionResources         *sessionResourcesStore
 	sessionResourceTemplates *sessionResourceTemplatesStore
-	sessionRequestIDs        sync.Map // sessionId --> last requestID(*atomic.Int64)
 	activeSessions           sync.Map // sessionId --> *streamableHttpSession (for sampling responses)
 
 	httpServer *http.Server
@@ -199,6 +211,10 @@ type StreamableHTTPServer struct {
 	logger                   util.Logger
 	sessionLogLevels         *sessionLogLevelsStore
 	disableStreaming         bool
+	metrics                  *Metrics
+	openStreams              atomic.Int64 // GET streams currently listening
+	shuttingDown             atomic.Bool
+	removeTerminator         func() // Removes the session terminator from the MCPServer
 
 	tlsCertFile string
 	tlsKeyFile  string
@@ -239,6 +255,8 @@ func NewStreamableHTTPServer(server *MCPServer, opts ...StreamableHTTPOption) *S
 		s.startSessionSweeper(ctx)
 	}
 
+	s.removeTerminator = server.addSessionTerminator(s.terminateSession)
+
 	return s
 }
 
@@ -265,6 +283,9 @@ func (s *StreamableHTTPServer) Start(addr string) error {
 	if s.httpServer == nil {
 		mux := http.NewServeMux()
 		mux.Handle(s.endpointPath, s)
+		if s.metrics != nil {
+			mux.Handle(DefaultMetricsPath, s.metrics)
+		}
 		s.httpServer = &http.Server{
 			Addr:    addr,
 			Handler: mux,
@@ -295,21 +316,37 @@ func (s *StreamableHTTPServer) Start(addr string) error {
 	return srv.ListenAndServe()
 }
 
-// Shutdown gracefully stops the server, closing all active sessions
-// and shutting down the HTTP server.
+// Shutdown gracefully stops the server. It drains the MCPServer, waiting
+// for in-flight requests and tasks until ctx is done (see
+// MCPServer.Shutdown), then closes the listening streams of all sessions and
+// shuts down the HTTP server. New requests are rejected while draining.
 func (s *StreamableHTTPServer) Shutdown(ctx context.Context) error {
+	s.shuttingDown.Store(true)
 	if s.sweeperCancel != nil {
 		s.sweeperCancel()
 	}
 
+	// Responses to in-flight requests are still written while draining.
+	drainErr := s.server.Shutdown(ctx)
+	s.removeTerminator()
+
+	s.activeSessions.Range(func(_, value any) bool {
+		if session, ok := value.(*streamableHttpSession); ok {
+			session.close()
+		}
+		return true
+	})
+
 	// shutdown the server if needed (may use as a http.Handler)
 	s.mu.RLock()
 	srv := s.httpServer
 	s.mu.RUnlock()
 	if srv != nil {
-		return srv.Shutdown(ctx)
+		if err := srv.Shutdown(ctx); err != nil {
+			return err
+		}
 	}
-	return nil
+	return drainErr
 }
 
 // --- internal methods ---
@@ -343,6 +380,15 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Responses to requests sent on the listening stream, such as sampling
+	// or ping, go to the request waiting for them.
+	isResponse := jsonMessage.Method == "" && jsonMessage.ID != nil &&
+		(jsonMessage.Result != nil || jsonMessage.Error != nil)
+	if isResponse && s.deliverResponse(r, rawData) {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
 	// detect empty ping response, skip session ID validation
 	isEmptyResponse := jsonMessage.Method == "" && jsonMessage.ID != nil &&
 		(isJSONEmpty(jsonMessage.Result) && isJSONEmpty(jsonMessage.Error))
@@ -357,17 +403,12 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Check if this is a sampling response (has result/error but no method)
-	isSamplingResponse := jsonMessage.Method == "" && jsonMessage.ID != nil &&
-		(jsonMessage.Result != nil || jsonMessage.Error != nil)
-
 	isInitializeRequest := jsonMessage.Method == mcp.MethodInitialize
 
-	// Handle sampling responses separately
-	if isSamplingResponse {
-		if err := s.handleSamplingResponse(w, r, jsonMessage); err != nil {
-			s.logger.Errorf("Failed to handle sampling response: %v", err)
-			http.Error(w, "Failed to handle sampling response", http.StatusInternalServerError)
+	// Responses that could not be delivered are rejected
+	if isResponse {
+		if err := s.rejectResponse(w, r, jsonMessage.ID); err != nil {
+			s.logger.Errorf("Failed to handle response: %v", err)
 		}
 		return
 	}
@@ -421,6 +462,14 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 	if session == nil {
 		session = newStreamableHttpSession(sessionID, s.sessionTools, s.sessionResources, s.sessionResourceTemplates, s.sessionLogLevels)
 	}
+	session.lastActive.Store(time.Now().UnixNano())
+
+	if !isInitializeRequest {
+		if err := checkProtocolVersionHeader(r, session); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 
 	// Set the client context before handling the message
 	ctx := s.server.WithContext(r.Context(), session)
@@ -559,6 +608,10 @@ drainLoop:
 func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request) {
 	// get request is for listening to notifications
 	// https://modelcontextprotocol.io/specification/2025-03-26/basic/transports#listening-for-messages-from-the-server
+	if s.shuttingDown.Load() {
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
 	if s.disableStreaming {
 		s.logger.Infof("Rejected GET request: streaming is disabled (session: %s)", r.Header.Get(HeaderKeySessionID))
 		http.Error(w, "Streaming is disabled on this server", http.StatusMethodNotAllowed)
@@ -589,6 +642,13 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 	actual, loaded := s.activeSessions.LoadOrStore(sessionID, newSession)
 	session = actual.(*streamableHttpSession)
 
+	if loaded {
+		if err := checkProtocolVersionHeader(r, session); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	if !loaded {
 		// We created a new session, need to register it
 		if err := s.server.RegisterSession(r.Context(), session); err != nil {
@@ -609,6 +669,10 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 
 	flusher.Flush()
+	s.openStreams.Add(1)
+	defer s.openStreams.Add(-1)
+	session.listening.Add(1)
+	defer session.listening.Add(-1)
 
 	// Start notification handler for this session
 	done := make(chan struct{})
@@ -624,47 +688,9 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 				case <-done:
 					return
 				}
-			case samplingReq := <-session.samplingRequestChan:
-				// Send sampling request to client via SSE
-				jsonrpcRequest := mcp.JSONRPCRequest{
-					JSONRPC: "2.0",
-					ID:      mcp.NewRequestId(samplingReq.requestID),
-					Request: mcp.Request{
-						Method: string(mcp.MethodSamplingCreateMessage),
-					},
-					Params: samplingReq.request.CreateMessageParams,
-				}
+			case request := <-session.requestChan:
 				select {
-				case writeChan <- jsonrpcRequest:
-				case <-done:
-					return
-				}
-			case elicitationReq := <-session.elicitationRequestChan:
-				// Send elicitation request to client via SSE
-				jsonrpcRequest := mcp.JSONRPCRequest{
-					JSONRPC: "2.0",
-					ID:      mcp.NewRequestId(elicitationReq.requestID),
-					Request: mcp.Request{
-						Method: string(mcp.MethodElicitationCreate),
-					},
-					Params: elicitationReq.request.Params,
-				}
-				select {
-				case writeChan <- jsonrpcRequest:
-				case <-done:
-					return
-				}
-			case rootsReq := <-session.rootsRequestChan:
-				// Send list roots request to client via SSE
-				jsonrpcRequest := mcp.JSONRPCRequest{
-					JSONRPC: "2.0",
-					ID:      mcp.NewRequestId(rootsReq.requestID),
-					Request: mcp.Request{
-						Method: string(mcp.MethodListRoots),
-					},
-				}
-				select {
-				case writeChan <- jsonrpcRequest:
+				case writeChan <- request:
 				case <-done:
 					return
 				}
@@ -684,7 +710,7 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 				case <-ticker.C:
 					message := mcp.JSONRPCRequest{
 						JSONRPC: "2.0",
-						ID:      mcp.NewRequestId(s.nextRequestID(sessionID)),
+						ID:      mcp.NewRequestId(session.requests.newRequestID()),
 						Request: mcp.Request{
 							Method: "ping",
 						},
@@ -717,6 +743,8 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 			}
 			flusher.Flush()
 			s.touchSession(sessionID)
+		case <-session.done:
+			return
 		case <-r.Context().Done():
 			return
 		}
@@ -742,6 +770,23 @@ func (s *StreamableHTTPServer) handleDelete(w http.ResponseWriter, r *http.Reque
 	w.WriteHeader(http.StatusOK)
 }
 
+// checkProtocolVersionHeader validates the Mcp-Protocol-Version header of a
+// request. The header is optional, but when present it must be a supported
+// version and match the version negotiated for the session.
+func checkProtocolVersionHeader(r *http.Request, session *streamableHttpSession) error {
+	version := r.Header.Get(HeaderKeyProtocolVersion)
+	if version == "" {
+		return nil
+	}
+	if !slices.Contains(mcp.ValidProtocolVersions, version) {
+		return fmt.Errorf("unsupported protocol version: %s", version)
+	}
+	if negotiated := session.GetProtocolVersion(); negotiated != "" && negotiated != version {
+		return fmt.Errorf("protocol version %s does not match negotiated version %s", version, negotiated)
+	}
+	return nil
+}
+
 func writeSSEEvent(w io.Writer, data any) error {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -754,17 +799,13 @@ func writeSSEEvent(w io.Writer, data any) error {
 	return nil
 }
 
-// handleSamplingResponse processes incoming sampling responses from clients
-func (s *StreamableHTTPServer) handleSamplingResponse(w http.ResponseWriter, r *http.Request, responseMessage struct {
-	ID     json.RawMessage `json:"id"`
-	Result json.RawMessage `json:"result,omitempty"`
-	Error  json.RawMessage `json:"error,omitempty"`
-	Method mcp.MCPMethod   `json:"method,omitempty"`
-}) error {
+// rejectResponse answers a response posted by the client that no request is
+// waiting for, and returns the reason.
+func (s *StreamableHTTPServer) rejectResponse(w http.ResponseWriter, r *http.Request, id json.RawMessage) error {
 	// Get session ID from header
 	sessionID := r.Header.Get(HeaderKeySessionID)
 	if sessionID == "" {
-		http.Error(w, "Missing session ID for sampling response", http.StatusBadRequest)
+		http.Error(w, "Missing session ID for response", http.StatusBadRequest)
 		return fmt.Errorf("missing session ID")
 	}
 
@@ -782,80 +823,23 @@ func (s *StreamableHTTPServer) handleSamplingResponse(w http.ResponseWriter, r *
 
 	// Parse the request ID
 	var requestID int64
-	if err := json.Unmarshal(responseMessage.ID, &requestID); err != nil {
-		http.Error(w, "Invalid request ID in sampling response", http.StatusBadRequest)
+	if err := json.Unmarshal(id, &requestID); err != nil {
+		http.Error(w, "Invalid request ID in response", http.StatusBadRequest)
 		return err
 	}
 
-	// Create the sampling response item
-	response := samplingResponseItem{
-		requestID: requestID,
-	}
-
-	// Parse result or error
-	if responseMessage.Error != nil {
-		// Parse error
-		var jsonrpcError struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		}
-		if err := json.Unmarshal(responseMessage.Error, &jsonrpcError); err != nil {
-			response.err = fmt.Errorf("failed to parse error: %v", err)
-		} else {
-			response.err = fmt.Errorf("sampling error %d: %s", jsonrpcError.Code, jsonrpcError.Message)
-		}
-	} else if responseMessage.Result != nil {
-		// Store the result to be unmarshaled later
-		response.result = responseMessage.Result
-	} else {
-		response.err = fmt.Errorf("sampling response has neither result nor error")
-	}
-
-	// Find the corresponding session and deliver the response
-	// The response is delivered to the specific session identified by sessionID
-	if err := s.deliverSamplingResponse(sessionID, response); err != nil {
-		s.logger.Errorf("Failed to deliver sampling response: %v", err)
-		http.Error(w, "Failed to deliver response", http.StatusInternalServerError)
-		return err
-	}
-
-	// Acknowledge receipt
-	w.WriteHeader(http.StatusAccepted)
-	return nil
+	http.Error(w, "Failed to deliver response", http.StatusInternalServerError)
+	return fmt.Errorf("no pending request found for session %s, request %d", sessionID, requestID)
 }
 
-// deliverSamplingResponse delivers a sampling response to the appropriate session
-func (s *StreamableHTTPServer) deliverSamplingResponse(sessionID string, response samplingResponseItem) error {
-	// Look up the active session
-	sessionInterface, ok := s.activeSessions.Load(sessionID)
-	if !ok {
-		return fmt.Errorf("no active session found for session %s", sessionID)
-	}
-
-	session, ok := sessionInterface.(*streamableHttpSession)
-	if !ok {
-		return fmt.Errorf("invalid session type for session %s", sessionID)
-	}
-
-	// Look up the dedicated response channel for this specific request
-	responseChannelInterface, exists := session.samplingRequests.Load(response.requestID)
-	if !exists {
-		return fmt.Errorf("no pending request found for session %s, request %d", sessionID, response.requestID)
-	}
-
-	responseChan, ok := responseChannelInterface.(chan samplingResponseItem)
+// deliverResponse routes a response posted by the client to the request of
+// its session waiting for it, reporting whether there was one.
+func (s *StreamableHTTPServer) deliverResponse(r *http.Request, rawData []byte) bool {
+	value, ok := s.activeSessions.Load(r.Header.Get(HeaderKeySessionID))
 	if !ok {
-		return fmt.Errorf("invalid response channel type for session %s, request %d", sessionID, response.requestID)
-	}
-
-	// Attempt to deliver the response with timeout to prevent indefinite blocking
-	select {
-	case responseChan <- response:
-		s.logger.Infof("Delivered sampling response for session %s, request %d", sessionID, response.requestID)
-		return nil
-	default:
-		return fmt.Errorf("failed to deliver sampling response for session %s, request %d: channel full or blocked", sessionID, response.requestID)
+		return false
 	}
+	return value.(*streamableHttpSession).requests.deliver(rawData)
 }
 
 // writeJSONRPCError writes a JSON-RPC error response with the given error details.
@@ -874,13 +858,6 @@ func (s *StreamableHTTPServer) writeJSONRPCError(
 	}
 }
 
-// nextRequestID gets the next incrementing requestID for the current session
-func (s *StreamableHTTPServer) nextRequestID(sessionID string) int64 {
-	actual, _ := s.sessionRequestIDs.LoadOrStore(sessionID, new(atomic.Int64))
-	counter := actual.(*atomic.Int64)
-	return counter.Add(1)
-}
-
 // touchSession records the current time as the last activity for the given session.
 // It is a no-op when the sweeper is disabled (sessionIdleTTL <= 0) or sessionID is empty.
 func (s *StreamableHTTPServer) touchSession(sessionID string) {
@@ -892,6 +869,19 @@ func (s *StreamableHTTPServer) touchSession(sessionID string) {
 	actual.(*atomic.Int64).Store(now)
 }
 
+// terminateSession ends the session with the given ID, removes its state
+// and closes its listening stream, reporting whether the session existed.
+func (s *StreamableHTTPServer) terminateSession(ctx context.Context, sessionID string) bool {
+	value, ok := s.activeSessions.Load(sessionID)
+	if !ok {
+		return false
+	}
+	_, _ = s.backgroundSessionIdManager().Terminate(sessionID)
+	s.cleanupSessionState(ctx, sessionID)
+	value.(*streamableHttpSession).close()
+	return true
+}
+
 // cleanupSessionState removes all per-session transport state for the given session ID.
 func (s *StreamableHTTPServer) cleanupSessionState(ctx context.Context, sessionID string) {
 	// Unregister first to stop notification routing before deleting data.
@@ -901,10 +891,18 @@ func (s *StreamableHTTPServer) cleanupSessionState(ctx context.Context, sessionI
 	s.sessionResources.delete(sessionID)
 	s.sessionResourceTemplates.delete(sessionID)
 	s.sessionLogLevels.delete(sessionID)
-	s.sessionRequestIDs.Delete(sessionID)
 	s.sessionLastActive.Delete(sessionID)
 }
 
+// backgroundSessionIdManager returns the session ID manager to use outside
+// of an HTTP request, such as in the sweeper.
+func (s *StreamableHTTPServer) backgroundSessionIdManager() SessionIdManager {
+	if s.sessionIdManager != nil {
+		return s.sessionIdManager
+	}
+	return s.sessionIdManagerResolver.ResolveSessionIdManager(nil)
+}
+
 // startSessionSweeper launches a background goroutine that periodically removes
 // transport state for sessions that have been idle longer than sessionIdleTTL.
 func (s *StreamableHTTPServer) startSessionSweeper(ctx context.Context) {
@@ -957,11 +955,7 @@ func (s *StreamableHTTPServer) sweepExpiredSessions() {
 		}
 
 		s.logger.Infof("Sweeping expired session: %s", sessionID)
-		mgr := s.sessionIdManager
-		if mgr == nil {
-			mgr = s.sessionIdManagerResolver.ResolveSessionIdManager(nil)
-		}
-		_, _ = mgr.Terminate(sessionID)
+		_, _ = s.backgroundSessionIdManager().Terminate(sessionID)
 		s.cleanupSessionState(context.Background(), sessionID)
 		return true
 	})
@@ -1100,33 +1094,6 @@ func (s *sessionToolsStore) delete(sessionID string) {
 	delete(s.tools, sessionID)
 }
 
-// Sampling support types for HTTP transport
-type samplingRequestItem struct {
-	requestID int64
-	request   mcp.CreateMessageRequest
-	response  chan samplingResponseItem
-}
-
-type samplingResponseItem struct {
-	requestID int64
-	result    json.RawMessage
-	err       error
-}
-
-// Elicitation support types for HTTP transport
-type elicitationRequestItem struct {
-	requestID int64
-	request   mcp.ElicitationRequest
-	response  chan samplingResponseItem
-}
-
-// Roots support types for HTTP transport
-type rootsRequestItem struct {
-	requestID int64
-	request   mcp.ListRootsRequest
-	response  chan samplingResponseItem
-}
-
 // streamableHttpSession is a session for streamable-http transport
 // When in POST handlers(request/notification), it's ephemeral, and only exists in the life of the request handler.
 // When in GET handlers(listening), it's a real session, and will be registered in the MCP server.
@@ -1138,30 +1105,31 @@ type streamableHttpSession struct {
 	resourceTemplates   *sessionResourceTemplatesStore
 	upgradeToSSE        atomic.Bool
 	logLevels           *sessionLogLevelsStore
-	clientInfo          atomic.Value // stores session-specific client info
-	clientCapabilities  atomic.Value // stores session-specific client capabilities
+	clientInfo          atomic.Value  // stores session-specific client info
+	clientCapabilities  atomic.Value  // stores session-specific client capabilities
+	protocolVersion     atomic.Value  // stores the negotiated protocol version
+	lastActive          atomic.Int64  // unix nanos of the last request from the client
+	done                chan struct{} // closed when the session is terminated
+	closeOnce           sync.Once
 
-	// Sampling support for bidirectional communication
-	samplingRequestChan    chan samplingRequestItem    // server -> client sampling requests
-	elicitationRequestChan chan elicitationRequestItem // server -> client elicitation requests
-	rootsRequestChan       chan rootsRequestItem       // server -> client list roots requests
-
-	samplingRequests sync.Map     // requestID -> pending sampling request context
-	requestIDCounter atomic.Int64 // for generating unique request IDs
+	// Server -> client requests, such as sampling, are sent on the listening stream
+	requestChan chan mcp.JSONRPCRequest
+	requests    clientRequests // requests sent on requestChan awaiting a response
+	listening   atomic.Int32   // number of open GET streams
 }
 
 func newStreamableHttpSession(sessionID string, toolStore *sessionToolsStore, resourcesStore *sessionResourcesStore, templatesStore *sessionResourceTemplatesStore, levels *sessionLogLevelsStore) *streamableHttpSession {
 	s := &streamableHttpSession{
-		sessionID:              sessionID,
-		notificationChannel:    make(chan mcp.JSONRPCNotification, 100),
-		tools:                  toolStore,
-		resources:              resourcesStore,
-		resourceTemplates:      templatesStore,
-		logLevels:              levels,
-		samplingRequestChan:    make(chan samplingRequestItem, 10),
-		elicitationRequestChan: make(chan elicitationRequestItem, 10),
-		rootsRequestChan:       make(chan rootsRequestItem, 10),
-	}
+		sessionID:           sessionID,
+		notificationChannel: make(chan mcp.JSONRPCNotification, 100),
+		tools:               toolStore,
+		resources:           resourcesStore,
+		resourceTemplates:   templatesStore,
+		logLevels:           levels,
+		requestChan:         make(chan mcp.JSONRPCRequest, 10),
+		done:                make(chan struct{}),
+	}
+	s.lastActive.Store(time.Now().UnixNano())
 	return s
 }
 
@@ -1191,6 +1159,29 @@ func (s *streamableHttpSession) GetLogLevel() mcp.LoggingLevel {
 	return s.logLevels.get(s.sessionID)
 }
 
+func (s *streamableHttpSession) GetProtocolVersion() string {
+	if value, ok := s.protocolVersion.Load().(string); ok {
+		return value
+	}
+	return ""
+}
+
+func (s *streamableHttpSession) SetProtocolVersion(version string) {
+	s.protocolVersion.Store(version)
+}
+
+func (s *streamableHttpSession) lastActivity() time.Time {
+	return time.Unix(0, s.lastActive.Load())
+}
+
+// close ends the session's listening GET stream, if any.
+func (s *streamableHttpSession) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.requests.close()
+	})
+}
+
 var _ ClientSession = (*streamableHttpSession)(nil)
 
 func (s *streamableHttpSession) GetSessionTools() map[string]ServerTool {
@@ -1249,6 +1240,7 @@ var (
 	_ SessionWithResourceTemplates = (*streamableHttpSession)(nil)
 	_ SessionWithLogging           = (*streamableHttpSession)(nil)
 	_ SessionWithClientInfo        = (*streamableHttpSession)(nil)
+	_ SessionWithProtocolVersion   = (*streamableHttpSession)(nil)
 )
 
 func (s *streamableHttpSession) UpgradeToSSEWhenReceiveNotification() {
@@ -1257,156 +1249,52 @@ func (s *streamableHttpSession) UpgradeToSSEWhenReceiveNotification() {
 
 var _ SessionWithStreamableHTTPConfig = (*streamableHttpSession)(nil)
 
-// RequestSampling implements SessionWithSampling interface for HTTP transport
-func (s *streamableHttpSession) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
-	// Generate unique request ID
-	requestID := s.requestIDCounter.Add(1)
-
-	// Create response channel for this specific request
-	responseChan := make(chan samplingResponseItem, 1)
-
-	// Create the sampling request item
-	samplingRequest := samplingRequestItem{
-		requestID: requestID,
-		request:   request,
-		response:  responseChan,
-	}
-
-	// Store the pending request
-	s.samplingRequests.Store(requestID, responseChan)
-	defer s.samplingRequests.Delete(requestID)
-
-	// Send the sampling request via the channel (non-blocking)
-	select {
-	case s.samplingRequestChan <- samplingRequest:
-		// Request queued successfully
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	default:
-		return nil, fmt.Errorf("sampling request queue is full - server overloaded")
-	}
-
-	// Wait for response or context cancellation
-	select {
-	case response := <-responseChan:
-		if response.err != nil {
-			return nil, response.err
-		}
-		var result mcp.CreateMessageResult
-		if err := json.Unmarshal(response.result, &result); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal sampling response: %v", err)
-		}
-
-		// Parse content from map[string]any to proper Content type (TextContent, ImageContent, AudioContent)
-		// HTTP transport unmarshals Content as map[string]any, we need to convert it to the proper type
-		if contentMap, ok := result.Content.(map[string]any); ok {
-			content, err := mcp.ParseContent(contentMap)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse sampling response content: %w", err)
-			}
-			result.Content = content
+// SendRequest implements SessionWithRequests for HTTP transport. The request
+// is sent on the listening GET stream, and the client posts the response.
+func (s *streamableHttpSession) SendRequest(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	return s.requests.send(ctx, s, method, params, func(ctx context.Context, request mcp.JSONRPCRequest) error {
+		select {
+		case s.requestChan <- request:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			return fmt.Errorf("%s request queue is full - server overloaded", request.Method)
 		}
+	})
+}
 
-		return &result, nil
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
+// RequestSampling implements SessionWithSampling interface for HTTP transport
+func (s *streamableHttpSession) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	return requestSampling(ctx, s, request)
 }
 
 // ListRoots implements SessionWithRoots interface for HTTP transport.
 // It sends a list roots request to the client via SSE and waits for the response.
 func (s *streamableHttpSession) ListRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
-	// Generate unique request ID
-	requestID := s.requestIDCounter.Add(1)
-
-	// Create response channel for this specific request
-	responseChan := make(chan samplingResponseItem, 1)
-
-	// Create the roots request item
-	rootsRequest := rootsRequestItem{
-		requestID: requestID,
-		request:   request,
-		response:  responseChan,
-	}
-
-	// Store the pending request
-	s.samplingRequests.Store(requestID, responseChan)
-	defer s.samplingRequests.Delete(requestID)
+	return sendClientRequest[mcp.ListRootsResult](ctx, s, mcp.MethodListRoots, nil)
+}
 
-	// Send the list roots request via the channel (non-blocking)
-	select {
-	case s.rootsRequestChan <- rootsRequest:
-		// Request queued successfully
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	default:
-		return nil, fmt.Errorf("list roots request queue is full - server overloaded")
-	}
-
-	// Wait for response or context cancellation
-	select {
-	case response := <-responseChan:
-		if response.err != nil {
-			return nil, response.err
-		}
-		var result mcp.ListRootsResult
-		if err := json.Unmarshal(response.result, &result); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal list roots response: %v", err)
-		}
-		return &result, nil
-	case <-ctx.Done():
-		return nil, ctx.Err()
+// Ping implements SessionWithPing for HTTP transport. The session must have
+// a listening GET stream open.
+func (s *streamableHttpSession) Ping(ctx context.Context) error {
+	if s.listening.Load() == 0 {
+		return fmt.Errorf("%w: no listening stream", ErrSessionDoesNotSupportPing)
 	}
+	_, err := s.SendRequest(ctx, string(mcp.MethodPing), nil)
+	return err
 }
 
 // RequestElicitation implements SessionWithElicitation interface for HTTP transport
 func (s *streamableHttpSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
-	// Generate unique request ID
-	requestID := s.requestIDCounter.Add(1)
-
-	// Create response channel for this specific request
-	responseChan := make(chan samplingResponseItem, 1)
-
-	// Create the sampling request item
-	elicitationRequest := elicitationRequestItem{
-		requestID: requestID,
-		request:   request,
-		response:  responseChan,
-	}
-
-	// Store the pending request
-	s.samplingRequests.Store(requestID, responseChan)
-	defer s.samplingRequests.Delete(requestID)
-
-	// Send the sampling request via the channel (non-blocking)
-	select {
-	case s.elicitationRequestChan <- elicitationRequest:
-		// Request queued successfully
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	default:
-		return nil, fmt.Errorf("elicitation request queue is full - server overloaded")
-	}
-
-	// Wait for response or context cancellation
-	select {
-	case response := <-responseChan:
-		if response.err != nil {
-			return nil, response.err
-		}
-		var result mcp.ElicitationResult
-		if err := json.Unmarshal(response.result, &result); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal elicitation response: %v", err)
-		}
-		return &result, nil
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
+	return sendClientRequest[mcp.ElicitationResult](ctx, s, mcp.MethodElicitationCreate, request.Params)
 }
 
 var _ SessionWithSampling = (*streamableHttpSession)(nil)
 var _ SessionWithElicitation = (*streamableHttpSession)(nil)
 var _ SessionWithRoots = (*streamableHttpSession)(nil)
+var _ SessionWithPing = (*streamableHttpSession)(nil)
+var _ SessionWithRequests = (*streamableHttpSession)(nil)
 
 // --- session id manager ---
 
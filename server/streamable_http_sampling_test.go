@@ -34,9 +34,9 @@ func TestStreamableHTTPServer_SamplingBasic(t *testing.T) {
 		t.Error("streamableHttpSession should implement SessionWithSampling")
 	}
 
-	// Test that sampling request channels are initialized
-	if session.samplingRequestChan == nil {
-		t.Error("samplingRequestChan should be initialized")
+	// Test that the request channel is initialized
+	if session.requestChan == nil {
+		t.Error("requestChan should be initialized")
 	}
 }
 
@@ -180,13 +180,9 @@ func TestStreamableHTTPServer_SamplingQueueFull(t *testing.T) {
 	sessionID := "test-session"
 	session := newStreamableHttpSession(sessionID, nil, nil, nil, nil)
 
-	// Fill the sampling request queue
-	for i := 0; i < cap(session.samplingRequestChan); i++ {
-		session.samplingRequestChan <- samplingRequestItem{
-			requestID: int64(i),
-			request:   mcp.CreateMessageRequest{},
-			response:  make(chan samplingResponseItem, 1),
-		}
+	// Fill the request queue
+	for i := 0; i < cap(session.requestChan); i++ {
+		session.requestChan <- mcp.JSONRPCRequest{ID: mcp.NewRequestId(int64(i))}
 	}
 
 	// Try to add another request (should fail)
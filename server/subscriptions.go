@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// NotifyResourceUpdated sends notifications/resources/updated for the
+// resource with the given URI to the sessions that subscribed to it with
+// resources/subscribe.
+func (s *MCPServer) NotifyResourceUpdated(uri string) {
+	for _, sessionID := range s.subscriptions.subscribers(uri) {
+		_ = s.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{
+			"uri": uri,
+		})
+	}
+}
+
+func (s *MCPServer) handleSubscribe(
+	ctx context.Context,
+	id any,
+	request mcp.SubscribeRequest,
+) (*mcp.EmptyResult, *requestError) {
+	sessionID, err := s.subscriptionSession(ctx, id, request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	s.subscriptions.add(sessionID, request.Params.URI)
+	return &mcp.EmptyResult{}, nil
+}
+
+func (s *MCPServer) handleUnsubscribe(
+	ctx context.Context,
+	id any,
+	request mcp.UnsubscribeRequest,
+) (*mcp.EmptyResult, *requestError) {
+	sessionID, err := s.subscriptionSession(ctx, id, request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	s.subscriptions.remove(sessionID, request.Params.URI)
+	return &mcp.EmptyResult{}, nil
+}
+
+// subscriptionSession checks a subscribe or unsubscribe request and returns
+// the ID of the session it belongs to.
+func (s *MCPServer) subscriptionSession(ctx context.Context, id any, uri string) (string, *requestError) {
+	if !s.capabilities.resources.subscribe {
+		return "", &requestError{
+			id:   id,
+			code: mcp.METHOD_NOT_FOUND,
+			err:  fmt.Errorf("resource subscriptions %w", ErrUnsupported),
+		}
+	}
+	if uri == "" {
+		return "", &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  errors.New("missing resource URI"),
+		}
+	}
+	session := ClientSessionFromContext(ctx)
+	if session == nil {
+		return "", &requestError{
+			id:   id,
+			code: mcp.INVALID_REQUEST,
+			err:  ErrNoActiveSession,
+		}
+	}
+	return session.SessionID(), nil
+}
+
+// resourceSubscriptions tracks the resources each session subscribed to.
+type resourceSubscriptions struct {
+	mu        sync.RWMutex
+	bySession map[string]map[string]struct{}
+}
+
+func (r *resourceSubscriptions) add(sessionID, uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.bySession == nil {
+		r.bySession = make(map[string]map[string]struct{})
+	}
+	uris, ok := r.bySession[sessionID]
+	if !ok {
+		uris = make(map[string]struct{})
+		r.bySession[sessionID] = uris
+	}
+	uris[uri] = struct{}{}
+}
+
+func (r *resourceSubscriptions) remove(sessionID, uri string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	uris := r.bySession[sessionID]
+	delete(uris, uri)
+	if len(uris) == 0 {
+		delete(r.bySession, sessionID)
+	}
+}
+
+// removeSession drops the subscriptions of the session with the given ID.
+func (r *resourceSubscriptions) removeSession(sessionID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.bySession, sessionID)
+}
+
+// subscribers returns the IDs of the sessions subscribed to uri.
+func (r *resourceSubscriptions) subscribers(uri string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var sessionIDs []string
+	for sessionID, uris := range r.bySession {
+		if _, ok := uris[uri]; ok {
+			sessionIDs = append(sessionIDs, sessionID)
+		}
+	}
+	return sessionIDs
+}
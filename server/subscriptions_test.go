@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMCPServer_ResourceSubscriptions(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", WithResourceCapabilities(true, false))
+	session := fakeSession{
+		sessionID:           "subscriber",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		initialized:         true,
+	}
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+	ctx := server.WithContext(context.Background(), session)
+
+	send := func(method, uri string) mcp.JSONRPCMessage {
+		message, err := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      1,
+			"method":  method,
+			"params":  map[string]any{"uri": uri},
+		})
+		require.NoError(t, err)
+		return server.HandleMessage(ctx, message)
+	}
+
+	require.IsType(t, mcp.JSONRPCResponse{}, send("resources/subscribe", "test://a"))
+	server.NotifyResourceUpdated("test://b")
+	server.NotifyResourceUpdated("test://a")
+	select {
+	case notification := <-session.notificationChannel:
+		assert.Equal(t, mcp.MethodNotificationResourceUpdated, notification.Method)
+		assert.Equal(t, "test://a", notification.Params.AdditionalFields["uri"])
+	default:
+		t.Fatal("no resources/updated notification sent")
+	}
+	assert.Empty(t, session.notificationChannel)
+
+	require.IsType(t, mcp.JSONRPCResponse{}, send("resources/unsubscribe", "test://a"))
+	server.NotifyResourceUpdated("test://a")
+	assert.Empty(t, session.notificationChannel)
+
+	// A missing URI is invalid.
+	response, ok := send("resources/subscribe", "").(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Equal(t, mcp.INVALID_PARAMS, response.Error.Code)
+
+	// Subscriptions are dropped with the session.
+	send("resources/subscribe", "test://a")
+	server.UnregisterSession(context.Background(), session.SessionID())
+	assert.Empty(t, server.subscriptions.subscribers("test://a"))
+}
+
+func TestMCPServer_ResourceSubscriptions_Unsupported(t *testing.T) {
+	server := NewMCPServer("test", "1.0.0", WithResourceCapabilities(false, true))
+	response, ok := server.HandleMessage(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"test://a"}}`)).(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Equal(t, mcp.METHOD_NOT_FOUND, response.Error.Code)
+}
@@ -57,7 +57,7 @@ func TestMCPServer_TaskCapabilities(t *testing.T) {
 				"id": 1,
 				"method": "initialize",
 				"params": {
-					"protocolVersion": "2025-06-18",
+					"protocolVersion": "2025-11-25",
 					"capabilities": {},
 					"clientInfo": {
 						"name": "test-client",
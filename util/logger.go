@@ -19,6 +19,13 @@ func DefaultLogger() Logger {
 	}
 }
 
+// NewStdLogger returns a Logger that writes to the given standard library logger.
+func NewStdLogger(logger *log.Logger) Logger {
+	return &stdLogger{
+		logger: logger,
+	}
+}
+
 // stdLogger wraps the standard library's log.Logger.
 type stdLogger struct {
 	logger *log.Logger
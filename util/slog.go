@@ -0,0 +1,42 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// NewSlogLogger returns a Logger that writes to the given structured logger.
+// Infof records are logged at slog.LevelInfo and Errorf records at
+// slog.LevelError. If logger is nil, slog.Default() is used.
+//
+// Use it wherever a Logger is accepted, for example:
+//
+//	server.NewStreamableHTTPServer(mcpServer, server.WithLogger(util.NewSlogLogger(logger)))
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &slogLogger{logger: logger}
+}
+
+// slogLogger adapts a *slog.Logger to the Logger interface.
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l *slogLogger) Infof(format string, v ...any) {
+	l.log(slog.LevelInfo, format, v...)
+}
+
+func (l *slogLogger) Errorf(format string, v ...any) {
+	l.log(slog.LevelError, format, v...)
+}
+
+func (l *slogLogger) log(level slog.Level, format string, v ...any) {
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	l.logger.Log(ctx, level, fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,129 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordHandler is a slog.Handler that keeps the records it handles.
+type recordHandler struct {
+	level   slog.Level
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *recordHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.records = append(h.records, record)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs(attrs []slog.Attr) slog.Handler { return h }
+func (h *recordHandler) WithGroup(name string) slog.Handler       { return h }
+
+// countingStringer counts how often it is formatted.
+type countingStringer struct {
+	calls int
+}
+
+func (s *countingStringer) String() string {
+	s.calls++
+	return "value"
+}
+
+func TestNewSlogLogger(t *testing.T) {
+	tests := []struct {
+		name          string
+		handlerLevel  slog.Level
+		log           func(Logger)
+		expectedLevel slog.Level
+		expectedMsg   string
+		expectLogged  bool
+	}{
+		{
+			name:          "Infof logs at info level",
+			handlerLevel:  slog.LevelInfo,
+			log:           func(l Logger) { l.Infof("started %s on port %d", "server", 8080) },
+			expectedLevel: slog.LevelInfo,
+			expectedMsg:   "started server on port 8080",
+			expectLogged:  true,
+		},
+		{
+			name:          "Errorf logs at error level",
+			handlerLevel:  slog.LevelInfo,
+			log:           func(l Logger) { l.Errorf("failed: %v", assert.AnError) },
+			expectedLevel: slog.LevelError,
+			expectedMsg:   "failed: " + assert.AnError.Error(),
+			expectLogged:  true,
+		},
+		{
+			name:          "format is applied like fmt.Sprintf",
+			handlerLevel:  slog.LevelInfo,
+			log:           func(l Logger) { l.Infof("100%% done") },
+			expectedLevel: slog.LevelInfo,
+			expectedMsg:   "100% done",
+			expectLogged:  true,
+		},
+		{
+			name:         "Infof is dropped below the handler level",
+			handlerLevel: slog.LevelWarn,
+			log:          func(l Logger) { l.Infof("ignored") },
+			expectLogged: false,
+		},
+		{
+			name:          "Errorf passes the handler level",
+			handlerLevel:  slog.LevelWarn,
+			log:           func(l Logger) { l.Errorf("kept") },
+			expectedLevel: slog.LevelError,
+			expectedMsg:   "kept",
+			expectLogged:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &recordHandler{level: tt.handlerLevel}
+			tt.log(NewSlogLogger(slog.New(handler)))
+
+			if !tt.expectLogged {
+				assert.Empty(t, handler.records)
+				return
+			}
+			require.Len(t, handler.records, 1)
+			assert.Equal(t, tt.expectedLevel, handler.records[0].Level)
+			assert.Equal(t, tt.expectedMsg, handler.records[0].Message)
+		})
+	}
+}
+
+func TestNewSlogLogger_DisabledLevelIsNotFormatted(t *testing.T) {
+	handler := &recordHandler{level: slog.LevelError}
+	logger := NewSlogLogger(slog.New(handler))
+
+	value := &countingStringer{}
+	logger.Infof("value: %s", value)
+	assert.Equal(t, 0, value.calls)
+
+	logger.Errorf("value: %s", value)
+	assert.Equal(t, 1, value.calls)
+	require.Len(t, handler.records, 1)
+	assert.Equal(t, "value: value", handler.records[0].Message)
+}
+
+func TestNewSlogLogger_NilUsesDefault(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	NewSlogLogger(nil).Infof("hello %s", "world")
+	assert.Contains(t, buf.String(), `level=INFO msg="hello world"`)
+}